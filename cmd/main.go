@@ -2,134 +2,346 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
-	"os/signal"
-	"sync"
-	"syscall"
 	"time"
 
 	objectProto "github.com/isd-sgcu/rpkm67-go-proto/rpkm67/store/object/v1"
 	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/isd-sgcu/rpkm67-store/database"
+	"github.com/isd-sgcu/rpkm67-store/internal/auth"
+	"github.com/isd-sgcu/rpkm67-store/internal/bootstrap"
+	"github.com/isd-sgcu/rpkm67-store/internal/cache"
+	"github.com/isd-sgcu/rpkm67-store/internal/catalog"
+	"github.com/isd-sgcu/rpkm67-store/internal/client/bucket"
+	httpclient "github.com/isd-sgcu/rpkm67-store/internal/client/http"
 	"github.com/isd-sgcu/rpkm67-store/internal/client/store"
+	"github.com/isd-sgcu/rpkm67-store/internal/healthcheck"
+	"github.com/isd-sgcu/rpkm67-store/internal/lifecycle"
+	"github.com/isd-sgcu/rpkm67-store/internal/limiter"
+	"github.com/isd-sgcu/rpkm67-store/internal/metrics"
 	"github.com/isd-sgcu/rpkm67-store/internal/object"
+	"github.com/isd-sgcu/rpkm67-store/internal/recovery"
+	"github.com/isd-sgcu/rpkm67-store/internal/reload"
+	"github.com/isd-sgcu/rpkm67-store/internal/resilience"
+	"github.com/isd-sgcu/rpkm67-store/internal/tracing"
+	"github.com/isd-sgcu/rpkm67-store/internal/transport"
 	"github.com/isd-sgcu/rpkm67-store/internal/utils"
-	"github.com/isd-sgcu/rpkm67-store/logger"
+	appLogger "github.com/isd-sgcu/rpkm67-store/logger"
+	adminProto "github.com/isd-sgcu/rpkm67-store/proto/rpkm67/store/admin/v1"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	grpcCredentials "google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
 func main() {
-	conf, err := config.LoadConfig()
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	conf, err := config.LoadConfig(configFlags)
 	if err != nil {
 		panic(fmt.Sprintf("Failed to load config: %v", err))
 	}
 
-	logger := logger.New(conf)
+	logger := appLogger.New(conf)
+	logger.Info("Loaded configuration", zap.Any("config", conf.Redacted()))
+
+	db, err := database.InitPostgresDatabase(&conf.DB, conf.App.IsDevelopment())
+	if err != nil {
+		panic(fmt.Sprintf("Failed to connect to database: %v", err))
+	}
+
+	var cacheRepo cache.Repository
+	var redisClient *redis.Client
+	if conf.Redis.Host != "" {
+		redisClient, err = database.InitRedis(&conf.Redis)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to connect to redis: %v", err))
+		}
+		cacheRepo = cache.NewRedisRepository(redisClient)
+	} else {
+		logger.Warn("REDIS_HOST is not set, falling back to in-process lookup cache")
+		cacheRepo = cache.NewLRURepository(conf.Cache.LRUSize)
+	}
 
+	storeCredentials := store.NewCredentials(conf.Store.AccessKey, conf.Store.SecretKey)
 	minioClient, err := minio.New(conf.Store.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(conf.Store.AccessKey, conf.Store.SecretKey, ""),
+		Creds:  credentials.New(storeCredentials),
 		Secure: conf.Store.UseSSL,
 	})
 	if err != nil {
 		panic(fmt.Sprintf("Failed to connect to Minio: %v", err))
 	}
 
-	storeClient := store.NewClient(minioClient)
+	bootstrapCtx, cancelBootstrap := context.WithTimeout(context.Background(), 30*time.Second)
+	err = bootstrap.NewBootstrapper(bucket.NewClient(minioClient), &conf.Store, logger.Named("bootstrap")).Run(bootstrapCtx)
+	cancelBootstrap()
+	if err != nil {
+		panic(fmt.Sprintf("Storage bucket is not usable: %v", err))
+	}
+
+	storeClient := tracing.NewStoreClient(resilience.NewStoreClient(metrics.NewStoreClient(store.NewClient(minioClient)), &conf.Store, logger.Named("storeClient")))
 	httpClient := &http.Client{}
 
 	randomUtils := utils.NewRandomUtils()
 
-	objectRepo := object.NewRepository(&conf.Store, storeClient, httpClient)
-	objectSvc := object.NewService(objectRepo, &conf.Store, logger.Named("objectSvc"), randomUtils)
+	catalogRepo := catalog.NewRepository(db)
+	objectRepo := tracing.NewObjectRepository(object.NewRepository(&conf.Store, storeClient, httpclient.NewClient(httpClient)))
+	objectSvc := object.NewService(objectRepo, catalogRepo, cacheRepo, &conf.Store, &conf.Cache, logger.Named("objectSvc"), randomUtils)
 
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%v", conf.App.Port))
 	if err != nil {
 		panic(fmt.Sprintf("Failed to listen: %v", err))
 	}
 
-	grpcServer := grpc.NewServer()
-	grpc_health_v1.RegisterHealthServer(grpcServer, health.NewServer())
+	serverOpts := transport.NewServerOptions(&conf.GRPC, conf.Store.MaxFileSize)
+	var tracerProvider *sdktrace.TracerProvider
+	if conf.Tracing.Exporter != "" {
+		tracerProvider, err = tracing.NewTracerProvider(context.Background(), &conf.Tracing)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to set up tracing: %v", err))
+		}
+		// extracts the trace context propagated in the incoming metadata
+		serverOpts = append(serverOpts, grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	}
+	if conf.TLS.Enabled() {
+		tlsConfig, err := transport.NewTLSConfig(&conf.TLS, logger.Named("tls"))
+		if err != nil {
+			panic(fmt.Sprintf("Failed to load TLS config: %v", err))
+		}
+		serverOpts = append(serverOpts, grpc.Creds(grpcCredentials.NewTLS(tlsConfig)))
+	}
+
+	// everything below reloads in place, the rest of the configuration needs a restart
+	reloadTargets := []reload.Target{
+		func(c *config.Config) error {
+			return appLogger.SetLevel(&c.App)
+		},
+		func(c *config.Config) error {
+			storeCredentials.Set(c.Store.AccessKey, c.Store.SecretKey)
+			return nil
+		},
+		func(c *config.Config) error {
+			// the gRPC message size limit is fixed when the server starts
+			if startup := conf.Store.MaxFileSize; startup > 0 && (c.Store.MaxFileSize == 0 || c.Store.MaxFileSize > startup) {
+				logger.Warn("APP_MAX_FILE_SIZE_MB was raised past its value at startup, larger uploads are rejected until a restart")
+			}
+			objectSvc.Reload(&c.Store)
+			return nil
+		},
+	}
+
+	unaryInterceptors := []grpc.UnaryServerInterceptor{
+		appLogger.UnaryServerInterceptor(logger.Named("access")),
+		metrics.UnaryServerInterceptor(),
+		recovery.UnaryServerInterceptor(logger.Named("recovery")),
+	}
+	streamInterceptors := []grpc.StreamServerInterceptor{
+		appLogger.StreamServerInterceptor(logger.Named("access")),
+		metrics.StreamServerInterceptor(),
+		recovery.StreamServerInterceptor(logger.Named("recovery")),
+	}
+	// with mTLS, callers without a token are identified by their client certificate
+	if conf.Auth.Enabled() || conf.TLS.ClientCAFile != "" {
+		authenticator := auth.NewAuthenticator(&conf.Auth, logger.Named("auth"))
+		unaryInterceptors = append(unaryInterceptors, authenticator.UnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, authenticator.StreamServerInterceptor())
+		reloadTargets = append(reloadTargets, func(c *config.Config) error {
+			authenticator.Reload(&c.Auth)
+			return nil
+		})
+	} else if conf.Auth.Disabled {
+		logger.Warn("AUTH_DISABLED is set, gRPC calls are not authenticated")
+	} else {
+		panic("Neither AUTH_SHARED_SECRETS, JWT_SECRET nor TLS_CLIENT_CA_FILE is set; set AUTH_DISABLED=true to serve unauthenticated calls")
+	}
+	policy, err := loadPolicy(&conf.Auth)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to load authorization policy: %v", err))
+	}
+	authorizer := auth.NewAuthorizer(policy, catalogRepo, &conf.Store, logger.Named("authz"))
+	unaryInterceptors = append(unaryInterceptors, authorizer.UnaryServerInterceptor())
+	reloadTargets = append(reloadTargets, func(c *config.Config) error {
+		policy, err := loadPolicy(&c.Auth)
+		if err != nil {
+			return fmt.Errorf("authorization policy: %w", err)
+		}
+		authorizer.Reload(policy)
+		return nil
+	})
+
+	// last, so that calls rejected by auth never take up capacity; only the byte budget of uploads
+	// is taken earlier, before their request is read
+	callLimiter := limiter.NewLimiter(&conf.Limits, transport.MaxMessageSize(conf.Store.MaxFileSize), logger.Named("limiter"))
+	unaryInterceptors = append(unaryInterceptors, callLimiter.UnaryServerInterceptor())
+	streamInterceptors = append(streamInterceptors, callLimiter.StreamServerInterceptor())
+
+	serverOpts = append(serverOpts,
+		grpc.InTapHandle(callLimiter.TapHandle),
+		grpc.StatsHandler(callLimiter.StatsHandler()),
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	)
+	grpcServer := grpc.NewServer(serverOpts...)
+	healthServer := health.NewServer()
+	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
 	objectProto.RegisterObjectServiceServer(grpcServer, objectSvc)
+	adminProto.RegisterAdminServiceServer(grpcServer, objectSvc)
 
 	reflection.Register(grpcServer)
 
+	metrics.Registry.MustRegister(metrics.NewCatalogCollector(catalogRepo, conf.Store.BucketName, logger.Named("metrics")))
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", metrics.Handler())
+	metricsServer := &http.Server{
+		Addr:              fmt.Sprintf(":%v", conf.Metrics.Port),
+		Handler:           metricsMux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	if conf.Metrics.Port != "" {
+		go func() {
+			logger.Sugar().Infof("Metrics listening at port %v", conf.Metrics.Port)
+
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("Failed to start metrics server", zap.Error(err))
+			}
+		}()
+	}
+
+	// probes go straight to the backend so they neither show up in traces nor wait on anything else
+	healthChecker := healthcheck.NewChecker(healthServer, store.NewClient(minioClient), &conf.Health, &conf.Store, logger.Named("health"),
+		objectProto.ObjectService_ServiceDesc.ServiceName, adminProto.AdminService_ServiceDesc.ServiceName)
+	healthCtx, stopHealth := context.WithCancel(context.Background())
+	go healthChecker.Run(healthCtx)
+
+	reloadWatcher := reload.NewWatcher(func() (*config.Config, error) {
+		return config.LoadConfig(configFlags)
+	}, conf, logger.Named("reload"), reloadTargets...)
+	reloadCtx, stopReload := context.WithCancel(context.Background())
+	go reloadWatcher.Run(reloadCtx)
+
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	if !conf.Store.HardDelete {
+		go runTrashPurge(purgeCtx, objectSvc, conf.Store.TrashPurgeInterval, logger.Named("trashPurge"))
+	}
+
 	go func() {
 		logger.Sugar().Infof("RPKM67 Store starting at port %v", conf.App.Port)
 
+		// Serve closes the listener once the server stops
 		if err := grpcServer.Serve(listener); err != nil {
 			logger.Fatal("Failed to start RPKM67 Store service", zap.Error(err))
 		}
 	}()
 
-	wait := gracefulShutdown(context.Background(), 2*time.Second, logger, map[string]operation{
-		"server": func(ctx context.Context) error {
-			grpcServer.GracefulStop()
+	// hooks run in order: stop taking work, drain calls in flight, flush, then close clients
+	lifecycleManager := lifecycle.NewManager(conf.App.ShutdownTimeout, logger.Named("lifecycle"))
+	lifecycleManager.OnShutdown("health", func(ctx context.Context) error {
+		stopHealth()
+		healthServer.Shutdown()
+		return nil
+	})
+	lifecycleManager.OnShutdown("background jobs", func(ctx context.Context) error {
+		stopReload()
+		stopPurge()
+		return nil
+	})
+	lifecycleManager.OnShutdown("shutdown delay", func(ctx context.Context) error {
+		// load balancers keep routing calls here until their next health check sees NOT_SERVING
+		select {
+		case <-time.After(conf.App.ShutdownDelay):
 			return nil
-		},
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	})
+	lifecycleManager.OnShutdown("server", func(ctx context.Context) error {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
 
-	<-wait
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			grpcServer.Stop()
+			return ctx.Err()
+		}
+	})
+	lifecycleManager.OnShutdown("metrics server", func(ctx context.Context) error {
+		return metricsServer.Shutdown(ctx)
+	})
+	lifecycleManager.OnShutdown("tracer", func(ctx context.Context) error {
+		if tracerProvider == nil {
+			return nil
+		}
+		return tracerProvider.Shutdown(ctx)
+	})
+	lifecycleManager.OnShutdown("redis", func(ctx context.Context) error {
+		if redisClient == nil {
+			return nil
+		}
+		return redisClient.Close()
+	})
+	lifecycleManager.OnShutdown("storage client", func(ctx context.Context) error {
+		httpClient.CloseIdleConnections()
+		return nil
+	})
+	lifecycleManager.OnShutdown("database", func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	})
 
-	grpcServer.GracefulStop()
-	logger.Info("Closing the listener")
-	listener.Close()
+	if err := lifecycleManager.Wait(); err != nil {
+		logger.Error("RPKM67 Store service did not shut down cleanly", zap.Error(err))
+		_ = logger.Sync()
+		os.Exit(1)
+	}
 	logger.Info("RPKM67 Store service has been shutdown gracefully")
 }
 
-type operation func(ctx context.Context) error
-
-func gracefulShutdown(ctx context.Context, timeout time.Duration, log *zap.Logger, ops map[string]operation) <-chan struct{} {
-	wait := make(chan struct{})
-	go func() {
-		s := make(chan os.Signal, 1)
-
-		signal.Notify(s, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
-		sig := <-s
-
-		log.Named("graceful shutdown").Sugar().
-			Infof("got signal \"%v\" shutting down service", sig)
-
-		timeoutFunc := time.AfterFunc(timeout, func() {
-			log.Named("graceful shutdown").Sugar().
-				Errorf("timeout %v ms has been elapsed, force exit", timeout.Milliseconds())
-			os.Exit(0)
-		})
-
-		defer timeoutFunc.Stop()
-
-		var wg sync.WaitGroup
-
-		for key, op := range ops {
-			wg.Add(1)
-			innerOp := op
-			innerKey := key
-			go func() {
-				defer wg.Done()
-
-				log.Named("graceful shutdown").Sugar().
-					Infof("cleaning up: %v", innerKey)
-				if err := innerOp(ctx); err != nil {
-					log.Named("graceful shutdown").Sugar().
-						Errorf("%v: clean up failed: %v", innerKey, err.Error())
-					return
-				}
+func runTrashPurge(ctx context.Context, objectSvc object.Service, interval time.Duration, log *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-				log.Named("graceful shutdown").Sugar().
-					Infof("%v was shutdown gracefully", innerKey)
-			}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := objectSvc.PurgeTrash(ctx)
+			if err != nil {
+				log.Error("Failed to purge trash", zap.Error(err))
+				continue
+			}
+			if purged > 0 {
+				log.Info("Purged expired trash", zap.Int("purged", purged))
+			}
 		}
+	}
+}
 
-		wg.Wait()
-		close(wait)
-	}()
+// loadPolicy loads the policy file, or the default policy when none is set.
+func loadPolicy(conf *config.Auth) (*auth.Policy, error) {
+	if conf.PolicyFile == "" {
+		return auth.DefaultPolicy(), nil
+	}
 
-	return wait
+	return auth.LoadPolicy(conf.PolicyFile)
 }
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/isd-sgcu/rpkm67-store/database"
+	"github.com/isd-sgcu/rpkm67-store/internal/catalog"
+	httpclient "github.com/isd-sgcu/rpkm67-store/internal/client/http"
+	"github.com/isd-sgcu/rpkm67-store/internal/client/store"
+	"github.com/isd-sgcu/rpkm67-store/internal/object"
+	"github.com/isd-sgcu/rpkm67-store/internal/reconcile"
+	"github.com/isd-sgcu/rpkm67-store/logger"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"go.uber.org/zap"
+)
+
+// reconcile walks the bucket and compares it with the referenced keys, printing a JSON report
+// of orphans and missing objects. It only moves orphans to the trash bucket when -delete is given,
+// and only once -backfill has added the objects stored before the catalog existed to it.
+func main() {
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	keysFile := flag.String("keys-file", "", "file with one referenced key per line; the metadata catalog is used when empty")
+	prefix := flag.String("prefix", "", "only reconcile keys under this prefix")
+	backfill := flag.Bool("backfill", false, "add catalog entries for objects that have none before reconciling")
+	deleteOrphans := flag.Bool("delete", false, "move orphans older than -min-age to the trash bucket (dry run when false)")
+	minAge := flag.Duration("min-age", 0, "minimum age of an orphan before it may be deleted (default RECONCILE_ORPHAN_MIN_AGE_HOURS)")
+	flag.Parse()
+
+	conf, err := config.LoadConfig(configFlags)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to load config: %v", err))
+	}
+
+	minAgeSet := false
+	flag.Visit(func(f *flag.Flag) { minAgeSet = minAgeSet || f.Name == "min-age" })
+	if !minAgeSet {
+		*minAge = conf.Reconcile.OrphanMinAge
+	}
+
+	logger := logger.New(conf)
+
+	db, err := database.InitPostgresDatabase(&conf.DB, conf.App.IsDevelopment())
+	if err != nil {
+		logger.Fatal("Failed to connect to database", zap.Error(err))
+	}
+
+	minioClient, err := minio.New(conf.Store.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(conf.Store.AccessKey, conf.Store.SecretKey, ""),
+		Secure: conf.Store.UseSSL,
+	})
+	if err != nil {
+		logger.Fatal("Failed to connect to Minio", zap.Error(err))
+	}
+
+	catalogRepo := catalog.NewRepository(db)
+	objectRepo := object.NewRepository(&conf.Store, store.NewClient(minioClient), httpclient.NewClient(&http.Client{}))
+	reconcileSvc := reconcile.NewService(objectRepo, catalogRepo, &conf.Store, logger.Named("reconcileSvc"))
+
+	if *backfill {
+		added, err := reconcileSvc.Backfill(context.Background(), *prefix)
+		if err != nil {
+			logger.Fatal("Failed to backfill catalog", zap.Error(err))
+		}
+		logger.Info("Backfilled catalog", zap.Int("added", added))
+	}
+
+	var referenced map[string]struct{}
+	if *keysFile != "" {
+		file, err := os.Open(*keysFile)
+		if err != nil {
+			logger.Fatal("Failed to open keys file", zap.Error(err))
+		}
+		referenced, err = reconcile.ReadKeys(file)
+		file.Close()
+		if err != nil {
+			logger.Fatal("Failed to read keys file", zap.Error(err))
+		}
+	} else {
+		referenced, err = reconcileSvc.CatalogKeys(*prefix)
+		if err != nil {
+			logger.Fatal("Failed to load catalog keys", zap.Error(err))
+		}
+	}
+
+	report, err := reconcileSvc.Reconcile(context.Background(), referenced, reconcile.Options{
+		Prefix:        *prefix,
+		DeleteOrphans: *deleteOrphans,
+		MinAge:        *minAge,
+		FromCatalog:   *keysFile == "",
+	})
+	if errors.Is(err, reconcile.ErrCatalogNotBackfilled) {
+		logger.Fatal("Refusing to delete orphans before the catalog is backfilled; run once with -backfill and no -prefix")
+	}
+	if err != nil {
+		logger.Fatal("Failed to reconcile", zap.Error(err))
+	}
+
+	logger.Info("Reconciliation finished",
+		zap.Bool("dry_run", report.DryRun),
+		zap.Int("orphans", len(report.Orphans)),
+		zap.Int("missing", len(report.Missing)))
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		logger.Fatal("Failed to write report", zap.Error(err))
+	}
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// readFile reads a YAML or TOML config file into settings. Nested keys are joined with
+// underscores, so store: {bucket_name: x} and STORE_BUCKET_NAME: x both set STORE_BUCKET_NAME.
+func readFile(filename string) (map[string]string, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &tree)
+	case ".toml":
+		tree, err = parseTOML(string(content))
+	default:
+		return nil, fmt.Errorf("config file %v: expected a .yaml, .yml or .toml file", filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %v: %w", filename, err)
+	}
+
+	values := make(map[string]string)
+	var problems []string
+	flatten("", tree, values, &problems)
+	if len(problems) > 0 {
+		for i, problem := range problems {
+			problems[i] = fmt.Sprintf("config file %v: %v", filename, problem)
+		}
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	return values, nil
+}
+
+func flatten(prefix string, tree map[string]interface{}, values map[string]string, problems *[]string) {
+	for name, node := range tree {
+		key := strings.ToUpper(name)
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+
+		if isSetting(key) {
+			values[key] = formatValue(node)
+			continue
+		}
+		if subtree, ok := node.(map[string]interface{}); ok {
+			flatten(key, subtree, values, problems)
+			continue
+		}
+		*problems = append(*problems, fmt.Sprintf("unknown setting %v", key))
+	}
+}
+
+// formatValue turns a file value into its environment form: lists are comma separated and
+// maps become comma separated key=value pairs, e.g. for AUTH_SHARED_SECRETS or STORE_QUOTAS.
+func formatValue(node interface{}) string {
+	switch node := node.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		items := make([]string, 0, len(node))
+		for _, item := range node {
+			items = append(items, formatValue(item))
+		}
+		return strings.Join(items, ",")
+	case map[string]interface{}:
+		items := make([]string, 0, len(node))
+		for name, item := range node {
+			items = append(items, name+"="+formatValue(item))
+		}
+		sort.Strings(items)
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(node)
+	}
+}
+
+// parseTOML reads the subset of TOML settings need: [table] headers, dotted keys, and
+// key = value lines holding strings, numbers, booleans or arrays of those.
+func parseTOML(content string) (map[string]interface{}, error) {
+	tree := make(map[string]interface{})
+	table := tree
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(stripComment(line))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: invalid table header %q", i+1, line)
+			}
+			var err error
+			table, err = subtable(tree, strings.Trim(line, "[]"))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			continue
+		}
+
+		name, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", i+1)
+		}
+		value, err := parseTOMLValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		path := strings.Split(strings.TrimSpace(name), ".")
+		parent, err := subtable(table, strings.Join(path[:len(path)-1], "."))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		parent[strings.TrimSpace(path[len(path)-1])] = value
+	}
+
+	return tree, nil
+}
+
+func subtable(tree map[string]interface{}, path string) (map[string]interface{}, error) {
+	if path == "" {
+		return tree, nil
+	}
+
+	for _, name := range strings.Split(path, ".") {
+		name = strings.TrimSpace(name)
+		next, ok := tree[name]
+		if !ok {
+			next = make(map[string]interface{})
+			tree[name] = next
+		}
+		table, ok := next.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%v is not a table", name)
+		}
+		tree = table
+	}
+
+	return tree, nil
+}
+
+func parseTOMLValue(raw string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		return strconv.Unquote(raw)
+	case strings.HasPrefix(raw, "'") && strings.HasSuffix(raw, "'") && len(raw) >= 2:
+		return raw[1 : len(raw)-1], nil
+	case strings.HasPrefix(raw, "[") && strings.HasSuffix(raw, "]"):
+		var items []interface{}
+		for _, item := range splitArray(raw[1 : len(raw)-1]) {
+			value, err := parseTOMLValue(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return items, nil
+	case raw == "true" || raw == "false":
+		return raw == "true", nil
+	}
+
+	if _, err := strconv.ParseFloat(strings.ReplaceAll(raw, "_", ""), 64); err != nil {
+		return nil, fmt.Errorf("unsupported value %q", raw)
+	}
+
+	return strings.ReplaceAll(raw, "_", ""), nil
+}
+
+// splitArray splits the inside of an array at commas outside of quotes.
+func splitArray(raw string) []string {
+	var items []string
+	var quote rune
+	start := 0
+	for i, r := range raw {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ',':
+			items = append(items, raw[start:i])
+			start = i + 1
+		}
+	}
+	items = append(items, raw[start:])
+
+	trimmed := items[:0]
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			trimmed = append(trimmed, item)
+		}
+	}
+
+	return trimmed
+}
+
+// stripComment drops a # comment that isn't inside a string.
+func stripComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return line[:i]
+		}
+	}
+
+	return line
+}
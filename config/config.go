@@ -1,22 +1,62 @@
 package config
 
 import (
+	"fmt"
+	"net/url"
 	"os"
+	"regexp"
 	"strconv"
-
-	"github.com/joho/godotenv"
+	"strings"
+	"time"
 )
 
 type App struct {
-	Port        string
-	Env         string
-	MaxFileSize int64
+	Port string
+	Env  string
+	// LogLevel overrides the level of the environment, debug in development and info otherwise
+	LogLevel string
+	// ReloadInterval is how often config files are checked for changes; 0 only reloads on SIGHUP
+	ReloadInterval time.Duration
+	// ShutdownTimeout is how long in-flight calls get to finish before the server is stopped forcibly
+	ShutdownTimeout time.Duration
+	// ShutdownDelay is how long the server keeps accepting calls after reporting NOT_SERVING, so load
+	// balancers stop routing to it first. It counts towards ShutdownTimeout.
+	ShutdownDelay time.Duration
 }
 
 type DB struct {
 	Url string
 }
 
+type Redis struct {
+	Host     string
+	Port     int
+	Password string
+}
+
+type Cache struct {
+	TTL         time.Duration
+	NegativeTTL time.Duration
+	LRUSize     int
+}
+
+type Reconcile struct {
+	OrphanMinAge time.Duration
+}
+
+// RetentionPolicy is the object lock applied to new uploads of a category.
+type RetentionPolicy struct {
+	Mode      string
+	Period    time.Duration
+	LegalHold bool
+}
+
+// Quota limits what a single uploader may store in a category. 0 means unlimited.
+type Quota struct {
+	MaxBytes   int64
+	MaxObjects int64
+}
+
 type Store struct {
 	Endpoint   string
 	AccessKey  string
@@ -25,45 +65,522 @@ type Store struct {
 	BucketName string
 	Region     string
 	Token      string
+	// HardDelete removes objects immediately instead of moving them to the trash
+	HardDelete bool
+	// TrashBucketName holds deleted objects until they are restored or purged. It is kept apart
+	// from BucketName so it is never covered by its public-read policy.
+	TrashBucketName    string
+	TrashRetention     time.Duration
+	TrashPurgeInterval time.Duration
+	// Retention maps an upload category to the object lock set on its objects
+	Retention map[string]RetentionPolicy
+	// Quotas maps an upload category to its per-uploader limits; the "*" entry applies to
+	// categories without one of their own
+	Quotas map[string]Quota
+	// MaxFileSize is the largest upload accepted, in bytes. 0 means unlimited. It is the only upload
+	// limit and is set from APP_MAX_FILE_SIZE_MB.
+	MaxFileSize int64
+	// IdempotencyWindow is how long an upload's idempotency key is remembered; 0 ignores the keys.
+	// Keys are kept in the Postgres idempotency_keys table, so every replica sees the same ones.
+	IdempotencyWindow time.Duration
+	// Bootstrap lets startup create a missing bucket and apply BucketPolicy, Versioning and the
+	// lifecycle rules below. Without it the bucket is only checked, and startup fails if it is unusable.
+	Bootstrap bool
+	// BucketPolicy is "public-read" or "private"; empty leaves the bucket's policy alone
+	BucketPolicy string
+	// Versioning is "enabled" or "suspended"; empty leaves the bucket's versioning alone
+	Versioning string
+	// NoncurrentVersionExpiryDays and AbortIncompleteUploadDays are lifecycle rules; 0 leaves them out
+	NoncurrentVersionExpiryDays int
+	AbortIncompleteUploadDays   int
+	// RetryAttempts is how many times a call failing with a transient backend error is tried, on top
+	// of minio-go's own retries, waiting a jittered RetryBaseDelay doubled per attempt and capped at
+	// RetryMaxDelay. Removing the latest version of an object is only retried by minio-go.
+	RetryAttempts  int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// BreakerFailures consecutive transient failures open the circuit breaker, failing calls
+	// without reaching the backend until BreakerCooldown has passed
+	BreakerFailures int
+	BreakerCooldown time.Duration
 }
 
-type Config struct {
-	App   App   `mapstructure:"app"`
-	Store Store `mapstructure:"store"`
+// Auth configures how callers of the gRPC server are authenticated. Startup fails when neither
+// shared secrets, a JWT secret nor client certificates are set, unless Disabled is set.
+type Auth struct {
+	// SharedSecrets maps a caller name to the secret it presents as its bearer token
+	SharedSecrets map[string]string
+	// JWTSecret, JWTIssuer and JWTAudience must match the JWT_* settings of the auth service
+	JWTSecret   string
+	JWTIssuer   string
+	JWTAudience string
+	// ExemptMethods are full method names, or service prefixes ending in "/", callable without credentials
+	ExemptMethods []string
+	// PolicyFile is the YAML authorization policy; without one every caller may use ObjectService
+	// but none may use AdminService, so force-deletes, restores, aliases and usage need a policy
+	PolicyFile string
+	// Disabled explicitly allows serving unauthenticated calls when no credentials are configured
+	Disabled bool
 }
 
-func LoadConfig() (config *Config, err error) {
-	if os.Getenv("APP_ENV") == "" {
-		err := godotenv.Load(".env")
-		if err != nil {
-			return nil, err
-		}
-	}
+// GRPC tunes the gRPC server. The message size limit isn't set here but follows the upload
+// limit, APP_MAX_FILE_SIZE_MB, as it is at startup. Durations of 0 keep gRPC's defaults.
+type GRPC struct {
+	MaxConcurrentStreams int
+	// KeepaliveTime is how long a connection is idle before the server pings the client, which
+	// then has KeepaliveTimeout to answer
+	KeepaliveTime    time.Duration
+	KeepaliveTimeout time.Duration
+	// KeepaliveMinTime is the shortest interval between client pings tolerated before the
+	// connection is closed; PermitWithoutStream allows pings while no call is active
+	KeepaliveMinTime             time.Duration
+	KeepalivePermitWithoutStream bool
+	MaxConnectionIdle            time.Duration
+	// MaxConnectionAge closes connections after this long, giving calls on them
+	// MaxConnectionAgeGrace to finish, so that clients rebalance across replicas
+	MaxConnectionAge      time.Duration
+	MaxConnectionAgeGrace time.Duration
+	// Compression is "gzip" to compress responses to clients that accept it; empty disables it
+	Compression string
+}
 
-	maxFileSizeMB, err := strconv.ParseInt(os.Getenv("APP_MAX_FILE_SIZE_MB"), 10, 64)
+// TLS enables TLS on the gRPC server when CertFile and KeyFile are set. Setting ClientCAFile
+// additionally requires clients to present a certificate signed by one of its CAs.
+type TLS struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+}
+
+// Metrics serves Prometheus metrics on Port; an empty Port disables the listener.
+type Metrics struct {
+	Port string
+}
+
+// Tracing selects where OpenTelemetry spans are exported: "otlp" sends them to OTLPEndpoint
+// over gRPC, "stdout" prints them, and an empty Exporter disables tracing.
+type Tracing struct {
+	Exporter     string
+	OTLPEndpoint string
+	OTLPInsecure bool
+	SampleRatio  float64
+}
+
+// Health controls the storage backend probe behind the readiness health service. With Canary
+// set, every probe also writes, checks and removes a small object.
+type Health struct {
+	Interval time.Duration
+	Timeout  time.Duration
+	Canary   bool
+	// Instance names this replica's canary object, so replicas don't delete each other's;
+	// it defaults to the hostname, which is the pod name on Kubernetes
+	Instance string
+}
+
+// Limits bound the work the server takes on at once. A call over a limit waits up to
+// QueueTimeout, and no longer than its deadline, before it's rejected with ResourceExhausted.
+type Limits struct {
+	// InFlightBytes caps the request bytes uploads hold at once; 0 means unlimited. An upload is
+	// counted at the largest message size before its request is read, so this allows at most
+	// InFlightBytes divided by that many uploads at a time, and at least one
+	InFlightBytes int64
+	// Concurrency maps a method name, e.g. Upload, to how many of its calls run at once; the
+	// "*" entry applies to methods without one of their own
+	Concurrency  map[string]int
+	QueueTimeout time.Duration
+}
+
+type Config struct {
+	App       App       `mapstructure:"app"`
+	DB        DB        `mapstructure:"db"`
+	Redis     Redis     `mapstructure:"redis"`
+	Cache     Cache     `mapstructure:"cache"`
+	Store     Store     `mapstructure:"store"`
+	Reconcile Reconcile `mapstructure:"reconcile"`
+	Auth      Auth      `mapstructure:"auth"`
+	TLS       TLS       `mapstructure:"tls"`
+	Metrics   Metrics   `mapstructure:"metrics"`
+	Tracing   Tracing   `mapstructure:"tracing"`
+	Health    Health    `mapstructure:"health"`
+	Limits    Limits    `mapstructure:"limits"`
+	GRPC      GRPC      `mapstructure:"grpc"`
+	// Files are the config, .env and secret files the configuration was read from
+	Files []string `mapstructure:"-"`
+}
+
+// LoadConfig builds the configuration from defaults, the config file, the environment and
+// flags, in increasing precedence. Every invalid setting is reported in one ValidationError.
+func LoadConfig(flags *Flags) (config *Config, err error) {
+	values, files, err := loadValues(flags)
 	if err != nil {
 		return nil, err
 	}
+	l := &loader{values: values}
+
 	appConfig := App{
-		Port:        os.Getenv("APP_PORT"),
-		Env:         os.Getenv("APP_ENV"),
-		MaxFileSize: maxFileSizeMB,
+		Port: l.required("APP_PORT"),
+		Env:  l.string("APP_ENV"),
+
+		LogLevel:        l.oneOf("APP_LOG_LEVEL", "", "debug", "info", "warn", "error"),
+		ReloadInterval:  l.duration("APP_RELOAD_INTERVAL_SECONDS", time.Second),
+		ShutdownTimeout: l.duration("APP_SHUTDOWN_TIMEOUT_SECONDS", time.Second),
+		ShutdownDelay:   l.duration("APP_SHUTDOWN_DELAY_SECONDS", time.Second),
+	}
+	l.check(appConfig.ShutdownTimeout > 0, "APP_SHUTDOWN_TIMEOUT_SECONDS", "must be positive")
+	l.check(appConfig.ShutdownDelay >= 0, "APP_SHUTDOWN_DELAY_SECONDS", "must not be negative")
+	l.check(appConfig.ShutdownDelay < appConfig.ShutdownTimeout, "APP_SHUTDOWN_DELAY_SECONDS", "must be less than APP_SHUTDOWN_TIMEOUT_SECONDS")
+
+	dbConfig := DB{
+		Url: l.required("DB_URL"),
 	}
 
+	redisConfig := Redis{
+		Host:     l.string("REDIS_HOST"),
+		Port:     l.int("REDIS_PORT"),
+		Password: l.string("REDIS_PASSWORD"),
+	}
+
+	cacheConfig := Cache{
+		TTL:         l.duration("CACHE_TTL_SECONDS", time.Second),
+		NegativeTTL: l.duration("CACHE_NEGATIVE_TTL_SECONDS", time.Second),
+		LRUSize:     l.int("CACHE_LRU_SIZE"),
+	}
+	l.check(cacheConfig.LRUSize > 0, "CACHE_LRU_SIZE", "must be positive")
+
+	retention, err := parseRetentionPolicies(l.string("STORE_RETENTION_POLICIES"))
+	l.fail("STORE_RETENTION_POLICIES", err)
+	quotas, err := parseQuotas(l.string("STORE_QUOTAS"))
+	l.fail("STORE_QUOTAS", err)
+
+	maxFileSizeMB := l.int64("APP_MAX_FILE_SIZE_MB")
+	l.check(maxFileSizeMB >= 0, "APP_MAX_FILE_SIZE_MB", "must not be negative")
 	storeConfig := Store{
-		BucketName: os.Getenv("STORE_BUCKET_NAME"),
-		Endpoint:   os.Getenv("STORE_ENDPOINT"),
-		AccessKey:  os.Getenv("STORE_ACCESS_KEY"),
-		SecretKey:  os.Getenv("STORE_SECRET_KEY"),
-		UseSSL:     os.Getenv("STORE_USE_SSL") == "true",
+		BucketName: l.required("STORE_BUCKET_NAME"),
+		Endpoint:   l.required("STORE_ENDPOINT"),
+		AccessKey:  l.required("STORE_ACCESS_KEY"),
+		SecretKey:  l.required("STORE_SECRET_KEY"),
+		UseSSL:     l.bool("STORE_USE_SSL"),
+
+		HardDelete:         l.bool("STORE_HARD_DELETE"),
+		TrashBucketName:    l.string("STORE_TRASH_BUCKET_NAME"),
+		TrashRetention:     l.duration("STORE_TRASH_RETENTION_HOURS", time.Hour),
+		TrashPurgeInterval: l.duration("STORE_TRASH_PURGE_INTERVAL_MINUTES", time.Minute),
+		Retention:          retention,
+		Quotas:             quotas,
+		MaxFileSize:        maxFileSizeMB * 1024 * 1024,
+		IdempotencyWindow:  l.duration("STORE_IDEMPOTENCY_WINDOW_MINUTES", time.Minute),
+
+		Bootstrap:                   l.bool("STORE_BUCKET_BOOTSTRAP"),
+		BucketPolicy:                l.oneOf("STORE_BUCKET_POLICY", "", "public-read", "private"),
+		Versioning:                  l.oneOf("STORE_BUCKET_VERSIONING", "", "enabled", "suspended"),
+		NoncurrentVersionExpiryDays: l.int("STORE_LIFECYCLE_NONCURRENT_DAYS"),
+		AbortIncompleteUploadDays:   l.int("STORE_LIFECYCLE_ABORT_UPLOAD_DAYS"),
+
+		RetryAttempts:   l.int("STORE_RETRY_ATTEMPTS"),
+		RetryBaseDelay:  l.duration("STORE_RETRY_BASE_DELAY_MS", time.Millisecond),
+		RetryMaxDelay:   l.duration("STORE_RETRY_MAX_DELAY_MS", time.Millisecond),
+		BreakerFailures: l.int("STORE_BREAKER_FAILURES"),
+		BreakerCooldown: l.duration("STORE_BREAKER_COOLDOWN_SECONDS", time.Second),
+	}
+	l.check(storeConfig.IdempotencyWindow >= 0, "STORE_IDEMPOTENCY_WINDOW_MINUTES", "must not be negative")
+	l.check(storeConfig.RetryAttempts > 0, "STORE_RETRY_ATTEMPTS", "must be positive")
+	l.check(storeConfig.RetryMaxDelay >= storeConfig.RetryBaseDelay, "STORE_RETRY_MAX_DELAY_MS", "must not be less than STORE_RETRY_BASE_DELAY_MS")
+	l.check(storeConfig.BreakerFailures > 0, "STORE_BREAKER_FAILURES", "must be positive")
+	l.check(storeConfig.BreakerCooldown > 0, "STORE_BREAKER_COOLDOWN_SECONDS", "must be positive")
+	if !storeConfig.HardDelete {
+		l.check(storeConfig.TrashPurgeInterval > 0, "STORE_TRASH_PURGE_INTERVAL_MINUTES", "must be positive")
+	}
+	if storeConfig.TrashBucketName == "" {
+		storeConfig.TrashBucketName = storeConfig.BucketName + "-trash"
+	}
+	l.check(storeConfig.TrashBucketName != storeConfig.BucketName, "STORE_TRASH_BUCKET_NAME", "must differ from STORE_BUCKET_NAME")
+
+	reconcileConfig := Reconcile{
+		OrphanMinAge: l.duration("RECONCILE_ORPHAN_MIN_AGE_HOURS", time.Hour),
+	}
+
+	sharedSecrets, err := parseSharedSecrets(l.string("AUTH_SHARED_SECRETS"))
+	l.fail("AUTH_SHARED_SECRETS", err)
+	authConfig := Auth{
+		SharedSecrets: sharedSecrets,
+		JWTSecret:     l.string("JWT_SECRET"),
+		JWTIssuer:     l.string("JWT_ISSUER"),
+		JWTAudience:   l.string("JWT_AUDIENCE"),
+		ExemptMethods: splitList(l.string("AUTH_EXEMPT_METHODS")),
+		PolicyFile:    l.string("AUTH_POLICY_FILE"),
+		Disabled:      l.bool("AUTH_DISABLED"),
+	}
+
+	grpcConfig := GRPC{
+		MaxConcurrentStreams:         l.int("GRPC_MAX_CONCURRENT_STREAMS"),
+		KeepaliveTime:                l.duration("GRPC_KEEPALIVE_TIME_SECONDS", time.Second),
+		KeepaliveTimeout:             l.duration("GRPC_KEEPALIVE_TIMEOUT_SECONDS", time.Second),
+		KeepaliveMinTime:             l.duration("GRPC_KEEPALIVE_MIN_TIME_SECONDS", time.Second),
+		KeepalivePermitWithoutStream: l.bool("GRPC_KEEPALIVE_PERMIT_WITHOUT_STREAM"),
+		MaxConnectionIdle:            l.duration("GRPC_MAX_CONNECTION_IDLE_SECONDS", time.Second),
+		MaxConnectionAge:             l.duration("GRPC_MAX_CONNECTION_AGE_SECONDS", time.Second),
+		MaxConnectionAgeGrace:        l.duration("GRPC_MAX_CONNECTION_AGE_GRACE_SECONDS", time.Second),
+		Compression:                  l.oneOf("GRPC_COMPRESSION", "", "gzip"),
+	}
+	l.check(grpcConfig.MaxConcurrentStreams >= 0, "GRPC_MAX_CONCURRENT_STREAMS", "must not be negative")
+	l.check(grpcConfig.KeepaliveTime >= 0 && grpcConfig.KeepaliveTimeout >= 0 && grpcConfig.KeepaliveMinTime >= 0, "GRPC_KEEPALIVE_TIME_SECONDS", "keepalive durations must not be negative")
+	l.check(grpcConfig.MaxConnectionIdle >= 0 && grpcConfig.MaxConnectionAge >= 0 && grpcConfig.MaxConnectionAgeGrace >= 0, "GRPC_MAX_CONNECTION_AGE_SECONDS", "connection durations must not be negative")
+
+	tlsConfig := TLS{
+		CertFile:     l.string("TLS_CERT_FILE"),
+		KeyFile:      l.string("TLS_KEY_FILE"),
+		ClientCAFile: l.string("TLS_CLIENT_CA_FILE"),
+	}
+	l.check((tlsConfig.CertFile == "") == (tlsConfig.KeyFile == ""), "TLS_CERT_FILE", "must be set together with TLS_KEY_FILE")
+	l.check(tlsConfig.ClientCAFile == "" || tlsConfig.CertFile != "", "TLS_CLIENT_CA_FILE", "requires TLS_CERT_FILE and TLS_KEY_FILE")
+
+	metricsConfig := Metrics{
+		Port: l.string("METRICS_PORT"),
+	}
+
+	tracingConfig := Tracing{
+		Exporter:     l.oneOf("TRACING_EXPORTER", "", "stdout", "otlp"),
+		OTLPEndpoint: l.string("TRACING_OTLP_ENDPOINT"),
+		OTLPInsecure: l.bool("TRACING_OTLP_INSECURE"),
+		SampleRatio:  l.float("TRACING_SAMPLE_RATIO"),
+	}
+	l.check(tracingConfig.Exporter != "otlp" || tracingConfig.OTLPEndpoint != "", "TRACING_OTLP_ENDPOINT", "is required with the otlp exporter")
+	l.check(tracingConfig.SampleRatio >= 0 && tracingConfig.SampleRatio <= 1, "TRACING_SAMPLE_RATIO", "must be between 0 and 1")
+
+	healthConfig := Health{
+		Interval: l.duration("HEALTH_CHECK_INTERVAL_SECONDS", time.Second),
+		Timeout:  l.duration("HEALTH_CHECK_TIMEOUT_SECONDS", time.Second),
+		Canary:   l.bool("HEALTH_CHECK_CANARY"),
+		Instance: l.string("HEALTH_CHECK_INSTANCE"),
+	}
+	if healthConfig.Instance == "" {
+		healthConfig.Instance, _ = os.Hostname()
+	}
+	l.check(healthConfig.Interval > 0, "HEALTH_CHECK_INTERVAL_SECONDS", "must be positive")
+	l.check(healthConfig.Timeout > 0, "HEALTH_CHECK_TIMEOUT_SECONDS", "must be positive")
+	l.check(!healthConfig.Canary || healthConfig.Instance != "", "HEALTH_CHECK_INSTANCE", "is required with the canary when the hostname is unknown")
+
+	inFlightMB := l.int64("LIMIT_INFLIGHT_MB")
+	l.check(inFlightMB >= 0, "LIMIT_INFLIGHT_MB", "must not be negative")
+	concurrency, err := parseConcurrency(l.string("LIMIT_CONCURRENCY"))
+	l.fail("LIMIT_CONCURRENCY", err)
+	limitsConfig := Limits{
+		InFlightBytes: inFlightMB * 1024 * 1024,
+		Concurrency:   concurrency,
+		QueueTimeout:  l.duration("LIMIT_QUEUE_TIMEOUT_MS", time.Millisecond),
+	}
+	l.check(limitsConfig.QueueTimeout >= 0, "LIMIT_QUEUE_TIMEOUT_MS", "must not be negative")
+
+	if err := l.err(); err != nil {
+		return nil, err
 	}
 
 	return &Config{
-		App:   appConfig,
-		Store: storeConfig,
+		App:       appConfig,
+		DB:        dbConfig,
+		Redis:     redisConfig,
+		Cache:     cacheConfig,
+		Store:     storeConfig,
+		Reconcile: reconcileConfig,
+		Auth:      authConfig,
+		TLS:       tlsConfig,
+		Metrics:   metricsConfig,
+		Tracing:   tracingConfig,
+		Health:    healthConfig,
+		Limits:    limitsConfig,
+		GRPC:      grpcConfig,
+		Files:     files,
 	}, nil
 }
 
+// parseRetentionPolicies parses a comma separated list of category=MODE:period[:legal-hold],
+// e.g. "profile=GOVERNANCE:720h,evidence=COMPLIANCE:8760h:legal-hold".
+func parseRetentionPolicies(value string) (map[string]RetentionPolicy, error) {
+	policies := make(map[string]RetentionPolicy)
+	if strings.TrimSpace(value) == "" {
+		return policies, nil
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		category, spec, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || category == "" {
+			return nil, fmt.Errorf("invalid retention policy %q: expected category=MODE:period[:legal-hold]", entry)
+		}
+
+		parts := strings.Split(spec, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid retention policy %q: expected category=MODE:period[:legal-hold]", entry)
+		}
+
+		mode := strings.ToUpper(parts[0])
+		if mode != "GOVERNANCE" && mode != "COMPLIANCE" {
+			return nil, fmt.Errorf("invalid retention mode %q for category %v", parts[0], category)
+		}
+
+		period, err := time.ParseDuration(parts[1])
+		if err != nil || period <= 0 {
+			return nil, fmt.Errorf("invalid retention period %q for category %v", parts[1], category)
+		}
+
+		legalHold := len(parts) == 3
+		if legalHold && parts[2] != "legal-hold" {
+			return nil, fmt.Errorf("invalid retention flag %q for category %v", parts[2], category)
+		}
+
+		policies[category] = RetentionPolicy{
+			Mode:      mode,
+			Period:    period,
+			LegalHold: legalHold,
+		}
+	}
+
+	return policies, nil
+}
+
+// parseQuotas parses a comma separated list of category=maxMB:maxObjects,
+// e.g. "profile=5:1,*=100:50". Either limit may be 0 for unlimited.
+func parseQuotas(value string) (map[string]Quota, error) {
+	quotas := make(map[string]Quota)
+	if strings.TrimSpace(value) == "" {
+		return quotas, nil
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		category, spec, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		maxMB, maxObjects, ok2 := strings.Cut(spec, ":")
+		if !ok || !ok2 || category == "" {
+			return nil, fmt.Errorf("invalid quota %q: expected category=maxMB:maxObjects", entry)
+		}
+
+		mb, err := strconv.ParseInt(maxMB, 10, 64)
+		if err != nil || mb < 0 {
+			return nil, fmt.Errorf("invalid quota size %q for category %v", maxMB, category)
+		}
+		objects, err := strconv.ParseInt(maxObjects, 10, 64)
+		if err != nil || objects < 0 {
+			return nil, fmt.Errorf("invalid quota object count %q for category %v", maxObjects, category)
+		}
+
+		quotas[category] = Quota{
+			MaxBytes:   mb * 1024 * 1024,
+			MaxObjects: objects,
+		}
+	}
+
+	return quotas, nil
+}
+
+// parseConcurrency parses a comma separated list of method=limit, e.g. "Upload=16,*=64".
+func parseConcurrency(value string) (map[string]int, error) {
+	limits := make(map[string]int)
+	for _, entry := range splitList(value) {
+		method, limit, ok := strings.Cut(entry, "=")
+		if !ok || method == "" {
+			return nil, fmt.Errorf("invalid concurrency limit %q: expected method=limit", entry)
+		}
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid concurrency limit %q for method %v", limit, method)
+		}
+		limits[method] = n
+	}
+
+	return limits, nil
+}
+
+// parseSharedSecrets parses a comma separated list of caller=secret, e.g. "gateway=s3cr3t,backend=0th3r".
+func parseSharedSecrets(value string) (map[string]string, error) {
+	secrets := make(map[string]string)
+	for _, entry := range splitList(value) {
+		caller, secret, ok := strings.Cut(entry, "=")
+		if !ok || caller == "" || secret == "" {
+			return nil, fmt.Errorf("invalid shared secret entry for %q: expected caller=secret", caller)
+		}
+		secrets[caller] = secret
+	}
+
+	return secrets, nil
+}
+
+// splitList splits a comma separated list, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func (a *Auth) Enabled() bool {
+	return len(a.SharedSecrets) > 0 || a.JWTSecret != ""
+}
+
+func (t *TLS) Enabled() bool {
+	return t.CertFile != ""
+}
+
 func (a *App) IsDevelopment() bool {
 	return a.Env == "development"
 }
+
+const redacted = "REDACTED"
+
+// Redacted returns a copy of c that is safe to log, with credentials masked.
+func (c Config) Redacted() Config {
+	c.DB.Url = redactURL(c.DB.Url)
+	c.Redis.Password = redact(c.Redis.Password)
+	c.Store.AccessKey = redact(c.Store.AccessKey)
+	c.Store.SecretKey = redact(c.Store.SecretKey)
+	c.Store.Token = redact(c.Store.Token)
+	c.Auth.JWTSecret = redact(c.Auth.JWTSecret)
+
+	sharedSecrets := make(map[string]string, len(c.Auth.SharedSecrets))
+	for caller, secret := range c.Auth.SharedSecrets {
+		sharedSecrets[caller] = redact(secret)
+	}
+	c.Auth.SharedSecrets = sharedSecrets
+
+	return c
+}
+
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	return redacted
+}
+
+// redactURL masks the passwords of a database DSN: the userinfo and any password query parameter
+// of a URL, or the password of a key/value DSN. A URL that doesn't parse is masked entirely.
+func redactURL(value string) string {
+	if !strings.Contains(value, "://") {
+		return dsnPassword.ReplaceAllString(value, "${1}"+redacted)
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return redact(value)
+	}
+	if _, ok := parsed.User.Password(); ok {
+		parsed.User = url.UserPassword(parsed.User.Username(), redacted)
+	}
+	if parsed.RawQuery != "" {
+		params := strings.Split(parsed.RawQuery, "&")
+		for i, param := range params {
+			name, _, _ := strings.Cut(param, "=")
+			if strings.EqualFold(name, "password") {
+				params[i] = name + "=" + redacted
+			}
+		}
+		parsed.RawQuery = strings.Join(params, "&")
+	}
+
+	return parsed.String()
+}
+
+// dsnPassword matches the password of a key/value DSN such as "host=db user=root password='s3 cr3t'".
+var dsnPassword = regexp.MustCompile(`(?i)(\bpassword\s*=\s*)('(?:[^'\\]|\\.)*'|\S*)`)
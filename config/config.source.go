@@ -0,0 +1,269 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// defaults lists every setting, keyed by its environment variable, with the value used when
+// no source sets it. Settings missing here are rejected in config files.
+var defaults = map[string]string{
+	"APP_PORT":                     "3005",
+	"APP_ENV":                      "development",
+	"APP_MAX_FILE_SIZE_MB":         "10",
+	"APP_LOG_LEVEL":                "",
+	"APP_RELOAD_INTERVAL_SECONDS":  "10",
+	"APP_SHUTDOWN_TIMEOUT_SECONDS": "30",
+	"APP_SHUTDOWN_DELAY_SECONDS":   "5",
+
+	"DB_URL": "",
+
+	"REDIS_HOST":     "",
+	"REDIS_PORT":     "6379",
+	"REDIS_PASSWORD": "",
+
+	"CACHE_TTL_SECONDS":          "300",
+	"CACHE_NEGATIVE_TTL_SECONDS": "30",
+	"CACHE_LRU_SIZE":             "10000",
+
+	"STORE_ENDPOINT":                     "",
+	"STORE_ACCESS_KEY":                   "",
+	"STORE_SECRET_KEY":                   "",
+	"STORE_USE_SSL":                      "false",
+	"STORE_BUCKET_NAME":                  "",
+	"STORE_HARD_DELETE":                  "false",
+	"STORE_TRASH_BUCKET_NAME":            "",
+	"STORE_TRASH_RETENTION_HOURS":        "168",
+	"STORE_TRASH_PURGE_INTERVAL_MINUTES": "60",
+	"STORE_RETENTION_POLICIES":           "",
+	"STORE_QUOTAS":                       "",
+	"STORE_IDEMPOTENCY_WINDOW_MINUTES":   "60",
+	"STORE_BUCKET_BOOTSTRAP":             "false",
+	"STORE_BUCKET_POLICY":                "",
+	"STORE_BUCKET_VERSIONING":            "",
+	"STORE_LIFECYCLE_NONCURRENT_DAYS":    "0",
+	"STORE_LIFECYCLE_ABORT_UPLOAD_DAYS":  "0",
+	"STORE_RETRY_ATTEMPTS":               "3",
+	"STORE_RETRY_BASE_DELAY_MS":          "100",
+	"STORE_RETRY_MAX_DELAY_MS":           "2000",
+	"STORE_BREAKER_FAILURES":             "5",
+	"STORE_BREAKER_COOLDOWN_SECONDS":     "30",
+
+	"RECONCILE_ORPHAN_MIN_AGE_HOURS": "24",
+
+	"AUTH_SHARED_SECRETS": "",
+	"AUTH_EXEMPT_METHODS": "/grpc.health.v1.Health/,/grpc.reflection.v1.ServerReflection/,/grpc.reflection.v1alpha.ServerReflection/",
+	"AUTH_POLICY_FILE":    "",
+	"AUTH_DISABLED":       "false",
+	"JWT_SECRET":          "",
+	"JWT_ISSUER":          "",
+	"JWT_AUDIENCE":        "",
+
+	"GRPC_MAX_CONCURRENT_STREAMS":           "100",
+	"GRPC_KEEPALIVE_TIME_SECONDS":           "120",
+	"GRPC_KEEPALIVE_TIMEOUT_SECONDS":        "20",
+	"GRPC_KEEPALIVE_MIN_TIME_SECONDS":       "30",
+	"GRPC_KEEPALIVE_PERMIT_WITHOUT_STREAM":  "false",
+	"GRPC_MAX_CONNECTION_IDLE_SECONDS":      "0",
+	"GRPC_MAX_CONNECTION_AGE_SECONDS":       "0",
+	"GRPC_MAX_CONNECTION_AGE_GRACE_SECONDS": "30",
+	"GRPC_COMPRESSION":                      "gzip",
+
+	"TLS_CERT_FILE":      "",
+	"TLS_KEY_FILE":       "",
+	"TLS_CLIENT_CA_FILE": "",
+
+	"METRICS_PORT": "",
+
+	"TRACING_EXPORTER":      "",
+	"TRACING_OTLP_ENDPOINT": "",
+	"TRACING_OTLP_INSECURE": "false",
+	"TRACING_SAMPLE_RATIO":  "1",
+
+	"HEALTH_CHECK_INTERVAL_SECONDS": "10",
+	"HEALTH_CHECK_TIMEOUT_SECONDS":  "5",
+	"HEALTH_CHECK_CANARY":           "false",
+	"HEALTH_CHECK_INSTANCE":         "",
+
+	"LIMIT_INFLIGHT_MB":      "256",
+	"LIMIT_CONCURRENCY":      "Upload=16",
+	"LIMIT_QUEUE_TIMEOUT_MS": "5000",
+}
+
+// secretFileSuffix marks a setting read from the file it names, e.g. STORE_SECRET_KEY_FILE
+// for a Docker or Kubernetes secret. It takes precedence over the setting itself.
+const secretFileSuffix = "_FILE"
+
+// Flags is the command line layer of the configuration: -config names the config file and every
+// setting has a flag of its own, e.g. -store-bucket-name for STORE_BUCKET_NAME.
+type Flags struct {
+	file   string
+	values map[string]string
+}
+
+// RegisterFlags defines the configuration flags on fs. Pass the result to LoadConfig once fs is parsed.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{values: make(map[string]string)}
+	fs.StringVar(&f.file, "config", "", "YAML (.yaml, .yml) or TOML (.toml) config file; CONFIG_FILE is used when empty")
+
+	for _, key := range settingKeys() {
+		fs.Var(&flagValue{key: key, values: f.values}, flagName(key), "overrides "+key)
+	}
+
+	return f
+}
+
+type flagValue struct {
+	key    string
+	values map[string]string
+}
+
+func (v *flagValue) String() string {
+	if v.values == nil {
+		return ""
+	}
+
+	return v.values[v.key]
+}
+
+func (v *flagValue) Set(value string) error {
+	v.values[v.key] = value
+	return nil
+}
+
+func flagName(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+}
+
+func settingKeys() []string {
+	keys := make([]string, 0, len(defaults))
+	for key := range defaults {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// isSetting reports whether key, or the setting it reads from a file, is known.
+func isSetting(key string) bool {
+	if _, ok := defaults[key]; ok {
+		return true
+	}
+	_, ok := defaults[strings.TrimSuffix(key, secretFileSuffix)]
+
+	return ok && strings.HasSuffix(key, secretFileSuffix)
+}
+
+// loadValues layers defaults, the config file, .env, the environment and flags, later sources
+// overriding earlier ones. A *_FILE setting beats a plain value for the same setting in its own
+// source, and is only read when no later source sets the setting. It also returns the files it read.
+func loadValues(flags *Flags) (map[string]string, []string, error) {
+	if flags == nil {
+		flags = &Flags{}
+	}
+	var files []string
+
+	// .env is optional and only read when the environment doesn't configure the app itself
+	dotenv := make(map[string]string)
+	if os.Getenv("APP_ENV") == "" {
+		var err error
+		dotenv, err = godotenv.Read(".env")
+		if errors.Is(err, os.ErrNotExist) {
+			dotenv = make(map[string]string)
+		} else if err != nil {
+			return nil, nil, err
+		} else {
+			files = append(files, ".env")
+		}
+	}
+	lookup := func(name string) (string, bool) {
+		if value, ok := os.LookupEnv(name); ok {
+			return value, true
+		}
+		value, ok := dotenv[name]
+		return value, ok
+	}
+
+	values := make(map[string]string, len(defaults))
+	for key, value := range defaults {
+		values[key] = value
+	}
+
+	var layers []map[string]string
+	file := flags.file
+	if file == "" {
+		file, _ = lookup("CONFIG_FILE")
+	}
+	if file != "" {
+		fileValues, err := readFile(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		layers = append(layers, fileValues)
+		files = append(files, file)
+	}
+
+	env := make(map[string]string)
+	for key := range defaults {
+		for _, name := range []string{key, key + secretFileSuffix} {
+			if value, ok := lookup(name); ok {
+				env[name] = value
+			}
+		}
+	}
+	layers = append(layers, env, flags.values)
+
+	secretFiles := make(map[string]string)
+	for _, layer := range layers {
+		for key, value := range layer {
+			if _, ok := defaults[strings.TrimSuffix(key, secretFileSuffix)]; ok && strings.HasSuffix(key, secretFileSuffix) {
+				continue
+			}
+			values[key] = value
+			delete(secretFiles, key)
+		}
+		for _, key := range settingKeys() {
+			if filename := strings.TrimSpace(layer[key+secretFileSuffix]); filename != "" {
+				secretFiles[key] = filename
+			}
+		}
+	}
+
+	// only the files that won their setting are read, so one overridden by a later source may be missing
+	resolved, problems := resolveSecretFiles(values, secretFiles)
+	if len(problems) > 0 {
+		return nil, nil, &ValidationError{Problems: problems}
+	}
+	files = append(files, resolved...)
+
+	return values, files, nil
+}
+
+// resolveSecretFiles sets each setting in secretFiles to the content of the file named for it.
+func resolveSecretFiles(values map[string]string, secretFiles map[string]string) ([]string, []string) {
+	var files []string
+	var problems []string
+	for _, key := range settingKeys() {
+		filename, ok := secretFiles[key]
+		if !ok {
+			continue
+		}
+
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%v: %v", key+secretFileSuffix, err))
+			continue
+		}
+		values[key] = strings.TrimRight(string(content), "\r\n")
+		files = append(files, filename)
+	}
+
+	return files, problems
+}
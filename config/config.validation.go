@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationError lists everything wrong with the configuration at once.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// loader reads typed settings, collecting a problem for each invalid one instead of stopping at the first.
+type loader struct {
+	values   map[string]string
+	problems []string
+}
+
+func (l *loader) string(key string) string {
+	return strings.TrimSpace(l.values[key])
+}
+
+func (l *loader) required(key string) string {
+	value := l.string(key)
+	l.check(value != "", key, "is required")
+
+	return value
+}
+
+func (l *loader) oneOf(key string, allowed ...string) string {
+	value := l.string(key)
+	for _, option := range allowed {
+		if value == option {
+			return value
+		}
+	}
+	l.problems = append(l.problems, fmt.Sprintf("%v: %q is not one of %v", key, value, strings.Join(allowed, ", ")))
+
+	return value
+}
+
+func (l *loader) int(key string) int {
+	value, err := strconv.Atoi(l.string(key))
+	if err != nil {
+		l.problems = append(l.problems, fmt.Sprintf("%v: %q is not a whole number", key, l.string(key)))
+	}
+
+	return value
+}
+
+func (l *loader) int64(key string) int64 {
+	value, err := strconv.ParseInt(l.string(key), 10, 64)
+	if err != nil {
+		l.problems = append(l.problems, fmt.Sprintf("%v: %q is not a whole number", key, l.string(key)))
+	}
+
+	return value
+}
+
+func (l *loader) float(key string) float64 {
+	value, err := strconv.ParseFloat(l.string(key), 64)
+	if err != nil {
+		l.problems = append(l.problems, fmt.Sprintf("%v: %q is not a number", key, l.string(key)))
+	}
+
+	return value
+}
+
+// bool treats an empty setting as false.
+func (l *loader) bool(key string) bool {
+	if l.string(key) == "" {
+		return false
+	}
+
+	value, err := strconv.ParseBool(l.string(key))
+	if err != nil {
+		l.problems = append(l.problems, fmt.Sprintf("%v: %q is not true or false", key, l.string(key)))
+	}
+
+	return value
+}
+
+// duration reads a whole number of units, rejecting negative values.
+func (l *loader) duration(key string, unit time.Duration) time.Duration {
+	value := l.int64(key)
+	l.check(value >= 0, key, "must not be negative")
+
+	return time.Duration(value) * unit
+}
+
+func (l *loader) check(ok bool, key string, problem string) {
+	if !ok {
+		l.problems = append(l.problems, key+": "+problem)
+	}
+}
+
+func (l *loader) fail(key string, err error) {
+	if err != nil {
+		l.problems = append(l.problems, key+": "+err.Error())
+	}
+}
+
+func (l *loader) err() error {
+	if len(l.problems) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Problems: l.problems}
+}
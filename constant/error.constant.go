@@ -1,13 +1,27 @@
 package constant
 
 const InvalidTokenErrorMessage = "Invalid token"
+const MissingCredentialsErrorMessage = "Missing credentials"
+const PermissionDeniedErrorMessage = "Permission denied"
 const InternalServerErrorMessage = "Internal server error"
+const StorageUnavailableErrorMessage = "Storage is temporarily unavailable"
+const ServerBusyErrorMessage = "Server is busy, try again later"
 
 const FileNotFoundErrorMessage = "File cannot be empty"
 const InvalidFileTypeErrorMessage = "Invalid file type"
 const InvalidFileSizeErrorMessage = "Invalid file size"
+const FileTooLargeErrorMessage = "File exceeds the maximum upload size"
 const InvalidFileErrorMessage = "Invalid file"
 const InvalidFileUrlErrorMessage = "Invalid file url"
 
 const KeyEmptyErrorMessage = "Key is empty"
 const ObjectNotFoundErrorMessage = "Object not found"
+const OwnerEmptyErrorMessage = "Owner is empty"
+const QuotaExceededErrorMessage = "Storage quota exceeded"
+const ObjectRetainedErrorMessage = "Object is under retention and cannot be deleted"
+const AliasEmptyErrorMessage = "Alias is empty"
+const VersionIdEmptyErrorMessage = "Version ID is empty"
+const InvalidChecksumErrorMessage = "Invalid checksum"
+const ChecksumMismatchErrorMessage = "Checksum does not match the uploaded data"
+const IdempotencyKeyReusedErrorMessage = "Idempotency key was already used for different data"
+const IdempotencyKeyInProgressErrorMessage = "An upload with this idempotency key is still in progress"
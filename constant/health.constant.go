@@ -0,0 +1,7 @@
+package constant
+
+// LivenessService reports SERVING for as long as the process is up.
+const LivenessService = "liveness"
+
+// ReadinessService reports SERVING only while the storage backend is reachable.
+const ReadinessService = "readiness"
@@ -0,0 +1,28 @@
+package constant
+
+// gRPC metadata keys set by callers (normally the gateway) on behalf of the end user
+const UserIdMetadataKey = "x-user-id"
+const CategoryMetadataKey = "x-object-category"
+
+// VersionIdMetadataKey selects a specific object version on requests, and carries
+// the version ID written by Upload and RestoreVersion in response headers.
+const VersionIdMetadataKey = "x-version-id"
+
+const DefaultCategory = "default"
+
+// AuthorizationMetadataKey carries the caller's "Bearer <token>" credentials
+const AuthorizationMetadataKey = "authorization"
+
+// RequestIdMetadataKey correlates the logs of a call; it is generated when the caller doesn't
+// send one and is always echoed back in the response headers
+const RequestIdMetadataKey = "x-request-id"
+
+// ChecksumSHA256MetadataKey and ContentMD5MetadataKey carry optional checksums of the data sent
+// to Upload, hex or base64 encoded, which fail the upload when they don't match. Both are
+// always returned in the response headers, the SHA-256 in hex and the MD5 in base64.
+const ChecksumSHA256MetadataKey = "x-checksum-sha256"
+const ContentMD5MetadataKey = "content-md5"
+
+// IdempotencyKeyMetadataKey makes retries of an Upload return the object the first call
+// created instead of creating another one
+const IdempotencyKeyMetadataKey = "x-idempotency-key"
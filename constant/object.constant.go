@@ -0,0 +1,9 @@
+package constant
+
+// AliasPrefix holds the marker objects backing aliases; they are not user objects.
+const AliasPrefix = ".aliases/"
+
+// HealthPrefix holds the canary objects written, checked and removed again by health probes,
+// one per replica under HealthCanaryPrefix.
+const HealthPrefix = ".health/"
+const HealthCanaryPrefix = HealthPrefix + "canary/"
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// migrationLockID is the Postgres advisory lock key held while migrating
+const migrationLockID = 672_167_001
+
+type schemaMigration struct {
+	Version string `gorm:"primaryKey"`
+}
+
+// Migrate applies every migrations/*.up.sql file that has not been recorded in
+// schema_migrations yet, in lexical order, each inside its own transaction.
+// The matching .down.sql files are kept for operators rolling back by hand.
+// Replicas starting together take turns on an advisory lock, so each migration runs once.
+func Migrate(db *gorm.DB) error {
+	// session advisory locks belong to a connection, so everything runs on the one holding it
+	return db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec(`SELECT pg_advisory_lock(?)`, migrationLockID).Error; err != nil {
+			return fmt.Errorf("lock migrations: %w", err)
+		}
+		defer conn.Exec(`SELECT pg_advisory_unlock(?)`, migrationLockID)
+
+		return migrate(conn)
+	})
+}
+
+func migrate(db *gorm.DB) error {
+	if err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version TEXT PRIMARY KEY)`).Error; err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	files, err := fs.Glob(migrations, "migrations/*.up.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		version := strings.TrimSuffix(strings.TrimPrefix(file, "migrations/"), ".up.sql")
+
+		var count int64
+		if err := db.Model(&schemaMigration{}).Where("version = ?", version).Count(&count).Error; err != nil {
+			return fmt.Errorf("check migration %v: %w", version, err)
+		}
+		if count > 0 {
+			continue
+		}
+
+		script, err := migrations.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(string(script)).Error; err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{Version: version}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("apply migration %v: %w", version, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS objects (
+    bucket       TEXT        NOT NULL,
+    key          TEXT        NOT NULL,
+    owner        TEXT        NOT NULL DEFAULT '',
+    category     TEXT        NOT NULL DEFAULT '',
+    size         BIGINT      NOT NULL DEFAULT 0,
+    content_type TEXT        NOT NULL DEFAULT '',
+    checksum     TEXT        NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at   TIMESTAMPTZ,
+    PRIMARY KEY (bucket, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_objects_owner ON objects (owner) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_objects_deleted_at ON objects (deleted_at);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS usages (
+    owner    TEXT   NOT NULL,
+    category TEXT   NOT NULL,
+    bytes    BIGINT NOT NULL DEFAULT 0,
+    objects  BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (owner, category)
+);
@@ -0,0 +1 @@
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS pending BOOLEAN NOT NULL DEFAULT FALSE;
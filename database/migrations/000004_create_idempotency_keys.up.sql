@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    owner      TEXT        NOT NULL,
+    key        TEXT        NOT NULL,
+    checksum   TEXT        NOT NULL,
+    url        TEXT        NOT NULL DEFAULT '',
+    object_key TEXT        NOT NULL DEFAULT '',
+    version_id TEXT        NOT NULL DEFAULT '',
+    pending    BOOLEAN     NOT NULL DEFAULT TRUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (owner, key)
+);
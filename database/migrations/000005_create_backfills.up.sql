@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS backfills (
+    bucket       TEXT        PRIMARY KEY,
+    completed_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,27 @@
+package database
+
+import (
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func InitPostgresDatabase(conf *config.DB, isDebug bool) (db *gorm.DB, err error) {
+	gormConf := &gorm.Config{TranslateError: true}
+
+	if !isDebug {
+		gormConf.Logger = gormLogger.Default.LogMode(gormLogger.Silent)
+	}
+
+	db, err = gorm.Open(postgres.Open(conf.Url), gormConf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = Migrate(db); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
@@ -0,0 +1,22 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/redis/go-redis/v9"
+)
+
+func InitRedis(conf *config.Redis) (*redis.Client, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%v:%v", conf.Host, conf.Port),
+		Password: conf.Password,
+	})
+
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
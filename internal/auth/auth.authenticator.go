@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+	"sync/atomic"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/isd-sgcu/rpkm67-store/constant"
+	"github.com/isd-sgcu/rpkm67-store/internal/utils"
+	"github.com/isd-sgcu/rpkm67-store/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type Authenticator interface {
+	Authenticate(ctx context.Context) (*Identity, error)
+	UnaryServerInterceptor() grpc.UnaryServerInterceptor
+	StreamServerInterceptor() grpc.StreamServerInterceptor
+	// Reload swaps in the shared secrets, JWT settings and exempt methods of conf.
+	Reload(conf *config.Auth)
+}
+
+type authenticatorImpl struct {
+	conf atomic.Pointer[config.Auth]
+	log  *zap.Logger
+}
+
+func NewAuthenticator(conf *config.Auth, log *zap.Logger) Authenticator {
+	a := &authenticatorImpl{
+		log: log,
+	}
+	a.conf.Store(conf)
+
+	return a
+}
+
+func (a *authenticatorImpl) Reload(conf *config.Auth) {
+	a.conf.Store(conf)
+}
+
+// claims mirrors the access tokens issued by the auth service.
+type claims struct {
+	UserID string `json:"user_id"`
+	jwt.RegisteredClaims
+}
+
+// Authenticate verifies the bearer token in the incoming metadata, either as one of the
+// configured shared secrets or as a JWT signed with the auth service's secret. Calls without
+// a token are identified by their verified TLS client certificate, if any.
+func (a *authenticatorImpl) Authenticate(ctx context.Context) (*Identity, error) {
+	header := utils.GetMetadataValue(ctx, constant.AuthorizationMetadataKey)
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || token == "" {
+		if identity := certificateIdentity(ctx); identity != nil {
+			return identity, nil
+		}
+		return nil, status.Error(codes.Unauthenticated, constant.MissingCredentialsErrorMessage)
+	}
+
+	conf := a.conf.Load()
+	for caller, secret := range conf.SharedSecrets {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1 {
+			return &Identity{Subject: caller, Kind: KindService}, nil
+		}
+	}
+
+	if conf.JWTSecret == "" {
+		return nil, status.Error(codes.Unauthenticated, constant.InvalidTokenErrorMessage)
+	}
+
+	identity, err := parseJWT(conf, token)
+	if err != nil {
+		logger.FromContext(ctx, a.log).Named("Authenticate").Warn("Invalid token", zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, constant.InvalidTokenErrorMessage)
+	}
+
+	return identity, nil
+}
+
+func parseJWT(conf *config.Auth, token string) (*Identity, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "HS384", "HS512"}),
+		jwt.WithExpirationRequired(),
+	}
+	if conf.JWTIssuer != "" {
+		opts = append(opts, jwt.WithIssuer(conf.JWTIssuer))
+	}
+	if conf.JWTAudience != "" {
+		opts = append(opts, jwt.WithAudience(conf.JWTAudience))
+	}
+
+	parsed := &claims{}
+	if _, err := jwt.ParseWithClaims(token, parsed, func(*jwt.Token) (interface{}, error) {
+		return []byte(conf.JWTSecret), nil
+	}, opts...); err != nil {
+		return nil, err
+	}
+
+	subject := parsed.UserID
+	if subject == "" {
+		subject = parsed.Subject
+	}
+	if subject == "" {
+		return nil, jwt.ErrTokenInvalidSubject
+	}
+
+	return &Identity{Subject: subject, Kind: KindUser}, nil
+}
+
+// certificateIdentity returns the subject of the client certificate the TLS handshake verified.
+func certificateIdentity(ctx context.Context) *Identity {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	subject := tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+	if subject == "" {
+		return nil
+	}
+
+	return &Identity{Subject: subject, Kind: KindCertificate}
+}
+
+func (a *authenticatorImpl) isExempt(fullMethod string) bool {
+	for _, method := range a.conf.Load().ExemptMethods {
+		if fullMethod == method || (strings.HasSuffix(method, "/") && strings.HasPrefix(fullMethod, method)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a *authenticatorImpl) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if a.isExempt(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		identity, err := a.Authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(withIdentity(ctx, identity), req)
+	}
+}
+
+func (a *authenticatorImpl) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if a.isExempt(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		identity, err := a.Authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: withIdentity(ss.Context(), identity)})
+	}
+}
+
+// withIdentity stores identity in ctx. For end users the token, not metadata supplied by the
+// caller, decides who the request is made for, so x-user-id is replaced with the token subject.
+func withIdentity(ctx context.Context, identity *Identity) context.Context {
+	logger.AddFields(ctx, zap.String("caller", identity.String()))
+	if identity.Kind == KindUser {
+		md, _ := metadata.FromIncomingContext(ctx)
+		md = md.Copy()
+		md.Set(constant.UserIdMetadataKey, identity.Subject)
+		ctx = metadata.NewIncomingContext(ctx, md)
+	}
+
+	return NewContext(ctx, identity)
+}
+
+// serverStream overrides the context of a grpc.ServerStream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	proto "github.com/isd-sgcu/rpkm67-go-proto/rpkm67/store/object/v1"
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/isd-sgcu/rpkm67-store/constant"
+	"github.com/isd-sgcu/rpkm67-store/internal/catalog"
+	"github.com/isd-sgcu/rpkm67-store/internal/model"
+	"github.com/isd-sgcu/rpkm67-store/internal/utils"
+	"github.com/isd-sgcu/rpkm67-store/logger"
+	adminProto "github.com/isd-sgcu/rpkm67-store/proto/rpkm67/store/admin/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+type Authorizer interface {
+	Authorize(ctx context.Context, op Operation, key string) error
+	UnaryServerInterceptor() grpc.UnaryServerInterceptor
+	// Reload replaces the policy; calls already authorized are unaffected.
+	Reload(policy *Policy)
+}
+
+type authorizerImpl struct {
+	policy      atomic.Pointer[Policy]
+	catalogRepo catalog.Repository
+	conf        *config.Store
+	log         *zap.Logger
+}
+
+func NewAuthorizer(policy *Policy, catalogRepo catalog.Repository, conf *config.Store, log *zap.Logger) Authorizer {
+	a := &authorizerImpl{
+		catalogRepo: catalogRepo,
+		conf:        conf,
+		log:         log,
+	}
+	a.policy.Store(policy)
+
+	return a
+}
+
+func (a *authorizerImpl) Reload(policy *Policy) {
+	a.policy.Store(policy)
+}
+
+// Authorize checks that the caller in ctx may perform op on key, which is the owner for usage.
+// A delete or usage granted only by an owner_only rule additionally requires the object, or the
+// usage, to belong to the x-user-id of the call.
+func (a *authorizerImpl) Authorize(ctx context.Context, op Operation, key string) error {
+	identity, _ := FromContext(ctx)
+	caller := identity.String()
+
+	ownerOnly := false
+	for _, rule := range a.policy.Load().Rules {
+		if !rule.match(caller, op, a.conf.BucketName, key) {
+			continue
+		}
+		if !rule.OwnerOnly || (op != OperationDelete && op != OperationUsage) {
+			return nil
+		}
+		ownerOnly = true
+	}
+
+	if !ownerOnly {
+		logger.FromContext(ctx, a.log).Named("Authorize").Warn("Permission denied", zap.String("caller", caller), zap.String("operation", string(op)), zap.String("key", key))
+		return status.Error(codes.PermissionDenied, constant.PermissionDeniedErrorMessage)
+	}
+
+	if op == OperationUsage {
+		return a.authorizeOwnUsage(ctx, caller, key)
+	}
+	return a.authorizeOwner(ctx, caller, key)
+}
+
+func (a *authorizerImpl) authorizeOwnUsage(ctx context.Context, caller string, owner string) error {
+	if user := utils.GetMetadataValue(ctx, constant.UserIdMetadataKey); user == "" || user != owner {
+		logger.FromContext(ctx, a.log).Named("Authorize").Warn("Usage of another owner", zap.String("caller", caller), zap.String("owner", owner))
+		return status.Error(codes.PermissionDenied, constant.PermissionDeniedErrorMessage)
+	}
+
+	return nil
+}
+
+func (a *authorizerImpl) authorizeOwner(ctx context.Context, caller string, key string) error {
+	owner := utils.GetMetadataValue(ctx, constant.UserIdMetadataKey)
+	if owner == "" {
+		logger.FromContext(ctx, a.log).Named("Authorize").Warn("Delete without owner", zap.String("caller", caller), zap.String("key", key))
+		return status.Error(codes.PermissionDenied, constant.PermissionDeniedErrorMessage)
+	}
+
+	record := &model.Object{}
+	err := a.catalogRepo.FindByKey(a.conf.BucketName, key, record)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.FromContext(ctx, a.log).Named("Authorize").Error("FindByKey: ", zap.Error(err))
+		return status.Error(codes.Internal, constant.InternalServerErrorMessage)
+	}
+	// objects without a catalog entry have no known owner, so nobody owns them
+	if err != nil || record.Owner != owner {
+		logger.FromContext(ctx, a.log).Named("Authorize").Warn("Delete of object not owned by caller", zap.String("caller", caller), zap.String("owner", owner), zap.String("key", key))
+		return status.Error(codes.PermissionDenied, constant.PermissionDeniedErrorMessage)
+	}
+
+	return nil
+}
+
+// UnaryServerInterceptor authorizes ObjectService and AdminService calls; other services pass through.
+// It must run after the authentication interceptor.
+func (a *authorizerImpl) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var err error
+		switch req := req.(type) {
+		case *proto.UploadObjectRequest:
+			err = a.Authorize(ctx, OperationUpload, req.Filename)
+		case *proto.FindByKeyObjectRequest:
+			err = a.Authorize(ctx, OperationFind, req.Key)
+		case *proto.DeleteByKeyObjectRequest:
+			err = a.Authorize(ctx, OperationDelete, req.Key)
+		case *adminProto.SetAliasRequest:
+			err = a.Authorize(ctx, OperationAlias, req.Alias)
+		case *adminProto.RestoreObjectRequest:
+			err = a.Authorize(ctx, OperationRestore, req.Key)
+		case *adminProto.ForceDeleteObjectRequest:
+			err = a.Authorize(ctx, OperationForceDelete, req.Key)
+		case *adminProto.ListVersionsRequest:
+			err = a.Authorize(ctx, OperationListVersions, req.Key)
+		case *adminProto.RestoreVersionRequest:
+			err = a.Authorize(ctx, OperationRestoreVersion, req.Key)
+		case *adminProto.GetUsageRequest:
+			owner := req.Owner
+			if owner == "" {
+				owner = utils.GetMetadataValue(ctx, constant.UserIdMetadataKey)
+			}
+			err = a.Authorize(ctx, OperationUsage, owner)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
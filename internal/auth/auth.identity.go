@@ -0,0 +1,43 @@
+package auth
+
+import "context"
+
+type Kind string
+
+const (
+	// KindService is a backend service authenticated with a shared secret
+	KindService Kind = "service"
+	// KindUser is an end user authenticated with a JWT issued by the auth service
+	KindUser Kind = "user"
+	// KindCertificate is a caller authenticated with a verified TLS client certificate,
+	// identified by the certificate subject's common name
+	KindCertificate Kind = "cert"
+)
+
+// Identity is the authenticated caller of a gRPC request.
+type Identity struct {
+	Subject string
+	Kind    Kind
+}
+
+// String is the caller name matched by policy rules, e.g. "service:gateway".
+func (i *Identity) String() string {
+	if i == nil {
+		return "anonymous"
+	}
+
+	return string(i.Kind) + ":" + i.Subject
+}
+
+type identityKey struct{}
+
+// NewContext returns a copy of ctx carrying identity.
+func NewContext(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext returns the caller identity set by the auth interceptors, if any.
+func FromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Operation string
+
+const (
+	OperationUpload         Operation = "upload"
+	OperationFind           Operation = "find"
+	OperationDelete         Operation = "delete"
+	OperationList           Operation = "list"
+	OperationAlias          Operation = "alias"
+	OperationRestore        Operation = "restore"
+	OperationForceDelete    Operation = "force_delete"
+	OperationListVersions   Operation = "list_versions"
+	OperationRestoreVersion Operation = "restore_version"
+	OperationUsage          Operation = "usage"
+)
+
+// Rule grants the matching callers some operations on keys in the matching buckets.
+type Rule struct {
+	// Callers are patterns on "<kind>:<subject>", e.g. "service:gateway" or "user:*"
+	Callers    []string    `yaml:"callers"`
+	Operations []Operation `yaml:"operations"`
+	// Buckets are patterns on the bucket name; empty matches every bucket
+	Buckets []string `yaml:"buckets"`
+	// Prefixes restrict the keys the rule applies to; empty matches every key
+	Prefixes []string `yaml:"prefixes"`
+	// OwnerOnly restricts delete to objects owned by the end user the call is made for, and usage
+	// to that user's own usage
+	OwnerOnly bool `yaml:"owner_only"`
+}
+
+// Policy is the set of rules loaded from the policy file. Anything not granted by a rule is denied.
+type Policy struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// DefaultPolicy applies when no policy file is set. It lets every caller use ObjectService but
+// grants none of the AdminService operations, which need a policy file to be granted.
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Rules: []Rule{{
+			Callers:    []string{"*"},
+			Operations: []Operation{OperationUpload, OperationFind, OperationDelete, OperationList},
+		}},
+	}
+}
+
+func LoadPolicy(filename string) (*Policy, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{}
+	if err := yaml.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("invalid policy file %v: %w", filename, err)
+	}
+	if err := policy.validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy file %v: %w", filename, err)
+	}
+
+	return policy, nil
+}
+
+func (p *Policy) validate() error {
+	for i, rule := range p.Rules {
+		if len(rule.Callers) == 0 {
+			return fmt.Errorf("rule %d has no callers", i)
+		}
+		for _, op := range rule.Operations {
+			switch op {
+			case OperationUpload, OperationFind, OperationDelete, OperationList, OperationAlias,
+				OperationRestore, OperationForceDelete, OperationListVersions, OperationRestoreVersion, OperationUsage, "*":
+			default:
+				return fmt.Errorf("rule %d has unknown operation %q", i, op)
+			}
+		}
+		for _, pattern := range append(append([]string{}, rule.Callers...), rule.Buckets...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("rule %d has invalid pattern %q", i, pattern)
+			}
+		}
+	}
+
+	return nil
+}
+
+// match reports whether the rule grants op on key in bucket to caller.
+func (r *Rule) match(caller string, op Operation, bucket string, key string) bool {
+	return matchAny(r.Callers, caller) &&
+		r.hasOperation(op) &&
+		(len(r.Buckets) == 0 || matchAny(r.Buckets, bucket)) &&
+		(len(r.Prefixes) == 0 || hasAnyPrefix(r.Prefixes, key))
+}
+
+func (r *Rule) hasOperation(op Operation) bool {
+	for _, allowed := range r.Operations {
+		if allowed == op || allowed == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasAnyPrefix(prefixes []string, key string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
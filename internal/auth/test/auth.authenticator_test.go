@@ -0,0 +1,213 @@
+package test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/isd-sgcu/rpkm67-store/constant"
+	"github.com/isd-sgcu/rpkm67-store/internal/auth"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type AuthenticatorTest struct {
+	suite.Suite
+	conf   *config.Auth
+	logger *zap.Logger
+}
+
+func TestAuthenticator(t *testing.T) {
+	suite.Run(t, new(AuthenticatorTest))
+}
+
+func (t *AuthenticatorTest) SetupTest() {
+	t.logger = zap.NewNop()
+	t.conf = &config.Auth{
+		SharedSecrets: map[string]string{"gateway": "gateway-secret"},
+		JWTSecret:     "jwt-secret",
+		JWTIssuer:     "rpkm67-auth",
+		JWTAudience:   "rpkm67-store",
+		ExemptMethods: []string{"/grpc.health.v1.Health/"},
+	}
+}
+
+func (t *AuthenticatorTest) bearer(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func (t *AuthenticatorTest) signToken(secret string, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	t.Require().Nil(err)
+	return token
+}
+
+func (t *AuthenticatorTest) validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"user_id": "user",
+		"iss":     "rpkm67-auth",
+		"aud":     "rpkm67-store",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func (t *AuthenticatorTest) TestAuthenticateMissingCredentials() {
+	authenticator := auth.NewAuthenticator(t.conf, t.logger)
+
+	expectedErr := status.Error(codes.Unauthenticated, constant.MissingCredentialsErrorMessage).Error()
+
+	actual, err := authenticator.Authenticate(context.Background())
+
+	t.Nil(actual)
+	t.EqualError(err, expectedErr)
+}
+
+func (t *AuthenticatorTest) TestAuthenticateSharedSecret() {
+	authenticator := auth.NewAuthenticator(t.conf, t.logger)
+
+	actual, err := authenticator.Authenticate(t.bearer("gateway-secret"))
+
+	t.Nil(err)
+	t.Equal(&auth.Identity{Subject: "gateway", Kind: auth.KindService}, actual)
+}
+
+func (t *AuthenticatorTest) TestAuthenticateJWT() {
+	authenticator := auth.NewAuthenticator(t.conf, t.logger)
+
+	actual, err := authenticator.Authenticate(t.bearer(t.signToken("jwt-secret", t.validClaims())))
+
+	t.Nil(err)
+	t.Equal(&auth.Identity{Subject: "user", Kind: auth.KindUser}, actual)
+}
+
+func (t *AuthenticatorTest) TestAuthenticateJWTInvalid() {
+	authenticator := auth.NewAuthenticator(t.conf, t.logger)
+
+	wrongIssuer := t.validClaims()
+	wrongIssuer["iss"] = "someone-else"
+	wrongAudience := t.validClaims()
+	wrongAudience["aud"] = "rpkm67-backend"
+	expired := t.validClaims()
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+	noExpiry := t.validClaims()
+	delete(noExpiry, "exp")
+
+	tokens := map[string]string{
+		"wrong secret":   t.signToken("other-secret", t.validClaims()),
+		"wrong issuer":   t.signToken("jwt-secret", wrongIssuer),
+		"wrong audience": t.signToken("jwt-secret", wrongAudience),
+		"expired":        t.signToken("jwt-secret", expired),
+		"no expiry":      t.signToken("jwt-secret", noExpiry),
+		"garbage":        "not-a-token",
+	}
+
+	expectedErr := status.Error(codes.Unauthenticated, constant.InvalidTokenErrorMessage).Error()
+
+	for name, token := range tokens {
+		actual, err := authenticator.Authenticate(t.bearer(token))
+
+		t.Nil(actual, name)
+		t.EqualError(err, expectedErr, name)
+	}
+}
+
+func (t *AuthenticatorTest) TestUnaryInterceptorSetsIdentity() {
+	authenticator := auth.NewAuthenticator(t.conf, t.logger)
+	info := &grpc.UnaryServerInfo{FullMethod: "/rpkm67.store.object.v1.ObjectService/Upload"}
+
+	var identity *auth.Identity
+	_, err := authenticator.UnaryServerInterceptor()(t.bearer("gateway-secret"), nil, info, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		identity, _ = auth.FromContext(ctx)
+		return nil, nil
+	})
+
+	t.Nil(err)
+	t.Equal("gateway", identity.Subject)
+}
+
+func (t *AuthenticatorTest) TestUnaryInterceptorRejectsUnauthenticated() {
+	authenticator := auth.NewAuthenticator(t.conf, t.logger)
+	info := &grpc.UnaryServerInfo{FullMethod: "/rpkm67.store.object.v1.ObjectService/DeleteByKey"}
+
+	_, err := authenticator.UnaryServerInterceptor()(context.Background(), nil, info, func(context.Context, interface{}) (interface{}, error) {
+		t.Fail("handler must not be called")
+		return nil, nil
+	})
+
+	t.Equal(codes.Unauthenticated, status.Code(err))
+}
+
+func (t *AuthenticatorTest) TestUnaryInterceptorExemptMethod() {
+	authenticator := auth.NewAuthenticator(t.conf, t.logger)
+	info := &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}
+
+	called := false
+	_, err := authenticator.UnaryServerInterceptor()(context.Background(), nil, info, func(context.Context, interface{}) (interface{}, error) {
+		called = true
+		return nil, nil
+	})
+
+	t.Nil(err)
+	t.True(called)
+}
+
+func (t *AuthenticatorTest) TestUnaryInterceptorUserOverridesOwner() {
+	authenticator := auth.NewAuthenticator(t.conf, t.logger)
+	info := &grpc.UnaryServerInfo{FullMethod: "/rpkm67.store.object.v1.ObjectService/Upload"}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"authorization", "Bearer "+t.signToken("jwt-secret", t.validClaims()),
+		"x-user-id", "someone-else",
+	))
+
+	var owner []string
+	_, err := authenticator.UnaryServerInterceptor()(ctx, nil, info, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		owner = md.Get("x-user-id")
+		return nil, nil
+	})
+
+	t.Nil(err)
+	t.Equal([]string{"user"}, owner)
+}
+
+func (t *AuthenticatorTest) TestAuthenticateClientCertificate() {
+	authenticator := auth.NewAuthenticator(t.conf, t.logger)
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{
+				VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "checkin"}}}},
+			},
+		},
+	})
+
+	actual, err := authenticator.Authenticate(ctx)
+
+	t.Nil(err)
+	t.Equal(&auth.Identity{Subject: "checkin", Kind: auth.KindCertificate}, actual)
+}
+
+func (t *AuthenticatorTest) TestReloadSharedSecrets() {
+	authenticator := auth.NewAuthenticator(t.conf, t.logger)
+
+	rotated := *t.conf
+	rotated.SharedSecrets = map[string]string{"gateway": "rotated-secret"}
+	authenticator.Reload(&rotated)
+
+	_, err := authenticator.Authenticate(t.bearer("gateway-secret"))
+	t.Equal(codes.Unauthenticated, status.Code(err))
+
+	actual, err := authenticator.Authenticate(t.bearer("rotated-secret"))
+	t.Nil(err)
+	t.Equal(&auth.Identity{Subject: "gateway", Kind: auth.KindService}, actual)
+}
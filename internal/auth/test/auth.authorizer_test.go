@@ -0,0 +1,236 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	proto "github.com/isd-sgcu/rpkm67-go-proto/rpkm67/store/object/v1"
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/isd-sgcu/rpkm67-store/constant"
+	"github.com/isd-sgcu/rpkm67-store/internal/auth"
+	"github.com/isd-sgcu/rpkm67-store/internal/model"
+	mock_catalog "github.com/isd-sgcu/rpkm67-store/mocks/catalog"
+	adminProto "github.com/isd-sgcu/rpkm67-store/proto/rpkm67/store/admin/v1"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+type AuthorizerTest struct {
+	suite.Suite
+	controller *gomock.Controller
+	conf       *config.Store
+	logger     *zap.Logger
+	policy     *auth.Policy
+}
+
+func TestAuthorizer(t *testing.T) {
+	suite.Run(t, new(AuthorizerTest))
+}
+
+func (t *AuthorizerTest) SetupTest() {
+	t.controller = gomock.NewController(t.T())
+	t.logger = zap.NewNop()
+	t.conf = &config.Store{
+		BucketName: "mock-bucket",
+	}
+
+	filename := filepath.Join(t.T().TempDir(), "policy.yaml")
+	t.Require().Nil(os.WriteFile(filename, []byte(`
+rules:
+  - callers: ["service:gateway"]
+    operations: [upload, find]
+  - callers: ["service:gateway"]
+    operations: [delete, usage]
+    owner_only: true
+  - callers: ["service:backend"]
+    operations: ["*"]
+    buckets: ["mock-*"]
+    prefixes: ["backend/"]
+`), 0o600))
+
+	policy, err := auth.LoadPolicy(filename)
+	t.Require().Nil(err)
+	t.policy = policy
+}
+
+func (t *AuthorizerTest) callerContext(subject string, pairs ...string) context.Context {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(pairs...))
+	return auth.NewContext(ctx, &auth.Identity{Subject: subject, Kind: auth.KindService})
+}
+
+func (t *AuthorizerTest) TestLoadPolicyInvalidOperation() {
+	filename := filepath.Join(t.T().TempDir(), "policy.yaml")
+	t.Require().Nil(os.WriteFile(filename, []byte(`
+rules:
+  - callers: ["service:gateway"]
+    operations: [rename]
+`), 0o600))
+
+	policy, err := auth.LoadPolicy(filename)
+
+	t.Nil(policy)
+	t.ErrorContains(err, "unknown operation")
+}
+
+func (t *AuthorizerTest) TestAuthorizeGranted() {
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	authorizer := auth.NewAuthorizer(t.policy, catalogRepo, t.conf, t.logger)
+
+	t.Nil(authorizer.Authorize(t.callerContext("gateway"), auth.OperationUpload, "object.png"))
+	t.Nil(authorizer.Authorize(t.callerContext("backend"), auth.OperationDelete, "backend/object.png"))
+}
+
+func (t *AuthorizerTest) TestAuthorizeDenied() {
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	authorizer := auth.NewAuthorizer(t.policy, catalogRepo, t.conf, t.logger)
+
+	expectedErr := status.Error(codes.PermissionDenied, constant.PermissionDeniedErrorMessage).Error()
+
+	t.EqualError(authorizer.Authorize(t.callerContext("checkin"), auth.OperationFind, "object.png"), expectedErr)
+	t.EqualError(authorizer.Authorize(t.callerContext("gateway"), auth.OperationList, ""), expectedErr)
+	t.EqualError(authorizer.Authorize(t.callerContext("backend"), auth.OperationDelete, "other/object.png"), expectedErr)
+	t.EqualError(authorizer.Authorize(context.Background(), auth.OperationFind, "object.png"), expectedErr)
+}
+
+func (t *AuthorizerTest) TestDefaultPolicyDeniesAdmin() {
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	authorizer := auth.NewAuthorizer(auth.DefaultPolicy(), catalogRepo, t.conf, t.logger)
+	info := &grpc.UnaryServerInfo{}
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	t.Nil(authorizer.Authorize(context.Background(), auth.OperationUpload, "object.png"))
+	t.Nil(authorizer.Authorize(t.callerContext("gateway"), auth.OperationDelete, "object.png"))
+
+	for _, req := range []interface{}{
+		&adminProto.SetAliasRequest{Alias: "avatar", Key: "object.png"},
+		&adminProto.RestoreObjectRequest{Key: "object.png"},
+		&adminProto.ForceDeleteObjectRequest{Key: "object.png"},
+		&adminProto.ListVersionsRequest{Key: "object.png"},
+		&adminProto.RestoreVersionRequest{Key: "object.png", VersionId: "v1"},
+		&adminProto.GetUsageRequest{Owner: "owner"},
+	} {
+		_, err := authorizer.UnaryServerInterceptor()(context.Background(), req, info, handler)
+		t.Equal(codes.PermissionDenied, status.Code(err))
+
+		_, err = authorizer.UnaryServerInterceptor()(t.callerContext("gateway"), req, info, handler)
+		t.Equal(codes.PermissionDenied, status.Code(err))
+	}
+}
+
+func (t *AuthorizerTest) TestReloadPolicy() {
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	authorizer := auth.NewAuthorizer(t.policy, catalogRepo, t.conf, t.logger)
+
+	filename := filepath.Join(t.T().TempDir(), "policy.yaml")
+	t.Require().Nil(os.WriteFile(filename, []byte(`
+rules:
+  - callers: ["service:checkin"]
+    operations: [find]
+`), 0o600))
+	policy, err := auth.LoadPolicy(filename)
+	t.Require().Nil(err)
+	authorizer.Reload(policy)
+
+	t.Nil(authorizer.Authorize(t.callerContext("checkin"), auth.OperationFind, "object.png"))
+	t.Equal(codes.PermissionDenied, status.Code(authorizer.Authorize(t.callerContext("gateway"), auth.OperationFind, "object.png")))
+}
+
+func (t *AuthorizerTest) TestAuthorizeOwnerOnlyDelete() {
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, "object.png", gomock.Any()).DoAndReturn(func(_, _ string, record *model.Object) error {
+		*record = model.Object{Key: "object.png", Owner: "owner"}
+		return nil
+	}).Times(2)
+	authorizer := auth.NewAuthorizer(t.policy, catalogRepo, t.conf, t.logger)
+
+	expectedErr := status.Error(codes.PermissionDenied, constant.PermissionDeniedErrorMessage).Error()
+
+	t.Nil(authorizer.Authorize(t.callerContext("gateway", "x-user-id", "owner"), auth.OperationDelete, "object.png"))
+	t.EqualError(authorizer.Authorize(t.callerContext("gateway", "x-user-id", "someone-else"), auth.OperationDelete, "object.png"), expectedErr)
+	t.EqualError(authorizer.Authorize(t.callerContext("gateway"), auth.OperationDelete, "object.png"), expectedErr)
+}
+
+func (t *AuthorizerTest) TestAuthorizeOwnerOnlyDeleteNotCataloged() {
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, "legacy.png", gomock.Any()).Return(gorm.ErrRecordNotFound)
+	authorizer := auth.NewAuthorizer(t.policy, catalogRepo, t.conf, t.logger)
+
+	expectedErr := status.Error(codes.PermissionDenied, constant.PermissionDeniedErrorMessage).Error()
+
+	t.EqualError(authorizer.Authorize(t.callerContext("gateway", "x-user-id", "owner"), auth.OperationDelete, "legacy.png"), expectedErr)
+}
+
+func (t *AuthorizerTest) TestAuthorizeOwnerOnlyDeleteInternalError() {
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, "object.png", gomock.Any()).Return(fmt.Errorf("error"))
+	authorizer := auth.NewAuthorizer(t.policy, catalogRepo, t.conf, t.logger)
+
+	expectedErr := status.Error(codes.Internal, constant.InternalServerErrorMessage).Error()
+
+	t.EqualError(authorizer.Authorize(t.callerContext("gateway", "x-user-id", "owner"), auth.OperationDelete, "object.png"), expectedErr)
+}
+
+func (t *AuthorizerTest) TestUnaryInterceptorMapsRequests() {
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	authorizer := auth.NewAuthorizer(t.policy, catalogRepo, t.conf, t.logger)
+	info := &grpc.UnaryServerInfo{}
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	actual, err := authorizer.UnaryServerInterceptor()(t.callerContext("gateway"), &proto.FindByKeyObjectRequest{Key: "object.png"}, info, handler)
+	t.Nil(err)
+	t.Equal("ok", actual)
+
+	_, err = authorizer.UnaryServerInterceptor()(t.callerContext("checkin"), &proto.UploadObjectRequest{Filename: "object.png"}, info, handler)
+	t.Equal(codes.PermissionDenied, status.Code(err))
+}
+
+func (t *AuthorizerTest) TestUnaryInterceptorMapsAdminRequests() {
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	authorizer := auth.NewAuthorizer(t.policy, catalogRepo, t.conf, t.logger)
+	info := &grpc.UnaryServerInfo{}
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	actual, err := authorizer.UnaryServerInterceptor()(t.callerContext("backend"), &adminProto.SetAliasRequest{Alias: "backend/avatar", Key: "object.png"}, info, handler)
+	t.Nil(err)
+	t.Equal("ok", actual)
+
+	_, err = authorizer.UnaryServerInterceptor()(t.callerContext("gateway"), &adminProto.SetAliasRequest{Alias: "backend/avatar", Key: "object.png"}, info, handler)
+	t.Equal(codes.PermissionDenied, status.Code(err))
+
+	_, err = authorizer.UnaryServerInterceptor()(t.callerContext("gateway"), &adminProto.ForceDeleteObjectRequest{Key: "gateway/object.png"}, info, handler)
+	t.Equal(codes.PermissionDenied, status.Code(err))
+
+	_, err = authorizer.UnaryServerInterceptor()(t.callerContext("gateway"), &adminProto.RestoreVersionRequest{Key: "gateway/object.png", VersionId: "v1"}, info, handler)
+	t.Equal(codes.PermissionDenied, status.Code(err))
+}
+
+func (t *AuthorizerTest) TestUnaryInterceptorUsageOwnerOnly() {
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	authorizer := auth.NewAuthorizer(t.policy, catalogRepo, t.conf, t.logger)
+	info := &grpc.UnaryServerInfo{}
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	actual, err := authorizer.UnaryServerInterceptor()(t.callerContext("gateway", "x-user-id", "user"), &adminProto.GetUsageRequest{}, info, handler)
+	t.Nil(err)
+	t.Equal("ok", actual)
+
+	_, err = authorizer.UnaryServerInterceptor()(t.callerContext("gateway", "x-user-id", "user"), &adminProto.GetUsageRequest{Owner: "other"}, info, handler)
+	t.Equal(codes.PermissionDenied, status.Code(err))
+}
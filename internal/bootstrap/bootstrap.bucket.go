@@ -0,0 +1,334 @@
+package bootstrap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/isd-sgcu/rpkm67-store/internal/client/bucket"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+	"go.uber.org/zap"
+)
+
+// publicReadPolicy lets anyone download objects, which the unsigned URLs returned to clients rely on.
+const publicReadPolicy = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetObject"],"Resource":["arn:aws:s3:::%s/*"]}]}`
+
+type Bootstrapper interface {
+	// Run checks that the bucket is reachable and usable with the configured settings, setting
+	// it up first when bootstrapping is allowed. The returned error says what is wrong.
+	Run(ctx context.Context) error
+}
+
+type bootstrapperImpl struct {
+	client bucket.Client
+	conf   *config.Store
+	log    *zap.Logger
+}
+
+func NewBootstrapper(client bucket.Client, conf *config.Store, log *zap.Logger) Bootstrapper {
+	return &bootstrapperImpl{
+		client: client,
+		conf:   conf,
+		log:    log,
+	}
+}
+
+func (b *bootstrapperImpl) Run(ctx context.Context) error {
+	exists, err := b.client.BucketExists(ctx, b.conf.BucketName)
+	if err != nil {
+		return fmt.Errorf("cannot reach storage backend at %v, check STORE_ENDPOINT and the credentials: %w", b.conf.Endpoint, err)
+	}
+
+	if !b.conf.Bootstrap {
+		if err := b.verify(ctx, exists); err != nil {
+			return err
+		}
+		return b.prepareTrash(ctx)
+	}
+
+	if !exists {
+		if err := b.createBucket(ctx); err != nil {
+			return err
+		}
+	}
+	if err := b.applyPolicy(ctx); err != nil {
+		return err
+	}
+	if err := b.applyVersioning(ctx); err != nil {
+		return err
+	}
+	if err := b.applyLifecycle(ctx); err != nil {
+		return err
+	}
+
+	if err := b.verifyObjectLock(ctx); err != nil {
+		return err
+	}
+
+	return b.prepareTrash(ctx)
+}
+
+// verify fails on settings that can't be honoured by the bucket as it is.
+func (b *bootstrapperImpl) verify(ctx context.Context, exists bool) error {
+	if !exists {
+		return fmt.Errorf("bucket %q does not exist, create it or set STORE_BUCKET_BOOTSTRAP=true", b.conf.BucketName)
+	}
+
+	if b.conf.Versioning != "" {
+		versioning, err := b.client.GetBucketVersioning(ctx, b.conf.BucketName)
+		if err != nil {
+			return fmt.Errorf("cannot read versioning of bucket %q: %w", b.conf.BucketName, err)
+		}
+		if (b.conf.Versioning == "enabled") != versioning.Enabled() {
+			return fmt.Errorf("bucket %q versioning is %q but STORE_BUCKET_VERSIONING is %q", b.conf.BucketName, versioning.Status, b.conf.Versioning)
+		}
+	}
+
+	if err := b.verifyPolicy(ctx); err != nil {
+		return err
+	}
+	if err := b.verifyLifecycle(ctx); err != nil {
+		return err
+	}
+
+	return b.verifyObjectLock(ctx)
+}
+
+// verifyPolicy checks that the bucket is public or private as STORE_BUCKET_POLICY says.
+func (b *bootstrapperImpl) verifyPolicy(ctx context.Context) error {
+	if b.conf.BucketPolicy == "" {
+		return nil
+	}
+
+	policy, err := b.client.GetBucketPolicy(ctx, b.conf.BucketName)
+	if err != nil {
+		return fmt.Errorf("cannot read policy of bucket %q: %w", b.conf.BucketName, err)
+	}
+	public, err := allowsPublicRead(policy, b.conf.BucketName)
+	if err != nil {
+		return fmt.Errorf("cannot parse policy of bucket %q: %w", b.conf.BucketName, err)
+	}
+	if public != (b.conf.BucketPolicy == "public-read") {
+		return fmt.Errorf("bucket %q policy doesn't match STORE_BUCKET_POLICY %q, fix it or set STORE_BUCKET_BOOTSTRAP=true", b.conf.BucketName, b.conf.BucketPolicy)
+	}
+
+	return nil
+}
+
+// verifyLifecycle checks that the bucket has an enabled rule for every configured lifecycle setting.
+func (b *bootstrapperImpl) verifyLifecycle(ctx context.Context) error {
+	wanted := b.lifecycleRules()
+	if wanted.Empty() {
+		return nil
+	}
+
+	actual, err := b.client.GetBucketLifecycle(ctx, b.conf.BucketName)
+	if minio.ToErrorResponse(err).Code == "NoSuchLifecycleConfiguration" {
+		actual, err = lifecycle.NewConfiguration(), nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read lifecycle of bucket %q: %w", b.conf.BucketName, err)
+	}
+
+	for _, rule := range wanted.Rules {
+		if !hasLifecycleRule(actual, rule) {
+			return fmt.Errorf("bucket %q has no lifecycle rule matching %v, fix it or set STORE_BUCKET_BOOTSTRAP=true", b.conf.BucketName, rule.ID)
+		}
+	}
+
+	return nil
+}
+
+// hasLifecycleRule reports whether config has an enabled rule doing what wanted does, whatever its ID.
+func hasLifecycleRule(config *lifecycle.Configuration, wanted lifecycle.Rule) bool {
+	for _, rule := range config.Rules {
+		if rule.Status == "Enabled" &&
+			rule.NoncurrentVersionExpiration.NoncurrentDays == wanted.NoncurrentVersionExpiration.NoncurrentDays &&
+			rule.AbortIncompleteMultipartUpload.DaysAfterInitiation == wanted.AbortIncompleteMultipartUpload.DaysAfterInitiation {
+			return true
+		}
+	}
+
+	return false
+}
+
+// verifyObjectLock checks that retention policies can be applied; object lock can only be
+// turned on when a bucket is created.
+func (b *bootstrapperImpl) verifyObjectLock(ctx context.Context) error {
+	if len(b.conf.Retention) == 0 {
+		return nil
+	}
+
+	objectLock, _, _, _, err := b.client.GetObjectLockConfig(ctx, b.conf.BucketName)
+	if err != nil || objectLock != "Enabled" {
+		return fmt.Errorf("bucket %q needs object lock for STORE_RETENTION_POLICIES, recreate it with object lock enabled", b.conf.BucketName)
+	}
+
+	return nil
+}
+
+// prepareTrash checks that the trash bucket exists, creating it when bootstrapping is allowed.
+// It never gets a policy, so unlike the main bucket it stays private.
+func (b *bootstrapperImpl) prepareTrash(ctx context.Context) error {
+	if b.conf.HardDelete {
+		return nil
+	}
+
+	exists, err := b.client.BucketExists(ctx, b.conf.TrashBucketName)
+	if err != nil {
+		return fmt.Errorf("cannot check trash bucket %q: %w", b.conf.TrashBucketName, err)
+	}
+	if exists {
+		return nil
+	}
+	if !b.conf.Bootstrap {
+		return fmt.Errorf("trash bucket %q does not exist, create it, set STORE_BUCKET_BOOTSTRAP=true or STORE_HARD_DELETE=true", b.conf.TrashBucketName)
+	}
+
+	if err := b.client.MakeBucket(ctx, b.conf.TrashBucketName, minio.MakeBucketOptions{Region: b.conf.Region}); err != nil {
+		return fmt.Errorf("cannot create trash bucket %q: %w", b.conf.TrashBucketName, err)
+	}
+	b.log.Named("Run").Info("Created trash bucket", zap.String("bucket", b.conf.TrashBucketName))
+
+	return nil
+}
+
+func (b *bootstrapperImpl) createBucket(ctx context.Context) error {
+	err := b.client.MakeBucket(ctx, b.conf.BucketName, minio.MakeBucketOptions{
+		Region:        b.conf.Region,
+		ObjectLocking: len(b.conf.Retention) > 0,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot create bucket %q: %w", b.conf.BucketName, err)
+	}
+	b.log.Named("Run").Info("Created bucket", zap.String("bucket", b.conf.BucketName))
+
+	return nil
+}
+
+func (b *bootstrapperImpl) applyPolicy(ctx context.Context) error {
+	var policy string
+	switch b.conf.BucketPolicy {
+	case "":
+		return nil
+	case "public-read":
+		policy = fmt.Sprintf(publicReadPolicy, b.conf.BucketName)
+	}
+
+	// an empty policy removes the bucket's policy, leaving it private
+	if err := b.client.SetBucketPolicy(ctx, b.conf.BucketName, policy); err != nil {
+		return fmt.Errorf("cannot set %v policy on bucket %q: %w", b.conf.BucketPolicy, b.conf.BucketName, err)
+	}
+
+	return nil
+}
+
+func (b *bootstrapperImpl) applyVersioning(ctx context.Context) error {
+	var status string
+	switch b.conf.Versioning {
+	case "":
+		return nil
+	case "enabled":
+		status = minio.Enabled
+	case "suspended":
+		status = minio.Suspended
+	}
+
+	if err := b.client.SetBucketVersioning(ctx, b.conf.BucketName, minio.BucketVersioningConfiguration{Status: status}); err != nil {
+		return fmt.Errorf("cannot set versioning of bucket %q: %w", b.conf.BucketName, err)
+	}
+
+	return nil
+}
+
+// applyLifecycle replaces the bucket's lifecycle rules with the configured ones, if there are any.
+func (b *bootstrapperImpl) applyLifecycle(ctx context.Context) error {
+	rules := b.lifecycleRules()
+	if rules.Empty() {
+		return nil
+	}
+
+	if err := b.client.SetBucketLifecycle(ctx, b.conf.BucketName, rules); err != nil {
+		return fmt.Errorf("cannot set lifecycle of bucket %q: %w", b.conf.BucketName, err)
+	}
+
+	return nil
+}
+
+// lifecycleRules builds the lifecycle rules the settings ask for; it is empty when none are set.
+func (b *bootstrapperImpl) lifecycleRules() *lifecycle.Configuration {
+	rules := lifecycle.NewConfiguration()
+	if b.conf.NoncurrentVersionExpiryDays > 0 {
+		rules.Rules = append(rules.Rules, lifecycle.Rule{
+			ID:     "expire-noncurrent-versions",
+			Status: "Enabled",
+			NoncurrentVersionExpiration: lifecycle.NoncurrentVersionExpiration{
+				NoncurrentDays: lifecycle.ExpirationDays(b.conf.NoncurrentVersionExpiryDays),
+			},
+		})
+	}
+	if b.conf.AbortIncompleteUploadDays > 0 {
+		rules.Rules = append(rules.Rules, lifecycle.Rule{
+			ID:     "abort-incomplete-uploads",
+			Status: "Enabled",
+			AbortIncompleteMultipartUpload: lifecycle.AbortIncompleteMultipartUpload{
+				DaysAfterInitiation: lifecycle.ExpirationDays(b.conf.AbortIncompleteUploadDays),
+			},
+		})
+	}
+
+	return rules
+}
+
+// policyDocument is the part of an S3 bucket policy needed to tell whether it allows public reads.
+// Principal, Action and Resource may each be a single string or a list.
+type policyDocument struct {
+	Statement []struct {
+		Effect    string          `json:"Effect"`
+		Principal json.RawMessage `json:"Principal"`
+		Action    stringList      `json:"Action"`
+		Resource  stringList      `json:"Resource"`
+	} `json:"Statement"`
+}
+
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = stringList{single}
+		return nil
+	}
+
+	return json.Unmarshal(data, (*[]string)(l))
+}
+
+// allowsPublicRead reports whether policy lets anyone get the objects of bucket. An empty policy is private.
+func allowsPublicRead(policy string, bucket string) (bool, error) {
+	if policy == "" {
+		return false, nil
+	}
+
+	document := policyDocument{}
+	if err := json.Unmarshal([]byte(policy), &document); err != nil {
+		return false, err
+	}
+
+	for _, statement := range document.Statement {
+		var principal struct {
+			AWS stringList `json:"AWS"`
+		}
+		anyone := string(statement.Principal) == `"*"` ||
+			(json.Unmarshal(statement.Principal, &principal) == nil && slices.Contains(principal.AWS, "*"))
+		if statement.Effect == "Allow" && anyone &&
+			(slices.Contains(statement.Action, "s3:GetObject") || slices.Contains(statement.Action, "s3:*")) &&
+			slices.Contains(statement.Resource, "arn:aws:s3:::"+bucket+"/*") {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
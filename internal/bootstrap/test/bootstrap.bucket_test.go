@@ -0,0 +1,210 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/isd-sgcu/rpkm67-store/internal/bootstrap"
+	mock_bucket "github.com/isd-sgcu/rpkm67-store/mocks/client/bucket"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type BootstrapTest struct {
+	suite.Suite
+	controller *gomock.Controller
+	conf       *config.Store
+}
+
+func TestBootstrap(t *testing.T) {
+	suite.Run(t, new(BootstrapTest))
+}
+
+func (t *BootstrapTest) SetupTest() {
+	t.controller = gomock.NewController(t.T())
+	t.conf = &config.Store{
+		BucketName:      "mock-bucket",
+		TrashBucketName: "mock-bucket-trash",
+		Endpoint:        "mock-endpoint",
+	}
+}
+
+func (t *BootstrapTest) run(client *mock_bucket.MockClient) error {
+	return bootstrap.NewBootstrapper(client, t.conf, zap.NewNop()).Run(context.Background())
+}
+
+func (t *BootstrapTest) TestUnreachableError() {
+	client := mock_bucket.NewMockClient(t.controller)
+	client.EXPECT().BucketExists(gomock.Any(), t.conf.BucketName).Return(false, fmt.Errorf("error"))
+
+	err := t.run(client)
+
+	t.ErrorContains(err, "cannot reach storage backend")
+}
+
+func (t *BootstrapTest) TestMissingBucketWithoutBootstrapError() {
+	client := mock_bucket.NewMockClient(t.controller)
+	client.EXPECT().BucketExists(gomock.Any(), t.conf.BucketName).Return(false, nil)
+
+	err := t.run(client)
+
+	t.ErrorContains(err, "STORE_BUCKET_BOOTSTRAP")
+}
+
+func (t *BootstrapTest) TestVerifySuccess() {
+	t.conf.Versioning = "enabled"
+	client := mock_bucket.NewMockClient(t.controller)
+	client.EXPECT().BucketExists(gomock.Any(), t.conf.BucketName).Return(true, nil)
+	client.EXPECT().GetBucketVersioning(gomock.Any(), t.conf.BucketName).Return(minio.BucketVersioningConfiguration{Status: minio.Enabled}, nil)
+	client.EXPECT().BucketExists(gomock.Any(), t.conf.TrashBucketName).Return(true, nil)
+
+	err := t.run(client)
+
+	t.Nil(err)
+}
+
+func (t *BootstrapTest) TestVerifyVersioningMismatchError() {
+	t.conf.Versioning = "enabled"
+	client := mock_bucket.NewMockClient(t.controller)
+	client.EXPECT().BucketExists(gomock.Any(), t.conf.BucketName).Return(true, nil)
+	client.EXPECT().GetBucketVersioning(gomock.Any(), t.conf.BucketName).Return(minio.BucketVersioningConfiguration{}, nil)
+
+	err := t.run(client)
+
+	t.ErrorContains(err, "STORE_BUCKET_VERSIONING")
+}
+
+func (t *BootstrapTest) TestVerifyPolicyAndLifecycleSuccess() {
+	t.conf.BucketPolicy = "public-read"
+	t.conf.AbortIncompleteUploadDays = 1
+	client := mock_bucket.NewMockClient(t.controller)
+	client.EXPECT().BucketExists(gomock.Any(), t.conf.BucketName).Return(true, nil)
+	client.EXPECT().GetBucketPolicy(gomock.Any(), t.conf.BucketName).Return(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::mock-bucket/*"}]}`, nil)
+	client.EXPECT().GetBucketLifecycle(gomock.Any(), t.conf.BucketName).Return(&lifecycle.Configuration{Rules: []lifecycle.Rule{{
+		ID:     "cleanup",
+		Status: "Enabled",
+		AbortIncompleteMultipartUpload: lifecycle.AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: 1,
+		},
+	}}}, nil)
+	client.EXPECT().BucketExists(gomock.Any(), t.conf.TrashBucketName).Return(true, nil)
+
+	err := t.run(client)
+
+	t.Nil(err)
+}
+
+func (t *BootstrapTest) TestVerifyPolicyMismatchError() {
+	t.conf.BucketPolicy = "private"
+	client := mock_bucket.NewMockClient(t.controller)
+	client.EXPECT().BucketExists(gomock.Any(), t.conf.BucketName).Return(true, nil)
+	client.EXPECT().GetBucketPolicy(gomock.Any(), t.conf.BucketName).Return(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetObject"],"Resource":["arn:aws:s3:::mock-bucket/*"]}]}`, nil)
+
+	err := t.run(client)
+
+	t.ErrorContains(err, "STORE_BUCKET_POLICY")
+}
+
+func (t *BootstrapTest) TestVerifyLifecycleMissingError() {
+	t.conf.NoncurrentVersionExpiryDays = 30
+	client := mock_bucket.NewMockClient(t.controller)
+	client.EXPECT().BucketExists(gomock.Any(), t.conf.BucketName).Return(true, nil)
+	client.EXPECT().GetBucketLifecycle(gomock.Any(), t.conf.BucketName).Return(nil, minio.ErrorResponse{Code: "NoSuchLifecycleConfiguration"})
+
+	err := t.run(client)
+
+	t.ErrorContains(err, "expire-noncurrent-versions")
+}
+
+func (t *BootstrapTest) TestVerifyObjectLockMissingError() {
+	t.conf.Retention = map[string]config.RetentionPolicy{
+		"evidence": {Mode: "COMPLIANCE", Period: time.Hour},
+	}
+	client := mock_bucket.NewMockClient(t.controller)
+	client.EXPECT().BucketExists(gomock.Any(), t.conf.BucketName).Return(true, nil)
+	client.EXPECT().GetObjectLockConfig(gomock.Any(), t.conf.BucketName).Return("", nil, nil, nil, fmt.Errorf("no object lock"))
+
+	err := t.run(client)
+
+	t.ErrorContains(err, "object lock")
+}
+
+func (t *BootstrapTest) TestBootstrapCreatesAndConfiguresBucket() {
+	t.conf.Bootstrap = true
+	t.conf.BucketPolicy = "public-read"
+	t.conf.Versioning = "enabled"
+	t.conf.AbortIncompleteUploadDays = 1
+	t.conf.Retention = map[string]config.RetentionPolicy{
+		"evidence": {Mode: "COMPLIANCE", Period: time.Hour},
+	}
+	client := mock_bucket.NewMockClient(t.controller)
+	client.EXPECT().BucketExists(gomock.Any(), t.conf.BucketName).Return(false, nil)
+	client.EXPECT().MakeBucket(gomock.Any(), t.conf.BucketName, minio.MakeBucketOptions{ObjectLocking: true}).Return(nil)
+	client.EXPECT().SetBucketPolicy(gomock.Any(), t.conf.BucketName, gomock.Any()).DoAndReturn(func(_ context.Context, _ string, policy string) error {
+		t.Contains(policy, "arn:aws:s3:::mock-bucket/*")
+		return nil
+	})
+	client.EXPECT().SetBucketVersioning(gomock.Any(), t.conf.BucketName, minio.BucketVersioningConfiguration{Status: minio.Enabled}).Return(nil)
+	client.EXPECT().SetBucketLifecycle(gomock.Any(), t.conf.BucketName, gomock.Any()).DoAndReturn(func(_ context.Context, _ string, rules *lifecycle.Configuration) error {
+		t.Require().Len(rules.Rules, 1)
+		t.Equal(lifecycle.ExpirationDays(1), rules.Rules[0].AbortIncompleteMultipartUpload.DaysAfterInitiation)
+		return nil
+	})
+	client.EXPECT().GetObjectLockConfig(gomock.Any(), t.conf.BucketName).Return("Enabled", nil, nil, nil, nil)
+	client.EXPECT().BucketExists(gomock.Any(), t.conf.TrashBucketName).Return(false, nil)
+	client.EXPECT().MakeBucket(gomock.Any(), t.conf.TrashBucketName, minio.MakeBucketOptions{}).Return(nil)
+
+	err := t.run(client)
+
+	t.Nil(err)
+}
+
+func (t *BootstrapTest) TestBootstrapPrivateRemovesPolicy() {
+	t.conf.Bootstrap = true
+	t.conf.BucketPolicy = "private"
+	client := mock_bucket.NewMockClient(t.controller)
+	client.EXPECT().BucketExists(gomock.Any(), t.conf.BucketName).Return(true, nil)
+	client.EXPECT().SetBucketPolicy(gomock.Any(), t.conf.BucketName, "").Return(nil)
+	client.EXPECT().BucketExists(gomock.Any(), t.conf.TrashBucketName).Return(true, nil)
+
+	err := t.run(client)
+
+	t.Nil(err)
+}
+
+func (t *BootstrapTest) TestBootstrapCreateError() {
+	t.conf.Bootstrap = true
+	client := mock_bucket.NewMockClient(t.controller)
+	client.EXPECT().BucketExists(gomock.Any(), t.conf.BucketName).Return(false, nil)
+	client.EXPECT().MakeBucket(gomock.Any(), t.conf.BucketName, gomock.Any()).Return(fmt.Errorf("error"))
+
+	err := t.run(client)
+
+	t.ErrorContains(err, "cannot create bucket")
+}
+
+func (t *BootstrapTest) TestMissingTrashBucketWithoutBootstrapError() {
+	client := mock_bucket.NewMockClient(t.controller)
+	client.EXPECT().BucketExists(gomock.Any(), t.conf.BucketName).Return(true, nil)
+	client.EXPECT().BucketExists(gomock.Any(), t.conf.TrashBucketName).Return(false, nil)
+
+	err := t.run(client)
+
+	t.ErrorContains(err, "trash bucket")
+}
+
+func (t *BootstrapTest) TestHardDeleteSkipsTrashBucket() {
+	t.conf.HardDelete = true
+	client := mock_bucket.NewMockClient(t.controller)
+	client.EXPECT().BucketExists(gomock.Any(), t.conf.BucketName).Return(true, nil)
+
+	err := t.run(client)
+
+	t.Nil(err)
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrCacheMiss is returned by GetValue when the key is absent or expired.
+var ErrCacheMiss = errors.New("cache miss")
+
+type Repository interface {
+	SetValue(key string, value interface{}, ttl time.Duration) error
+	GetValue(key string, value interface{}) error
+	DeleteValue(key string) error
+}
+
+type redisRepositoryImpl struct {
+	client *redis.Client
+}
+
+func NewRedisRepository(client *redis.Client) Repository {
+	return &redisRepositoryImpl{
+		client: client,
+	}
+}
+
+func (r *redisRepositoryImpl) SetValue(key string, value interface{}, ttl time.Duration) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Set(context.Background(), key, raw, ttl).Err()
+}
+
+func (r *redisRepositoryImpl) GetValue(key string, value interface{}) error {
+	raw, err := r.client.Get(context.Background(), key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return ErrCacheMiss
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, value)
+}
+
+func (r *redisRepositoryImpl) DeleteValue(key string) error {
+	return r.client.Del(context.Background(), key).Err()
+}
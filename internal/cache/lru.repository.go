@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"container/list"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// lruRepositoryImpl is an in-process Repository used when Redis is not configured.
+// Values are stored JSON-encoded so callers observe the same copy semantics as with Redis.
+type lruRepositoryImpl struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func NewLRURepository(size int) Repository {
+	return &lruRepositoryImpl{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (r *lruRepositoryImpl) SetValue(key string, value interface{}, ttl time.Duration) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry := &lruEntry{key: key, value: raw, expiresAt: time.Now().Add(ttl)}
+	if elem, ok := r.entries[key]; ok {
+		elem.Value = entry
+		r.order.MoveToFront(elem)
+		return nil
+	}
+
+	r.entries[key] = r.order.PushFront(entry)
+	for r.size > 0 && r.order.Len() > r.size {
+		r.removeElement(r.order.Back())
+	}
+
+	return nil
+}
+
+func (r *lruRepositoryImpl) GetValue(key string, value interface{}) error {
+	r.mu.Lock()
+	elem, ok := r.entries[key]
+	if !ok {
+		r.mu.Unlock()
+		return ErrCacheMiss
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if !time.Now().Before(entry.expiresAt) {
+		r.removeElement(elem)
+		r.mu.Unlock()
+		return ErrCacheMiss
+	}
+	r.order.MoveToFront(elem)
+	r.mu.Unlock()
+
+	return json.Unmarshal(entry.value, value)
+}
+
+func (r *lruRepositoryImpl) DeleteValue(key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if elem, ok := r.entries[key]; ok {
+		r.removeElement(elem)
+	}
+
+	return nil
+}
+
+func (r *lruRepositoryImpl) removeElement(elem *list.Element) {
+	r.order.Remove(elem)
+	delete(r.entries, elem.Value.(*lruEntry).key)
+}
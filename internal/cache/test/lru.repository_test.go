@@ -0,0 +1,71 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/isd-sgcu/rpkm67-store/internal/cache"
+	"github.com/stretchr/testify/suite"
+)
+
+type LRURepositoryTest struct {
+	suite.Suite
+}
+
+func TestLRURepository(t *testing.T) {
+	suite.Run(t, new(LRURepositoryTest))
+}
+
+func (t *LRURepositoryTest) TestGetValueSuccess() {
+	repo := cache.NewLRURepository(10)
+	t.Nil(repo.SetValue("key", "value", time.Minute))
+
+	var value string
+	err := repo.GetValue("key", &value)
+	t.Nil(err)
+	t.Equal("value", value)
+}
+
+func (t *LRURepositoryTest) TestGetValueMiss() {
+	repo := cache.NewLRURepository(10)
+
+	var value string
+	err := repo.GetValue("key", &value)
+	t.ErrorIs(err, cache.ErrCacheMiss)
+}
+
+func (t *LRURepositoryTest) TestGetValueExpired() {
+	repo := cache.NewLRURepository(10)
+	t.Nil(repo.SetValue("key", "value", time.Millisecond))
+
+	time.Sleep(5 * time.Millisecond)
+
+	var value string
+	err := repo.GetValue("key", &value)
+	t.ErrorIs(err, cache.ErrCacheMiss)
+}
+
+func (t *LRURepositoryTest) TestEvictsLeastRecentlyUsed() {
+	repo := cache.NewLRURepository(2)
+	t.Nil(repo.SetValue("a", 1, time.Minute))
+	t.Nil(repo.SetValue("b", 2, time.Minute))
+
+	var value int
+	t.Nil(repo.GetValue("a", &value))
+	t.Nil(repo.SetValue("c", 3, time.Minute))
+
+	t.ErrorIs(repo.GetValue("b", &value), cache.ErrCacheMiss)
+	t.Nil(repo.GetValue("a", &value))
+	t.Equal(1, value)
+	t.Nil(repo.GetValue("c", &value))
+	t.Equal(3, value)
+}
+
+func (t *LRURepositoryTest) TestDeleteValue() {
+	repo := cache.NewLRURepository(10)
+	t.Nil(repo.SetValue("key", "value", time.Minute))
+	t.Nil(repo.DeleteValue("key"))
+
+	var value string
+	t.ErrorIs(repo.GetValue("key", &value), cache.ErrCacheMiss)
+}
@@ -0,0 +1,228 @@
+package catalog
+
+import (
+	"strings"
+	"time"
+
+	"github.com/isd-sgcu/rpkm67-store/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	Create(object *model.Object) error
+	Backfill(object *model.Object) (created bool, err error)
+	CompleteBackfill(bucketName string) error
+	IsBackfilled(bucketName string) (bool, error)
+	Commit(bucketName string, objectKey string) error
+	FindByKey(bucketName string, objectKey string, object *model.Object) error
+	List(bucketName string, prefix string, limit int, objects *[]*model.Object) error
+	Delete(bucketName string, objectKey string) error
+	Restore(bucketName string, objectKey string) error
+	Purge(bucketName string, objectKey string) error
+	Resize(bucketName string, objectKey string, size int64) error
+	IncrementUsage(owner string, category string, bytes int64, objects int64, maxBytes int64, maxObjects int64) (ok bool, err error)
+	FindUsage(owner string, usages *[]*model.Usage) error
+	SumByCategory(bucketName string, usages *[]*model.Usage) error
+	ReserveIdempotencyKey(key *model.IdempotencyKey) (reserved bool, err error)
+	FindIdempotencyKey(owner string, key string, record *model.IdempotencyKey) error
+	CompleteIdempotencyKey(key *model.IdempotencyKey) error
+	ReleaseIdempotencyKey(owner string, key string) error
+	WithTransaction(txFunc func(Repository) error) error
+	TryLock(name string, fn func() error) (locked bool, err error)
+}
+
+type repositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repositoryImpl{
+		db: db,
+	}
+}
+
+func (r *repositoryImpl) Create(object *model.Object) error {
+	return r.db.Create(object).Error
+}
+
+// Backfill adds an entry for an object stored before the catalog existed, unless the catalog
+// has an entry for the key already, deleted or not, in which case created is false.
+func (r *repositoryImpl) Backfill(object *model.Object) (created bool, err error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(object)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// CompleteBackfill records that every object of the bucket has a catalog entry.
+func (r *repositoryImpl) CompleteBackfill(bucketName string) error {
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(&model.Backfill{Bucket: bucketName, CompletedAt: time.Now()}).Error
+}
+
+func (r *repositoryImpl) IsBackfilled(bucketName string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.Backfill{}).Where("bucket = ?", bucketName).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Commit marks a pending entry as stored, making it visible to the other queries.
+func (r *repositoryImpl) Commit(bucketName string, objectKey string) error {
+	return r.db.Model(&model.Object{}).
+		Where("bucket = ? AND key = ?", bucketName, objectKey).
+		Update("pending", false).Error
+}
+
+func (r *repositoryImpl) FindByKey(bucketName string, objectKey string, object *model.Object) error {
+	return r.db.Where("bucket = ? AND key = ? AND NOT pending", bucketName, objectKey).First(object).Error
+}
+
+func (r *repositoryImpl) List(bucketName string, prefix string, limit int, objects *[]*model.Object) error {
+	query := r.db.Where("bucket = ? AND key LIKE ? AND NOT pending", bucketName, escapeLike(prefix)+"%").Order("key")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	return query.Find(objects).Error
+}
+
+// Delete marks the entry as deleted by setting deleted_at; it is excluded from every other query afterwards.
+func (r *repositoryImpl) Delete(bucketName string, objectKey string) error {
+	return r.db.Where("bucket = ? AND key = ?", bucketName, objectKey).Delete(&model.Object{}).Error
+}
+
+// Restore clears deleted_at of an entry previously removed by Delete.
+func (r *repositoryImpl) Restore(bucketName string, objectKey string) error {
+	return r.db.Unscoped().Model(&model.Object{}).
+		Where("bucket = ? AND key = ?", bucketName, objectKey).
+		Update("deleted_at", nil).Error
+}
+
+// Purge permanently removes the entry, whether or not it was deleted before.
+func (r *repositoryImpl) Purge(bucketName string, objectKey string) error {
+	return r.db.Unscoped().Where("bucket = ? AND key = ?", bucketName, objectKey).Delete(&model.Object{}).Error
+}
+
+// Resize records a new size for the entry, whether or not it was deleted, e.g. when another version
+// of the object becomes current.
+func (r *repositoryImpl) Resize(bucketName string, objectKey string, size int64) error {
+	return r.db.Unscoped().Model(&model.Object{}).
+		Where("bucket = ? AND key = ?", bucketName, objectKey).
+		Update("size", size).Error
+}
+
+// IncrementUsage adds bytes and objects (which may be negative) to the owner's usage in category,
+// unless the result would exceed maxBytes or maxObjects, in which case ok is false and nothing changes.
+// A limit of 0 means unlimited. The check and the update happen in one statement so concurrent
+// uploads cannot both squeeze under the limit.
+func (r *repositoryImpl) IncrementUsage(owner string, category string, bytes int64, objects int64, maxBytes int64, maxObjects int64) (ok bool, err error) {
+	err = r.db.Exec(`INSERT INTO usages (owner, category) VALUES (?, ?) ON CONFLICT DO NOTHING`, owner, category).Error
+	if err != nil {
+		return false, err
+	}
+
+	result := r.db.Exec(`UPDATE usages SET bytes = bytes + ?, objects = objects + ?
+		WHERE owner = ? AND category = ?
+		AND (? = 0 OR bytes + ? <= ?)
+		AND (? = 0 OR objects + ? <= ?)`,
+		bytes, objects, owner, category,
+		maxBytes, bytes, maxBytes,
+		maxObjects, objects, maxObjects)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *repositoryImpl) FindUsage(owner string, usages *[]*model.Usage) error {
+	return r.db.Where("owner = ?", owner).Order("category").Find(usages).Error
+}
+
+// SumByCategory totals the size and count of live objects in the bucket per category, across all owners.
+func (r *repositoryImpl) SumByCategory(bucketName string, usages *[]*model.Usage) error {
+	return r.db.Model(&model.Object{}).
+		Select("category, SUM(size) AS bytes, COUNT(*) AS objects").
+		Where("bucket = ? AND NOT pending", bucketName).
+		Group("category").
+		Order("category").
+		Scan(usages).Error
+}
+
+// ReserveIdempotencyKey inserts key unless the owner holds it already and it hasn't expired,
+// in which case reserved is false and nothing changes. Expired keys of the owner are dropped
+// first; the primary key lets only one of concurrent reservations of the same key succeed.
+func (r *repositoryImpl) ReserveIdempotencyKey(key *model.IdempotencyKey) (reserved bool, err error) {
+	err = r.db.Where("owner = ? AND expires_at <= ?", key.Owner, time.Now()).Delete(&model.IdempotencyKey{}).Error
+	if err != nil {
+		return false, err
+	}
+
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *repositoryImpl) FindIdempotencyKey(owner string, key string, record *model.IdempotencyKey) error {
+	return r.db.Where("owner = ? AND key = ?", owner, key).First(record).Error
+}
+
+// CompleteIdempotencyKey records the stored object of a pending key, which is no longer pending afterwards.
+func (r *repositoryImpl) CompleteIdempotencyKey(key *model.IdempotencyKey) error {
+	return r.db.Model(&model.IdempotencyKey{}).
+		Where("owner = ? AND key = ?", key.Owner, key.Key).
+		Updates(map[string]interface{}{
+			"url":        key.Url,
+			"object_key": key.ObjectKey,
+			"version_id": key.VersionID,
+			"pending":    false,
+			"expires_at": key.ExpiresAt,
+		}).Error
+}
+
+// ReleaseIdempotencyKey drops a pending key after its upload failed, so that a retry can take it.
+func (r *repositoryImpl) ReleaseIdempotencyKey(owner string, key string) error {
+	return r.db.Where("owner = ? AND key = ? AND pending", owner, key).Delete(&model.IdempotencyKey{}).Error
+}
+
+// WithTransaction runs txFunc against a repository bound to a single transaction,
+// committing if txFunc returns nil and rolling back otherwise.
+func (r *repositoryImpl) WithTransaction(txFunc func(Repository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return txFunc(&repositoryImpl{db: tx})
+	})
+}
+
+// TryLock runs fn while holding the advisory lock called name, unless another session holds it,
+// in which case locked is false and fn doesn't run. Replicas use it to run a job only once.
+func (r *repositoryImpl) TryLock(name string, fn func() error) (locked bool, err error) {
+	// session advisory locks belong to a connection, so they are taken and released on one
+	err = r.db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Raw(`SELECT pg_try_advisory_lock(hashtext(?))`, name).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+		defer conn.Exec(`SELECT pg_advisory_unlock(hashtext(?))`, name)
+
+		return fn()
+	})
+
+	return locked, err
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
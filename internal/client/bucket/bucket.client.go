@@ -0,0 +1,65 @@
+package bucket
+
+import (
+	"context"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+)
+
+// Client covers the bucket level settings managed at startup, apart from the object calls of store.Client.
+type Client interface {
+	BucketExists(ctx context.Context, bucketName string) (bool, error)
+	MakeBucket(ctx context.Context, bucketName string, opts minio.MakeBucketOptions) error
+	SetBucketPolicy(ctx context.Context, bucketName string, policy string) error
+	GetBucketPolicy(ctx context.Context, bucketName string) (string, error)
+	SetBucketLifecycle(ctx context.Context, bucketName string, config *lifecycle.Configuration) error
+	GetBucketLifecycle(ctx context.Context, bucketName string) (*lifecycle.Configuration, error)
+	SetBucketVersioning(ctx context.Context, bucketName string, config minio.BucketVersioningConfiguration) error
+	GetBucketVersioning(ctx context.Context, bucketName string) (minio.BucketVersioningConfiguration, error)
+	GetObjectLockConfig(ctx context.Context, bucketName string) (objectLock string, mode *minio.RetentionMode, validity *uint, unit *minio.ValidityUnit, err error)
+}
+
+type clientImpl struct {
+	*minio.Client
+}
+
+func NewClient(minioClient *minio.Client) Client {
+	return &clientImpl{minioClient}
+}
+
+func (c *clientImpl) BucketExists(ctx context.Context, bucketName string) (bool, error) {
+	return c.Client.BucketExists(ctx, bucketName)
+}
+
+func (c *clientImpl) MakeBucket(ctx context.Context, bucketName string, opts minio.MakeBucketOptions) error {
+	return c.Client.MakeBucket(ctx, bucketName, opts)
+}
+
+func (c *clientImpl) SetBucketPolicy(ctx context.Context, bucketName string, policy string) error {
+	return c.Client.SetBucketPolicy(ctx, bucketName, policy)
+}
+
+func (c *clientImpl) GetBucketPolicy(ctx context.Context, bucketName string) (string, error) {
+	return c.Client.GetBucketPolicy(ctx, bucketName)
+}
+
+func (c *clientImpl) SetBucketLifecycle(ctx context.Context, bucketName string, config *lifecycle.Configuration) error {
+	return c.Client.SetBucketLifecycle(ctx, bucketName, config)
+}
+
+func (c *clientImpl) GetBucketLifecycle(ctx context.Context, bucketName string) (*lifecycle.Configuration, error) {
+	return c.Client.GetBucketLifecycle(ctx, bucketName)
+}
+
+func (c *clientImpl) SetBucketVersioning(ctx context.Context, bucketName string, config minio.BucketVersioningConfiguration) error {
+	return c.Client.SetBucketVersioning(ctx, bucketName, config)
+}
+
+func (c *clientImpl) GetBucketVersioning(ctx context.Context, bucketName string) (minio.BucketVersioningConfiguration, error) {
+	return c.Client.GetBucketVersioning(ctx, bucketName)
+}
+
+func (c *clientImpl) GetObjectLockConfig(ctx context.Context, bucketName string) (objectLock string, mode *minio.RetentionMode, validity *uint, unit *minio.ValidityUnit, err error) {
+	return c.Client.GetObjectLockConfig(ctx, bucketName)
+}
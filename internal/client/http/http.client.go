@@ -1,11 +1,13 @@
 package http
 
 import (
+	"context"
 	"net/http"
 )
 
 type Client interface {
-	Get(url string) (resp *http.Response, err error)
+	// Head sends a HEAD request for url, which is cancelled along with ctx. The caller closes the body.
+	Head(ctx context.Context, url string) (resp *http.Response, err error)
 }
 
 type clientImpl struct {
@@ -16,6 +18,11 @@ func NewClient(httpClient *http.Client) Client {
 	return &clientImpl{httpClient}
 }
 
-func (c *clientImpl) Get(url string) (resp *http.Response, err error) {
-	return c.Client.Get(url)
+func (c *clientImpl) Head(ctx context.Context, url string) (resp *http.Response, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Client.Do(req)
 }
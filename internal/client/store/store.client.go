@@ -10,6 +10,10 @@ import (
 type Client interface {
 	PutObject(ctx context.Context, bucketName string, objectName string, reader io.Reader, objectSize int64, opts minio.PutObjectOptions) (info minio.UploadInfo, err error)
 	RemoveObject(ctx context.Context, bucketName string, objectName string, opts minio.RemoveObjectOptions) error
+	StatObject(ctx context.Context, bucketName string, objectName string, opts minio.StatObjectOptions) (minio.ObjectInfo, error)
+	ListObjects(ctx context.Context, bucketName string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo
+	CopyObject(ctx context.Context, dst minio.CopyDestOptions, src minio.CopySrcOptions) (minio.UploadInfo, error)
+	BucketExists(ctx context.Context, bucketName string) (bool, error)
 }
 
 type clientImpl struct {
@@ -27,3 +31,19 @@ func (c *clientImpl) PutObject(ctx context.Context, bucketName string, objectNam
 func (c *clientImpl) RemoveObject(ctx context.Context, bucketName string, objectName string, opts minio.RemoveObjectOptions) error {
 	return c.Client.RemoveObject(ctx, bucketName, objectName, opts)
 }
+
+func (c *clientImpl) StatObject(ctx context.Context, bucketName string, objectName string, opts minio.StatObjectOptions) (minio.ObjectInfo, error) {
+	return c.Client.StatObject(ctx, bucketName, objectName, opts)
+}
+
+func (c *clientImpl) ListObjects(ctx context.Context, bucketName string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+	return c.Client.ListObjects(ctx, bucketName, opts)
+}
+
+func (c *clientImpl) CopyObject(ctx context.Context, dst minio.CopyDestOptions, src minio.CopySrcOptions) (minio.UploadInfo, error) {
+	return c.Client.CopyObject(ctx, dst, src)
+}
+
+func (c *clientImpl) BucketExists(ctx context.Context, bucketName string) (bool, error) {
+	return c.Client.BucketExists(ctx, bucketName)
+}
@@ -0,0 +1,145 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/isd-sgcu/rpkm67-store/constant"
+	"github.com/isd-sgcu/rpkm67-store/internal/client/store"
+	"github.com/minio/minio-go/v7"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+var errBucketNotFound = errors.New("bucket does not exist")
+
+var canaryData = []byte("ok")
+
+type Checker interface {
+	// Check probes the storage backend once and returns why it is unusable, if it is.
+	Check(ctx context.Context) error
+	// Run probes the backend every interval until ctx is done, keeping the health server up to date.
+	Run(ctx context.Context)
+}
+
+type checkerImpl struct {
+	server     *health.Server
+	client     store.Client
+	conf       *config.Health
+	bucketName string
+	canaryKey  string
+	services   []string
+	probed     bool
+	healthy    bool
+	log        *zap.Logger
+}
+
+// NewChecker returns a Checker reporting on server. The liveness service is SERVING from the
+// start, while the readiness service, the overall "" service and services stay NOT_SERVING
+// until a probe succeeds.
+func NewChecker(server *health.Server, client store.Client, conf *config.Health, storeConf *config.Store, log *zap.Logger, services ...string) Checker {
+	c := &checkerImpl{
+		server:     server,
+		client:     client,
+		conf:       conf,
+		bucketName: storeConf.BucketName,
+		canaryKey:  constant.HealthCanaryPrefix + conf.Instance,
+		services:   append([]string{"", constant.ReadinessService}, services...),
+		log:        log,
+	}
+	server.SetServingStatus(constant.LivenessService, grpc_health_v1.HealthCheckResponse_SERVING)
+	c.setReadiness(grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	return c
+}
+
+func (c *checkerImpl) Check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.conf.Timeout)
+	defer cancel()
+
+	// an unreachable backend or rejected credentials fail here too
+	exists, err := c.client.BucketExists(ctx, c.bucketName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errBucketNotFound
+	}
+
+	if c.conf.Canary {
+		return c.checkCanary(ctx)
+	}
+
+	return nil
+}
+
+// checkCanary writes, reads back and deletes this replica's canary object. Only the version
+// written is read and deleted, so on a versioned bucket no delete markers pile up.
+func (c *checkerImpl) checkCanary(ctx context.Context) error {
+	written, err := c.client.PutObject(ctx, c.bucketName, c.canaryKey, bytes.NewReader(canaryData), int64(len(canaryData)), minio.PutObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("canary write: %w", err)
+	}
+
+	info, err := c.client.StatObject(ctx, c.bucketName, c.canaryKey, minio.StatObjectOptions{VersionID: written.VersionID})
+	if err != nil {
+		return fmt.Errorf("canary read: %w", err)
+	}
+	if info.Size != int64(len(canaryData)) {
+		return fmt.Errorf("canary read: expected %d bytes, got %d", len(canaryData), info.Size)
+	}
+
+	if err := c.client.RemoveObject(ctx, c.bucketName, c.canaryKey, minio.RemoveObjectOptions{VersionID: written.VersionID}); err != nil {
+		return fmt.Errorf("canary delete: %w", err)
+	}
+
+	return nil
+}
+
+func (c *checkerImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.conf.Interval)
+	defer ticker.Stop()
+
+	for {
+		c.probe(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probe runs a Check and updates the readiness services, logging only when the outcome changes.
+func (c *checkerImpl) probe(ctx context.Context) {
+	err := c.Check(ctx)
+	healthy := err == nil
+
+	if !c.probed || healthy != c.healthy {
+		if healthy {
+			c.log.Named("Check").Info("Storage backend available", zap.String("bucket", c.bucketName))
+		} else {
+			c.log.Named("Check").Error("Storage backend unavailable", zap.String("bucket", c.bucketName), zap.Error(err))
+		}
+	}
+	c.probed = true
+	c.healthy = healthy
+
+	if healthy {
+		c.setReadiness(grpc_health_v1.HealthCheckResponse_SERVING)
+	} else {
+		c.setReadiness(grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	}
+}
+
+func (c *checkerImpl) setReadiness(status grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	for _, service := range c.services {
+		c.server.SetServingStatus(service, status)
+	}
+}
@@ -0,0 +1,125 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/isd-sgcu/rpkm67-store/constant"
+	"github.com/isd-sgcu/rpkm67-store/internal/healthcheck"
+	mock_store "github.com/isd-sgcu/rpkm67-store/mocks/client/store"
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type HealthCheckerTest struct {
+	suite.Suite
+	controller *gomock.Controller
+	conf       *config.Health
+	storeConf  *config.Store
+	server     *health.Server
+}
+
+func TestHealthChecker(t *testing.T) {
+	suite.Run(t, new(HealthCheckerTest))
+}
+
+func (t *HealthCheckerTest) SetupTest() {
+	t.controller = gomock.NewController(t.T())
+	t.conf = &config.Health{
+		Interval: time.Hour,
+		Timeout:  time.Second,
+		Instance: "pod-a",
+	}
+	t.storeConf = &config.Store{
+		BucketName: "mock-bucket",
+	}
+	t.server = health.NewServer()
+}
+
+func (t *HealthCheckerTest) status(service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	resp, err := t.server.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: service})
+	t.Require().Nil(err)
+
+	return resp.Status
+}
+
+// run probes once; the cancelled context stops Run right after its first probe
+func (t *HealthCheckerTest) run(checker healthcheck.Checker) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	checker.Run(ctx)
+}
+
+func (t *HealthCheckerTest) TestNotReadyBeforeFirstProbe() {
+	client := mock_store.NewMockClient(t.controller)
+
+	healthcheck.NewChecker(t.server, client, t.conf, t.storeConf, zap.NewNop(), "object")
+
+	t.Equal(grpc_health_v1.HealthCheckResponse_SERVING, t.status(constant.LivenessService))
+	t.Equal(grpc_health_v1.HealthCheckResponse_NOT_SERVING, t.status(constant.ReadinessService))
+	t.Equal(grpc_health_v1.HealthCheckResponse_NOT_SERVING, t.status(""))
+	t.Equal(grpc_health_v1.HealthCheckResponse_NOT_SERVING, t.status("object"))
+}
+
+func (t *HealthCheckerTest) TestRunServing() {
+	client := mock_store.NewMockClient(t.controller)
+	client.EXPECT().BucketExists(gomock.Any(), t.storeConf.BucketName).Return(true, nil)
+
+	t.run(healthcheck.NewChecker(t.server, client, t.conf, t.storeConf, zap.NewNop(), "object"))
+
+	t.Equal(grpc_health_v1.HealthCheckResponse_SERVING, t.status(constant.ReadinessService))
+	t.Equal(grpc_health_v1.HealthCheckResponse_SERVING, t.status(""))
+	t.Equal(grpc_health_v1.HealthCheckResponse_SERVING, t.status("object"))
+}
+
+func (t *HealthCheckerTest) TestRunBackendError() {
+	client := mock_store.NewMockClient(t.controller)
+	client.EXPECT().BucketExists(gomock.Any(), t.storeConf.BucketName).Return(false, fmt.Errorf("error"))
+
+	t.run(healthcheck.NewChecker(t.server, client, t.conf, t.storeConf, zap.NewNop()))
+
+	t.Equal(grpc_health_v1.HealthCheckResponse_SERVING, t.status(constant.LivenessService))
+	t.Equal(grpc_health_v1.HealthCheckResponse_NOT_SERVING, t.status(constant.ReadinessService))
+}
+
+func (t *HealthCheckerTest) TestCheckBucketMissing() {
+	client := mock_store.NewMockClient(t.controller)
+	client.EXPECT().BucketExists(gomock.Any(), t.storeConf.BucketName).Return(false, nil)
+
+	err := healthcheck.NewChecker(t.server, client, t.conf, t.storeConf, zap.NewNop()).Check(context.Background())
+
+	t.NotNil(err)
+}
+
+func (t *HealthCheckerTest) TestCheckCanarySuccess() {
+	t.conf.Canary = true
+	client := mock_store.NewMockClient(t.controller)
+	gomock.InOrder(
+		client.EXPECT().BucketExists(gomock.Any(), t.storeConf.BucketName).Return(true, nil),
+		client.EXPECT().PutObject(gomock.Any(), t.storeConf.BucketName, constant.HealthCanaryPrefix+"pod-a", gomock.Any(), int64(2), gomock.Any()).Return(minio.UploadInfo{VersionID: "v1"}, nil),
+		client.EXPECT().StatObject(gomock.Any(), t.storeConf.BucketName, constant.HealthCanaryPrefix+"pod-a", minio.StatObjectOptions{VersionID: "v1"}).Return(minio.ObjectInfo{Size: 2}, nil),
+		client.EXPECT().RemoveObject(gomock.Any(), t.storeConf.BucketName, constant.HealthCanaryPrefix+"pod-a", minio.RemoveObjectOptions{VersionID: "v1"}).Return(nil),
+	)
+
+	err := healthcheck.NewChecker(t.server, client, t.conf, t.storeConf, zap.NewNop()).Check(context.Background())
+
+	t.Nil(err)
+}
+
+func (t *HealthCheckerTest) TestCheckCanaryWriteError() {
+	t.conf.Canary = true
+	client := mock_store.NewMockClient(t.controller)
+	client.EXPECT().BucketExists(gomock.Any(), t.storeConf.BucketName).Return(true, nil)
+	client.EXPECT().PutObject(gomock.Any(), t.storeConf.BucketName, constant.HealthCanaryPrefix+"pod-a", gomock.Any(), int64(2), gomock.Any()).Return(minio.UploadInfo{}, fmt.Errorf("error"))
+
+	err := healthcheck.NewChecker(t.server, client, t.conf, t.storeConf, zap.NewNop()).Check(context.Background())
+
+	t.ErrorContains(err, "canary write")
+}
@@ -0,0 +1,109 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrForced is returned by Shutdown when the drain timeout elapsed before every hook finished.
+var ErrForced = errors.New("shutdown was forced")
+
+// abandonTimeout is how long each hook that starts after the drain timeout gets to return,
+// enough to close a client but not to wait on anything.
+const abandonTimeout = time.Second
+
+// Hook releases one part of the service. It should give up once ctx is done, e.g. by
+// cancelling in-flight work instead of waiting for it.
+type Hook func(ctx context.Context) error
+
+type Manager interface {
+	// OnShutdown registers a hook. Hooks run one at a time in the order they were registered,
+	// so each can rely on everything registered before it having stopped.
+	OnShutdown(name string, hook Hook)
+	// Wait blocks until SIGINT or SIGTERM and then shuts down.
+	Wait() error
+	// Shutdown runs the hooks within the drain timeout. Once it elapses, a hook still running is
+	// abandoned, the remaining hooks get a moment each to release what they hold, and the
+	// returned error wraps ErrForced.
+	Shutdown() error
+}
+
+type namedHook struct {
+	name string
+	hook Hook
+}
+
+type managerImpl struct {
+	timeout time.Duration
+	hooks   []namedHook
+	log     *zap.Logger
+}
+
+func NewManager(timeout time.Duration, log *zap.Logger) Manager {
+	return &managerImpl{
+		timeout: timeout,
+		log:     log,
+	}
+}
+
+func (m *managerImpl) OnShutdown(name string, hook Hook) {
+	m.hooks = append(m.hooks, namedHook{name: name, hook: hook})
+}
+
+func (m *managerImpl) Wait() error {
+	s := make(chan os.Signal, 1)
+	signal.Notify(s, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-s
+	signal.Stop(s)
+
+	m.log.Named("Wait").Info("Shutting down", zap.String("signal", sig.String()), zap.Duration("timeout", m.timeout))
+
+	return m.Shutdown()
+}
+
+func (m *managerImpl) Shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	var errs []error
+	for _, h := range m.hooks {
+		log := m.log.Named("Shutdown").With(zap.String("hook", h.name))
+		log.Info("Shutting down")
+
+		done := make(chan error, 1)
+		go func(hook Hook) {
+			done <- hook(ctx)
+		}(h.hook)
+
+		wait, stopWaiting := ctx, context.CancelFunc(func() {})
+		if ctx.Err() != nil {
+			wait, stopWaiting = context.WithTimeout(context.Background(), abandonTimeout)
+		}
+
+		select {
+		case err := <-done:
+			if err != nil {
+				log.Error("Failed to shut down", zap.Error(err))
+				errs = append(errs, fmt.Errorf("%v: %w", h.name, err))
+			} else {
+				log.Info("Shut down")
+			}
+		case <-wait.Done():
+			log.Error("Abandoned after the drain timeout")
+			errs = append(errs, fmt.Errorf("%v: abandoned", h.name))
+		}
+		stopWaiting()
+	}
+	if ctx.Err() != nil {
+		errs = append(errs, ErrForced)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,91 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/isd-sgcu/rpkm67-store/internal/lifecycle"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type ManagerTest struct {
+	suite.Suite
+	logger *zap.Logger
+}
+
+func TestManager(t *testing.T) {
+	suite.Run(t, new(ManagerTest))
+}
+
+func (t *ManagerTest) SetupTest() {
+	t.logger = zap.NewNop()
+}
+
+func (t *ManagerTest) TestShutdownRunsHooksInOrder() {
+	manager := lifecycle.NewManager(time.Second, t.logger)
+	var order []string
+	for _, name := range []string{"health", "server", "database"} {
+		name := name
+		manager.OnShutdown(name, func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	t.Nil(manager.Shutdown())
+	t.Equal([]string{"health", "server", "database"}, order)
+}
+
+func (t *ManagerTest) TestShutdownHookErrorRunsRemainingHooks() {
+	manager := lifecycle.NewManager(time.Second, t.logger)
+	closed := false
+	manager.OnShutdown("metrics server", func(ctx context.Context) error {
+		return errors.New("error")
+	})
+	manager.OnShutdown("database", func(ctx context.Context) error {
+		closed = true
+		return nil
+	})
+
+	err := manager.Shutdown()
+
+	t.EqualError(err, "metrics server: error")
+	t.False(errors.Is(err, lifecycle.ErrForced))
+	t.True(closed)
+}
+
+func (t *ManagerTest) TestShutdownForcedAfterTimeout() {
+	manager := lifecycle.NewManager(20*time.Millisecond, t.logger)
+	closed := false
+	manager.OnShutdown("server", func(ctx context.Context) error {
+		// a drain that ignores the deadline is abandoned
+		time.Sleep(time.Hour)
+		return nil
+	})
+	manager.OnShutdown("database", func(ctx context.Context) error {
+		closed = true
+		return nil
+	})
+
+	start := time.Now()
+	err := manager.Shutdown()
+
+	t.ErrorIs(err, lifecycle.ErrForced)
+	t.True(closed)
+	t.Less(time.Since(start), time.Second)
+}
+
+func (t *ManagerTest) TestShutdownHookSeesDeadline() {
+	manager := lifecycle.NewManager(20*time.Millisecond, t.logger)
+	manager.OnShutdown("server", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	err := manager.Shutdown()
+
+	t.ErrorIs(err, lifecycle.ErrForced)
+}
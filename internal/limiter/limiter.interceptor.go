@@ -0,0 +1,200 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"path"
+	"sync"
+
+	proto "github.com/isd-sgcu/rpkm67-go-proto/rpkm67/store/object/v1"
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/isd-sgcu/rpkm67-store/constant"
+	"github.com/isd-sgcu/rpkm67-store/internal/metrics"
+	"github.com/isd-sgcu/rpkm67-store/logger"
+	"go.uber.org/zap"
+	"golang.org/x/sync/semaphore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/tap"
+)
+
+// inFlightLimit labels the byte budget in metrics, next to the method names of concurrency limits.
+const inFlightLimit = "inflight_bytes"
+
+// Limiter bounds how many calls run at once per method and how many request bytes uploads buffer
+// between them. Calls over a concurrency limit queue in arrival order until capacity frees up,
+// their deadline passes or the queue timeout elapses, and are then rejected with ResourceExhausted.
+type Limiter interface {
+	UnaryServerInterceptor() grpc.UnaryServerInterceptor
+	StreamServerInterceptor() grpc.StreamServerInterceptor
+	// TapHandle applies the byte budget before an upload's request is read, by reserving the
+	// largest message size for it. It can't wait for capacity without stalling the connection,
+	// so an upload over the budget is rejected straight away. Install it with grpc.InTapHandle.
+	TapHandle(ctx context.Context, info *tap.Info) (context.Context, error)
+	// StatsHandler returns the reservations of TapHandle once their calls end. Install it with
+	// grpc.StatsHandler.
+	StatsHandler() stats.Handler
+}
+
+type limiterImpl struct {
+	conf           *config.Limits
+	maxMessageSize int64
+	inFlight       *semaphore.Weighted
+	mu             sync.Mutex
+	methods        map[string]*semaphore.Weighted
+	log            *zap.Logger
+}
+
+// NewLimiter returns a limiter for conf on a server that receives messages of up to maxMessageSize bytes.
+func NewLimiter(conf *config.Limits, maxMessageSize int, log *zap.Logger) Limiter {
+	l := &limiterImpl{
+		conf:           conf,
+		maxMessageSize: int64(maxMessageSize),
+		methods:        make(map[string]*semaphore.Weighted),
+		log:            log,
+	}
+	if conf.InFlightBytes > 0 {
+		l.inFlight = semaphore.NewWeighted(conf.InFlightBytes)
+	}
+
+	return l
+}
+
+func (l *limiterImpl) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		release, err := l.acquireMethod(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
+		return handler(ctx, req)
+	}
+}
+
+type reservationKey struct{}
+
+// reservation is the share of the byte budget an upload holds until it ends.
+type reservation struct {
+	once    sync.Once
+	release func()
+}
+
+func (l *limiterImpl) TapHandle(ctx context.Context, info *tap.Info) (context.Context, error) {
+	if l.inFlight == nil || info.FullMethodName != proto.ObjectService_Upload_FullMethodName {
+		return ctx, nil
+	}
+
+	// a budget smaller than one message still lets a single upload through
+	size := min(l.maxMessageSize, l.conf.InFlightBytes)
+	if !l.inFlight.TryAcquire(size) {
+		return nil, l.reject(ctx, inFlightLimit)
+	}
+
+	return context.WithValue(ctx, reservationKey{}, &reservation{release: func() { l.inFlight.Release(size) }}), nil
+}
+
+func (l *limiterImpl) StatsHandler() stats.Handler {
+	return releaseHandler{}
+}
+
+type releaseHandler struct{}
+
+func (releaseHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (releaseHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	if _, ok := s.(*stats.End); !ok {
+		return
+	}
+	if r, ok := ctx.Value(reservationKey{}).(*reservation); ok {
+		r.once.Do(r.release)
+	}
+}
+
+func (releaseHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (releaseHandler) HandleConn(context.Context, stats.ConnStats) {}
+
+func (l *limiterImpl) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		release, err := l.acquireMethod(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		return handler(srv, ss)
+	}
+}
+
+// acquireMethod takes a slot from the concurrency limit of fullMethod, if it has one.
+func (l *limiterImpl) acquireMethod(ctx context.Context, fullMethod string) (func(), error) {
+	method := path.Base(fullMethod)
+	sem := l.methodSemaphore(method)
+	if sem == nil {
+		return func() {}, nil
+	}
+	if err := l.acquire(ctx, sem, 1, method); err != nil {
+		return nil, err
+	}
+
+	return func() { sem.Release(1) }, nil
+}
+
+func (l *limiterImpl) methodSemaphore(method string) *semaphore.Weighted {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if sem, ok := l.methods[method]; ok {
+		return sem
+	}
+
+	limit, ok := l.conf.Concurrency[method]
+	if !ok {
+		limit, ok = l.conf.Concurrency["*"]
+	}
+	var sem *semaphore.Weighted
+	if ok {
+		sem = semaphore.NewWeighted(int64(limit))
+	}
+	l.methods[method] = sem
+
+	return sem
+}
+
+func (l *limiterImpl) acquire(ctx context.Context, sem *semaphore.Weighted, n int64, limit string) error {
+	if sem.TryAcquire(n) {
+		return nil
+	}
+	if l.conf.QueueTimeout <= 0 {
+		return l.reject(ctx, limit)
+	}
+
+	queueCtx, cancel := context.WithTimeout(ctx, l.conf.QueueTimeout)
+	defer cancel()
+
+	metrics.AddQueued(limit, 1)
+	err := sem.Acquire(queueCtx, n)
+	metrics.AddQueued(limit, -1)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+
+	return l.reject(ctx, limit)
+}
+
+func (l *limiterImpl) reject(ctx context.Context, limit string) error {
+	metrics.ObserveRejected(limit)
+	logger.FromContext(ctx, l.log).Warn("Rejected call over limit", zap.String("limit", limit))
+
+	return status.Error(codes.ResourceExhausted, constant.ServerBusyErrorMessage)
+}
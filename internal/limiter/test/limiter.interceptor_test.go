@@ -0,0 +1,164 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	proto "github.com/isd-sgcu/rpkm67-go-proto/rpkm67/store/object/v1"
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/isd-sgcu/rpkm67-store/constant"
+	"github.com/isd-sgcu/rpkm67-store/internal/limiter"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/tap"
+)
+
+type LimiterTest struct {
+	suite.Suite
+	conf           *config.Limits
+	maxMessageSize int
+	logger         *zap.Logger
+	upload         *grpc.UnaryServerInfo
+	find           *grpc.UnaryServerInfo
+}
+
+func TestLimiter(t *testing.T) {
+	suite.Run(t, new(LimiterTest))
+}
+
+func (t *LimiterTest) SetupTest() {
+	t.logger = zap.NewNop()
+	t.conf = &config.Limits{
+		Concurrency:  map[string]int{"Upload": 1},
+		QueueTimeout: time.Second,
+	}
+	t.maxMessageSize = 40
+	t.upload = &grpc.UnaryServerInfo{FullMethod: proto.ObjectService_Upload_FullMethodName}
+	t.find = &grpc.UnaryServerInfo{FullMethod: proto.ObjectService_FindByKey_FullMethodName}
+}
+
+// hold starts a call that stays in its handler until the returned function is called.
+func (t *LimiterTest) hold(interceptor grpc.UnaryServerInterceptor, req interface{}, info *grpc.UnaryServerInfo) func() {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := interceptor(context.Background(), req, info, func(context.Context, interface{}) (interface{}, error) {
+			close(entered)
+			<-release
+			return nil, nil
+		})
+		t.Nil(err)
+	}()
+	<-entered
+
+	return func() {
+		close(release)
+		<-done
+	}
+}
+
+func (t *LimiterTest) call(ctx context.Context, interceptor grpc.UnaryServerInterceptor, req interface{}, info *grpc.UnaryServerInfo) error {
+	_, err := interceptor(ctx, req, info, func(context.Context, interface{}) (interface{}, error) {
+		return "response", nil
+	})
+
+	return err
+}
+
+func (t *LimiterTest) TestConcurrencyLimitRejects() {
+	t.conf.QueueTimeout = 0
+	interceptor := limiter.NewLimiter(t.conf, t.maxMessageSize, t.logger).UnaryServerInterceptor()
+	release := t.hold(interceptor, &proto.UploadObjectRequest{}, t.upload)
+	defer release()
+
+	err := t.call(context.Background(), interceptor, &proto.UploadObjectRequest{}, t.upload)
+
+	t.Equal(codes.ResourceExhausted, status.Code(err))
+	t.Equal(constant.ServerBusyErrorMessage, status.Convert(err).Message())
+	// other methods have no limit of their own
+	t.Nil(t.call(context.Background(), interceptor, &proto.FindByKeyObjectRequest{}, t.find))
+}
+
+func (t *LimiterTest) TestConcurrencyLimitQueues() {
+	interceptor := limiter.NewLimiter(t.conf, t.maxMessageSize, t.logger).UnaryServerInterceptor()
+	release := t.hold(interceptor, &proto.UploadObjectRequest{}, t.upload)
+
+	time.AfterFunc(20*time.Millisecond, release)
+	err := t.call(context.Background(), interceptor, &proto.UploadObjectRequest{}, t.upload)
+
+	t.Nil(err)
+}
+
+func (t *LimiterTest) TestQueueRespectsDeadline() {
+	interceptor := limiter.NewLimiter(t.conf, t.maxMessageSize, t.logger).UnaryServerInterceptor()
+	release := t.hold(interceptor, &proto.UploadObjectRequest{}, t.upload)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := t.call(ctx, interceptor, &proto.UploadObjectRequest{}, t.upload)
+
+	t.Equal(codes.ResourceExhausted, status.Code(err))
+	t.Less(time.Since(start), t.conf.QueueTimeout)
+}
+
+func (t *LimiterTest) TestDefaultConcurrencyLimit() {
+	t.conf.QueueTimeout = 0
+	t.conf.Concurrency = map[string]int{"*": 1}
+	interceptor := limiter.NewLimiter(t.conf, t.maxMessageSize, t.logger).UnaryServerInterceptor()
+	release := t.hold(interceptor, &proto.FindByKeyObjectRequest{}, t.find)
+	defer release()
+
+	t.Equal(codes.ResourceExhausted, status.Code(t.call(context.Background(), interceptor, &proto.FindByKeyObjectRequest{}, t.find)))
+	// each method gets a limit of its own
+	t.Nil(t.call(context.Background(), interceptor, &proto.UploadObjectRequest{}, t.upload))
+}
+
+func (t *LimiterTest) TestInFlightBytesLimit() {
+	t.conf.InFlightBytes = 100
+	callLimiter := limiter.NewLimiter(t.conf, t.maxMessageSize, t.logger)
+	upload := &tap.Info{FullMethodName: t.upload.FullMethod}
+
+	// each upload reserves a whole message, so two fit in the budget
+	first, err := callLimiter.TapHandle(context.Background(), upload)
+	t.Require().Nil(err)
+	_, err = callLimiter.TapHandle(context.Background(), upload)
+	t.Require().Nil(err)
+	_, err = callLimiter.TapHandle(context.Background(), upload)
+	t.Equal(codes.ResourceExhausted, status.Code(err))
+	// other methods don't carry files
+	_, err = callLimiter.TapHandle(context.Background(), &tap.Info{FullMethodName: t.find.FullMethod})
+	t.Nil(err)
+
+	callLimiter.StatsHandler().HandleRPC(first, &stats.End{})
+	// a call only returns its reservation once
+	callLimiter.StatsHandler().HandleRPC(first, &stats.End{})
+	_, err = callLimiter.TapHandle(context.Background(), upload)
+	t.Nil(err)
+	_, err = callLimiter.TapHandle(context.Background(), upload)
+	t.Equal(codes.ResourceExhausted, status.Code(err))
+}
+
+func (t *LimiterTest) TestInFlightBytesBelowMessageSize() {
+	t.conf.InFlightBytes = 10
+	callLimiter := limiter.NewLimiter(t.conf, t.maxMessageSize, t.logger)
+	upload := &tap.Info{FullMethodName: t.upload.FullMethod}
+
+	// a budget smaller than a message still admits one upload at a time
+	first, err := callLimiter.TapHandle(context.Background(), upload)
+	t.Require().Nil(err)
+	_, err = callLimiter.TapHandle(context.Background(), upload)
+	t.Equal(codes.ResourceExhausted, status.Code(err))
+
+	callLimiter.StatsHandler().HandleRPC(first, &stats.End{})
+	_, err = callLimiter.TapHandle(context.Background(), upload)
+	t.Nil(err)
+}
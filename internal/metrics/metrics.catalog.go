@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"github.com/isd-sgcu/rpkm67-store/internal/catalog"
+	"github.com/isd-sgcu/rpkm67-store/internal/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+var (
+	storedBytesDesc = prometheus.NewDesc(
+		"store_stored_bytes",
+		"Total size of live cataloged objects, by category.",
+		[]string{"category"}, nil,
+	)
+	storedObjectsDesc = prometheus.NewDesc(
+		"store_stored_objects",
+		"Number of live cataloged objects, by category.",
+		[]string{"category"}, nil,
+	)
+)
+
+type catalogCollector struct {
+	catalogRepo catalog.Repository
+	bucketName  string
+	log         *zap.Logger
+}
+
+// NewCatalogCollector reports the bytes and objects stored per category, read from the
+// catalog on every scrape.
+func NewCatalogCollector(catalogRepo catalog.Repository, bucketName string, log *zap.Logger) prometheus.Collector {
+	return &catalogCollector{
+		catalogRepo: catalogRepo,
+		bucketName:  bucketName,
+		log:         log,
+	}
+}
+
+func (c *catalogCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- storedBytesDesc
+	ch <- storedObjectsDesc
+}
+
+func (c *catalogCollector) Collect(ch chan<- prometheus.Metric) {
+	var usages []*model.Usage
+	if err := c.catalogRepo.SumByCategory(c.bucketName, &usages); err != nil {
+		c.log.Named("Collect").Error("SumByCategory: ", zap.Error(err))
+		ch <- prometheus.NewInvalidMetric(storedBytesDesc, err)
+		return
+	}
+
+	for _, usage := range usages {
+		ch <- prometheus.MustNewConstMetric(storedBytesDesc, prometheus.GaugeValue, float64(usage.Bytes), usage.Category)
+		ch <- prometheus.MustNewConstMetric(storedObjectsDesc, prometheus.GaugeValue, float64(usage.Objects), usage.Category)
+	}
+}
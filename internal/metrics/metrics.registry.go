@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds every metric exposed by the service.
+var Registry = prometheus.NewRegistry()
+
+var (
+	grpcHandled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "Total number of gRPC calls completed on the server, by method and status code.",
+	}, []string{"grpc_service", "grpc_method", "grpc_code"})
+
+	grpcHandlingSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Time taken by the server to handle gRPC calls.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"grpc_service", "grpc_method"})
+
+	storeRequestSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "store_backend_request_duration_seconds",
+		Help:    "Latency of calls to the object storage backend, by operation.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	storeErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "store_backend_errors_total",
+		Help: "Failed calls to the object storage backend, by operation.",
+	}, []string{"operation"})
+
+	uploadSizeBytes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "store_upload_size_bytes",
+		Help:    "Size of uploaded objects, by category.",
+		Buckets: prometheus.ExponentialBuckets(1024, 4, 8),
+	}, []string{"category"})
+
+	limiterQueued = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "grpc_server_limiter_queued",
+		Help: "Calls waiting for capacity, by limit: a method's concurrency or the in-flight byte budget.",
+	}, []string{"limit"})
+
+	limiterRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_limiter_rejected_total",
+		Help: "Calls rejected because capacity didn't free up in time, by limit.",
+	}, []string{"limit"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		grpcHandled,
+		grpcHandlingSeconds,
+		storeRequestSeconds,
+		storeErrors,
+		uploadSizeBytes,
+		limiterQueued,
+		limiterRejected,
+	)
+}
+
+// Handler serves the metrics in Registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveUpload records the size of a successfully uploaded object.
+func ObserveUpload(category string, size int64) {
+	uploadSizeBytes.WithLabelValues(category).Observe(float64(size))
+}
+
+// AddQueued changes the number of calls waiting on limit by delta.
+func AddQueued(limit string, delta float64) {
+	limiterQueued.WithLabelValues(limit).Add(delta)
+}
+
+// ObserveRejected records a call rejected by limit.
+func ObserveRejected(limit string) {
+	limiterRejected.WithLabelValues(limit).Inc()
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/isd-sgcu/rpkm67-store/internal/client/store"
+	"github.com/minio/minio-go/v7"
+)
+
+type storeClient struct {
+	client store.Client
+}
+
+// NewStoreClient wraps client to record the latency and errors of every storage backend call.
+// Lookups of missing objects are expected and not counted as errors.
+func NewStoreClient(client store.Client) store.Client {
+	return &storeClient{
+		client: client,
+	}
+}
+
+func observeStore(operation string, start time.Time, err error) {
+	storeRequestSeconds.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil && !isNotFound(err) {
+		storeErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+func isNotFound(err error) bool {
+	code := minio.ToErrorResponse(err).Code
+	return code == "NoSuchKey" || code == "NoSuchVersion"
+}
+
+func (c *storeClient) PutObject(ctx context.Context, bucketName string, objectName string, reader io.Reader, objectSize int64, opts minio.PutObjectOptions) (info minio.UploadInfo, err error) {
+	defer func(start time.Time) { observeStore("PutObject", start, err) }(time.Now())
+	return c.client.PutObject(ctx, bucketName, objectName, reader, objectSize, opts)
+}
+
+func (c *storeClient) RemoveObject(ctx context.Context, bucketName string, objectName string, opts minio.RemoveObjectOptions) (err error) {
+	defer func(start time.Time) { observeStore("RemoveObject", start, err) }(time.Now())
+	return c.client.RemoveObject(ctx, bucketName, objectName, opts)
+}
+
+func (c *storeClient) StatObject(ctx context.Context, bucketName string, objectName string, opts minio.StatObjectOptions) (info minio.ObjectInfo, err error) {
+	defer func(start time.Time) { observeStore("StatObject", start, err) }(time.Now())
+	return c.client.StatObject(ctx, bucketName, objectName, opts)
+}
+
+// ListObjects times the listing until the caller has drained the channel or given up on it
+// by cancelling ctx. An error reported in the listing counts as an error of the call.
+func (c *storeClient) ListObjects(ctx context.Context, bucketName string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+	start := time.Now()
+	objects := c.client.ListObjects(ctx, bucketName, opts)
+
+	relayed := make(chan minio.ObjectInfo)
+	go func() {
+		defer close(relayed)
+
+		var err error
+		defer func() { observeStore("ListObjects", start, err) }()
+		for info := range objects {
+			if info.Err != nil {
+				err = info.Err
+			}
+			select {
+			case relayed <- info:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return relayed
+}
+
+func (c *storeClient) CopyObject(ctx context.Context, dst minio.CopyDestOptions, src minio.CopySrcOptions) (info minio.UploadInfo, err error) {
+	defer func(start time.Time) { observeStore("CopyObject", start, err) }(time.Now())
+	return c.client.CopyObject(ctx, dst, src)
+}
+
+func (c *storeClient) BucketExists(ctx context.Context, bucketName string) (exists bool, err error) {
+	defer func(start time.Time) { observeStore("BucketExists", start, err) }(time.Now())
+	return c.client.BucketExists(ctx, bucketName)
+}
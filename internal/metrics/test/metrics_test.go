@@ -0,0 +1,151 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/isd-sgcu/rpkm67-store/internal/metrics"
+	"github.com/isd-sgcu/rpkm67-store/internal/model"
+	mock_catalog "github.com/isd-sgcu/rpkm67-store/mocks/catalog"
+	mock_store "github.com/isd-sgcu/rpkm67-store/mocks/client/store"
+	"github.com/minio/minio-go/v7"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type MetricsTest struct {
+	suite.Suite
+	controller *gomock.Controller
+}
+
+func TestMetrics(t *testing.T) {
+	suite.Run(t, new(MetricsTest))
+}
+
+func (t *MetricsTest) SetupTest() {
+	t.controller = gomock.NewController(t.T())
+}
+
+// value returns the value of the counter, or the sample count of the histogram, with the given labels.
+func (t *MetricsTest) value(name string, labels map[string]string) float64 {
+	families, err := metrics.Registry.Gather()
+	t.Require().Nil(err)
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metricLoop:
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if labels[label.GetName()] != label.GetValue() {
+					continue metricLoop
+				}
+			}
+			if metric.GetHistogram() != nil {
+				return float64(metric.GetHistogram().GetSampleCount())
+			}
+			return metric.GetCounter().GetValue()
+		}
+	}
+
+	return 0
+}
+
+func (t *MetricsTest) TestUnaryServerInterceptor() {
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.v1.TestService/Unary"}
+	labels := map[string]string{"grpc_service": "test.v1.TestService", "grpc_method": "Unary", "grpc_code": "NotFound"}
+	before := t.value("grpc_server_handled_total", labels)
+
+	_, err := metrics.UnaryServerInterceptor()(context.Background(), nil, info, func(context.Context, interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "not found")
+	})
+
+	t.Equal(codes.NotFound, status.Code(err))
+	t.Equal(before+1, t.value("grpc_server_handled_total", labels))
+	t.Equal(float64(1), t.value("grpc_server_handling_seconds", map[string]string{"grpc_service": "test.v1.TestService", "grpc_method": "Unary"}))
+}
+
+func (t *MetricsTest) TestStoreClientCountsErrors() {
+	client := mock_store.NewMockClient(t.controller)
+	client.EXPECT().StatObject(gomock.Any(), "bucket", "missing", gomock.Any()).Return(minio.ObjectInfo{}, minio.ErrorResponse{Code: "NoSuchKey"})
+	client.EXPECT().StatObject(gomock.Any(), "bucket", "broken", gomock.Any()).Return(minio.ObjectInfo{}, fmt.Errorf("error"))
+	labels := map[string]string{"operation": "StatObject"}
+	beforeErrors := t.value("store_backend_errors_total", labels)
+	beforeCalls := t.value("store_backend_request_duration_seconds", labels)
+
+	storeClient := metrics.NewStoreClient(client)
+	_, _ = storeClient.StatObject(context.Background(), "bucket", "missing", minio.StatObjectOptions{})
+	_, _ = storeClient.StatObject(context.Background(), "bucket", "broken", minio.StatObjectOptions{})
+
+	t.Equal(beforeErrors+1, t.value("store_backend_errors_total", labels))
+	t.Equal(beforeCalls+2, t.value("store_backend_request_duration_seconds", labels))
+}
+
+func (t *MetricsTest) TestStoreClientTimesListingUntilDrained() {
+	objects := make(chan minio.ObjectInfo, 2)
+	objects <- minio.ObjectInfo{Key: "object"}
+	objects <- minio.ObjectInfo{Err: fmt.Errorf("error")}
+	close(objects)
+
+	client := mock_store.NewMockClient(t.controller)
+	client.EXPECT().ListObjects(gomock.Any(), "bucket", gomock.Any()).Return(objects)
+	labels := map[string]string{"operation": "ListObjects"}
+	beforeErrors := t.value("store_backend_errors_total", labels)
+	beforeCalls := t.value("store_backend_request_duration_seconds", labels)
+
+	storeClient := metrics.NewStoreClient(client)
+	listing := storeClient.ListObjects(context.Background(), "bucket", minio.ListObjectsOptions{})
+	<-listing
+	t.Equal(beforeCalls, t.value("store_backend_request_duration_seconds", labels))
+
+	for range listing {
+	}
+	t.Eventually(func() bool {
+		return t.value("store_backend_request_duration_seconds", labels) == beforeCalls+1
+	}, time.Second, time.Millisecond)
+	t.Equal(beforeErrors+1, t.value("store_backend_errors_total", labels))
+}
+
+func (t *MetricsTest) TestObserveUpload() {
+	labels := map[string]string{"category": "metrics-test"}
+
+	metrics.ObserveUpload("metrics-test", 2048)
+
+	t.Equal(float64(1), t.value("store_upload_size_bytes", labels))
+}
+
+func (t *MetricsTest) TestCatalogCollector() {
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	catalogRepo.EXPECT().SumByCategory("bucket", gomock.Any()).DoAndReturn(func(_ string, usages *[]*model.Usage) error {
+		*usages = []*model.Usage{{Category: "avatar", Bytes: 100, Objects: 2}}
+		return nil
+	})
+
+	collector := metrics.NewCatalogCollector(catalogRepo, "bucket", zap.NewNop())
+
+	expected := `
+# HELP store_stored_bytes Total size of live cataloged objects, by category.
+# TYPE store_stored_bytes gauge
+store_stored_bytes{category="avatar"} 100
+`
+	t.Nil(testutil.CollectAndCompare(collector, strings.NewReader(expected), "store_stored_bytes"))
+}
+
+func (t *MetricsTest) TestHandler() {
+	recorder := httptest.NewRecorder()
+
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	t.Equal(200, recorder.Code)
+	t.Contains(recorder.Body.String(), "go_goroutines")
+}
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// Backfill records that every object of the bucket stored before the catalog existed has been
+// added to it, so the catalog can be trusted to list all of the bucket's objects.
+type Backfill struct {
+	Bucket      string    `gorm:"primaryKey" json:"bucket"`
+	CompletedAt time.Time `json:"completed_at"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// IdempotencyKey reserves an owner's idempotency key for one upload until ExpiresAt. It stays
+// pending while the upload is in progress, then records where the object was stored.
+type IdempotencyKey struct {
+	Owner     string    `gorm:"primaryKey" json:"owner"`
+	Key       string    `gorm:"primaryKey" json:"key"`
+	Checksum  string    `json:"checksum"`
+	Url       string    `json:"url"`
+	ObjectKey string    `json:"object_key"`
+	VersionID string    `json:"version_id"`
+	Pending   bool      `json:"pending"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
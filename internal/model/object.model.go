@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Object is the catalog entry of an object stored in the bucket. Pending entries are reserved
+// for uploads still in progress and are hidden from lookups until committed.
+type Object struct {
+	Bucket      string         `gorm:"primaryKey" json:"bucket"`
+	Key         string         `gorm:"primaryKey" json:"key"`
+	Owner       string         `json:"owner"`
+	Category    string         `json:"category"`
+	Size        int64          `json:"size"`
+	ContentType string         `json:"content_type"`
+	Checksum    string         `json:"checksum"`
+	Pending     bool           `json:"pending"`
+	CreatedAt   time.Time      `json:"created_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at"`
+}
@@ -0,0 +1,9 @@
+package model
+
+// Usage is the storage an owner currently uses in a category.
+type Usage struct {
+	Owner    string `gorm:"primaryKey" json:"owner"`
+	Category string `gorm:"primaryKey" json:"category"`
+	Bytes    int64  `json:"bytes"`
+	Objects  int64  `json:"objects"`
+}
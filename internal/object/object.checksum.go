@@ -0,0 +1,75 @@
+package object
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/isd-sgcu/rpkm67-store/constant"
+	"github.com/isd-sgcu/rpkm67-store/internal/utils"
+)
+
+// Checksums are the digests of an upload's data. The backend verifies the data it receives
+// against them, and they are kept in the object's metadata.
+type Checksums struct {
+	SHA256 [sha256.Size]byte
+	MD5    [md5.Size]byte
+}
+
+func computeChecksums(data []byte) Checksums {
+	return Checksums{
+		SHA256: sha256.Sum256(data),
+		MD5:    md5.Sum(data),
+	}
+}
+
+// verifyChecksums compares the checksums the caller sent in the metadata with sums. Missing
+// checksums are skipped.
+func verifyChecksums(ctx context.Context, sums Checksums) error {
+	expected := []struct {
+		key    string
+		actual []byte
+	}{
+		{constant.ChecksumSHA256MetadataKey, sums.SHA256[:]},
+		{constant.ContentMD5MetadataKey, sums.MD5[:]},
+	}
+
+	for _, e := range expected {
+		value := utils.GetMetadataValue(ctx, e.key)
+		if value == "" {
+			continue
+		}
+
+		sum, ok := decodeChecksum(value, len(e.actual))
+		if !ok {
+			return newInvalidArgumentError(e.key, constant.InvalidChecksumErrorMessage)
+		}
+		if string(sum) != string(e.actual) {
+			return newInvalidArgumentError(e.key, constant.ChecksumMismatchErrorMessage)
+		}
+	}
+
+	return nil
+}
+
+// decodeChecksum decodes a checksum of size bytes from hex, or from base64 as in Content-MD5.
+func decodeChecksum(value string, size int) ([]byte, bool) {
+	if len(value) == hex.EncodedLen(size) {
+		if sum, err := hex.DecodeString(value); err == nil {
+			return sum, true
+		}
+	}
+	if sum, err := base64.StdEncoding.DecodeString(value); err == nil && len(sum) == size {
+		return sum, true
+	}
+
+	return nil, false
+}
+
+// setChecksumHeaders returns the checksums of the stored data in the response headers.
+func setChecksumHeaders(ctx context.Context, sums Checksums) {
+	utils.SetHeaderValue(ctx, constant.ChecksumSHA256MetadataKey, hex.EncodeToString(sums.SHA256[:]))
+	utils.SetHeaderValue(ctx, constant.ContentMD5MetadataKey, base64.StdEncoding.EncodeToString(sums.MD5[:]))
+}
@@ -0,0 +1,119 @@
+package object
+
+import (
+	"errors"
+
+	"github.com/isd-sgcu/rpkm67-store/constant"
+	"github.com/isd-sgcu/rpkm67-store/internal/resilience"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// ErrorKind classifies why an object operation failed.
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindNotFound
+	KindInvalidArgument
+	KindTooLarge
+	KindQuotaExceeded
+	KindConflict
+	KindRetained
+	KindUnavailable
+)
+
+// Error is the error returned by the Service. It implements GRPCStatus, so the status sent
+// to the caller is derived from its kind in one place instead of being built by every method.
+type Error struct {
+	Kind    ErrorKind
+	Message string
+	// Field is the request field at fault for KindInvalidArgument and KindTooLarge
+	Field string
+	// Subject is what a quota or retention error is about, e.g. the owner's category or the key
+	Subject string
+	// Err is the underlying cause. It is logged but never sent to the caller.
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// GRPCStatus maps the error to its gRPC status, with errdetails describing the failure
+// for the kinds a caller can act on.
+func (e *Error) GRPCStatus() *status.Status {
+	switch e.Kind {
+	case KindNotFound:
+		return status.New(codes.NotFound, e.Message)
+	case KindInvalidArgument, KindTooLarge:
+		return withDetails(status.New(codes.InvalidArgument, e.Message), &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: e.Field, Description: e.Message}},
+		})
+	case KindQuotaExceeded:
+		return withDetails(status.New(codes.ResourceExhausted, e.Message), &errdetails.QuotaFailure{
+			Violations: []*errdetails.QuotaFailure_Violation{{Subject: e.Subject, Description: e.Message}},
+		})
+	case KindConflict:
+		return status.New(codes.AlreadyExists, e.Message)
+	case KindRetained:
+		return withDetails(status.New(codes.FailedPrecondition, e.Message), &errdetails.PreconditionFailure{
+			Violations: []*errdetails.PreconditionFailure_Violation{{Type: "RETENTION", Subject: e.Subject, Description: e.Message}},
+		})
+	case KindUnavailable:
+		return status.New(codes.Unavailable, e.Message)
+	default:
+		return status.New(codes.Internal, constant.InternalServerErrorMessage)
+	}
+}
+
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) *status.Status {
+	if detailed, err := st.WithDetails(details...); err == nil {
+		return detailed
+	}
+
+	return st
+}
+
+func newNotFoundError() error {
+	return &Error{Kind: KindNotFound, Message: constant.ObjectNotFoundErrorMessage}
+}
+
+func newInvalidArgumentError(field string, message string) error {
+	return &Error{Kind: KindInvalidArgument, Message: message, Field: field}
+}
+
+func newTooLargeError(field string) error {
+	return &Error{Kind: KindTooLarge, Message: constant.FileTooLargeErrorMessage, Field: field}
+}
+
+func newQuotaExceededError(owner string, category string) error {
+	return &Error{Kind: KindQuotaExceeded, Message: constant.QuotaExceededErrorMessage, Subject: owner + ":" + category}
+}
+
+func newConflictError(message string) error {
+	return &Error{Kind: KindConflict, Message: message}
+}
+
+func newRetainedError(key string) error {
+	return &Error{Kind: KindRetained, Message: constant.ObjectRetainedErrorMessage, Subject: key}
+}
+
+// newInternalError reports err as Unavailable instead when the circuit breaker refused the
+// call, so callers know to retry later.
+func newInternalError(err error) error {
+	if errors.Is(err, resilience.ErrCircuitOpen) {
+		return &Error{Kind: KindUnavailable, Message: constant.StorageUnavailableErrorMessage, Err: err}
+	}
+	return &Error{Kind: KindInternal, Message: constant.InternalServerErrorMessage, Err: err}
+}
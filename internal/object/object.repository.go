@@ -3,11 +3,14 @@ package object
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 	"fmt"
 	"net/http"
+	neturl "net/url"
 	"time"
 
 	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/isd-sgcu/rpkm67-store/constant"
 	httpClient "github.com/isd-sgcu/rpkm67-store/internal/client/http"
 	storeClient "github.com/isd-sgcu/rpkm67-store/internal/client/store"
 	"github.com/minio/minio-go/v7"
@@ -15,12 +18,77 @@ import (
 )
 
 type Repository interface {
-	Upload(file []byte, bucketName string, objectKey string) (url string, key string, err error)
-	Delete(bucketName string, objectKey string) (err error)
-	Get(bucketName string, objectKey string) (url string, err error)
+	Upload(ctx context.Context, file []byte, bucketName string, objectKey string, opts UploadOptions) (url string, key string, versionID string, err error)
+	Delete(ctx context.Context, bucketName string, objectKey string) (err error)
+	Purge(ctx context.Context, bucketName string, objectKey string, bypassGovernance bool) (err error)
+	GetRetention(ctx context.Context, bucketName string, objectKey string, versionID string) (retention *Retention, err error)
+	GetVersion(ctx context.Context, bucketName string, objectKey string, versionID string) (url string, err error)
+	DeleteVersion(ctx context.Context, bucketName string, objectKey string, versionID string) (err error)
+	ListVersions(ctx context.Context, bucketName string, objectKey string) (versions []ObjectVersion, err error)
+	RestoreVersion(ctx context.Context, bucketName string, objectKey string, versionID string) (url string, newVersionID string, err error)
+	Trash(ctx context.Context, bucketName string, objectKey string) (err error)
+	Restore(ctx context.Context, bucketName string, objectKey string) (url string, err error)
+	Get(ctx context.Context, bucketName string, objectKey string) (url string, err error)
 	GetURL(bucketName string, objectKey string) string
+	SetAlias(ctx context.Context, bucketName string, alias string, objectKey string) (err error)
+	ResolveAlias(ctx context.Context, bucketName string, alias string) (key string, err error)
+	List(ctx context.Context, bucketName string, prefix string) (objects []StoredObject, err error)
 }
 
+type StoredObject struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+type ObjectVersion struct {
+	VersionID      string
+	Size           int64
+	LastModified   time.Time
+	IsLatest       bool
+	IsDeleteMarker bool
+}
+
+type UploadOptions struct {
+	ContentType string
+	Retention   Retention
+	// Checksums are sent for the backend to verify; the zero value sends none
+	Checksums Checksums
+}
+
+// Retention is the object lock state of an object. The zero value means unlocked.
+type Retention struct {
+	Mode        minio.RetentionMode
+	RetainUntil time.Time
+	LegalHold   bool
+}
+
+// Locked reports whether deleting the object at now would violate its retention.
+// Governance retention can be bypassed by privileged callers; compliance retention and legal holds cannot.
+func (r *Retention) Locked(now time.Time, bypassGovernance bool) bool {
+	if r.LegalHold {
+		return true
+	}
+	if !r.Mode.IsValid() || !now.Before(r.RetainUntil) {
+		return false
+	}
+
+	return r.Mode == minio.Compliance || !bypassGovernance
+}
+
+const (
+	// aliases are stored as empty marker objects under constant.AliasPrefix, pointing at
+	// the underlying object through their user metadata
+	aliasTargetMetadata = "Alias-Target"
+
+	// checksums of uploads are kept in their user metadata, hex encoded
+	checksumSHA256Metadata = "Checksum-Sha256"
+	checksumMD5Metadata    = "Checksum-Md5"
+
+	// uploaded keys carry a random suffix and are never overwritten, so they can be cached forever
+	immutableCacheControl = "public, max-age=31536000, immutable"
+)
+
 type repositoryImpl struct {
 	conf        *config.Store
 	storeClient storeClient.Client
@@ -35,31 +103,26 @@ func NewRepository(conf *config.Store, storeClient storeClient.Client, httpClien
 	}
 }
 
-func (r *repositoryImpl) Upload(file []byte, bucketName string, objectKey string) (url string, key string, err error) {
-	ctx := context.Background()
-	_, cancel := context.WithTimeout(ctx, 50*time.Second)
+func (r *repositoryImpl) Upload(ctx context.Context, file []byte, bucketName string, objectKey string, opts UploadOptions) (url string, key string, versionID string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Second)
 	defer cancel()
 
 	buffer := bytes.NewReader(file)
 
 	uploadOutput, err := r.storeClient.PutObject(ctx, bucketName, objectKey, buffer,
-		buffer.Size(), minio.PutObjectOptions{})
+		buffer.Size(), putObjectOptions(opts))
 	if err != nil {
-		return "", "", errors.Wrap(err, fmt.Sprintf("Couldn't upload object to %v/%v.", bucketName, objectKey))
+		return "", "", "", errors.Wrap(err, fmt.Sprintf("Couldn't upload object to %v/%v.", bucketName, objectKey))
 	}
 
-	return r.GetURL(bucketName, objectKey), uploadOutput.Key, nil
+	return r.GetURL(bucketName, objectKey), uploadOutput.Key, uploadOutput.VersionID, nil
 }
 
-func (r *repositoryImpl) Delete(bucketName string, objectKey string) (err error) {
-	ctx := context.Background()
-	_, cancel := context.WithTimeout(ctx, 50*time.Second)
+func (r *repositoryImpl) Delete(ctx context.Context, bucketName string, objectKey string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Second)
 	defer cancel()
 
-	opts := minio.RemoveObjectOptions{
-		GovernanceBypass: true,
-	}
-	err = r.storeClient.RemoveObject(ctx, bucketName, objectKey, opts)
+	err = r.storeClient.RemoveObject(ctx, bucketName, objectKey, minio.RemoveObjectOptions{})
 	if err != nil {
 		return errors.Wrap(err, fmt.Sprintf("Couldn't delete object %v/%v.", bucketName, objectKey))
 	}
@@ -67,17 +130,179 @@ func (r *repositoryImpl) Delete(bucketName string, objectKey string) (err error)
 	return nil
 }
 
-func (r *repositoryImpl) Get(bucketName string, objectKey string) (url string, err error) {
-	ctx := context.Background()
-	_, cancel := context.WithTimeout(ctx, 50*time.Second)
+// Purge permanently deletes every version and delete marker of the object. bypassGovernance
+// also deletes versions under governance retention and must only be set on privileged admin paths.
+func (r *repositoryImpl) Purge(ctx context.Context, bucketName string, objectKey string, bypassGovernance bool) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Second)
+	defer cancel()
+
+	versions, err := r.ListVersions(ctx, bucketName, objectKey)
+	if err != nil {
+		return err
+	}
+
+	for _, version := range versions {
+		opts := minio.RemoveObjectOptions{
+			VersionID:        version.VersionID,
+			GovernanceBypass: bypassGovernance,
+		}
+		err = r.storeClient.RemoveObject(ctx, bucketName, objectKey, opts)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Couldn't purge object %v/%v version %v.", bucketName, objectKey, version.VersionID))
+		}
+	}
+
+	return nil
+}
+
+// GetRetention returns the object lock state of the object (its latest version when versionID is empty),
+// or nil if the object doesn't exist.
+func (r *repositoryImpl) GetRetention(ctx context.Context, bucketName string, objectKey string, versionID string) (retention *Retention, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Second)
+	defer cancel()
+
+	info, err := r.storeClient.StatObject(ctx, bucketName, objectKey, minio.StatObjectOptions{VersionID: versionID})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, fmt.Sprintf("Couldn't get retention of object %v/%v.", bucketName, objectKey))
+	}
+
+	retention = &Retention{
+		Mode:      minio.RetentionMode(info.Metadata.Get("X-Amz-Object-Lock-Mode")),
+		LegalHold: minio.LegalHoldStatus(info.Metadata.Get("X-Amz-Object-Lock-Legal-Hold")) == minio.LegalHoldEnabled,
+	}
+	if until := info.Metadata.Get("X-Amz-Object-Lock-Retain-Until-Date"); until != "" {
+		retention.RetainUntil, err = time.Parse(time.RFC3339, until)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("Couldn't parse retention of object %v/%v.", bucketName, objectKey))
+		}
+	}
+
+	return retention, nil
+}
+
+// GetVersion returns the URL of a specific version of the object, or "" if that version doesn't exist.
+func (r *repositoryImpl) GetVersion(ctx context.Context, bucketName string, objectKey string, versionID string) (url string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Second)
+	defer cancel()
+
+	info, err := r.storeClient.StatObject(ctx, bucketName, objectKey, minio.StatObjectOptions{VersionID: versionID})
+	if err != nil {
+		if isNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrap(err, fmt.Sprintf("Couldn't get object %v/%v version %v.", bucketName, objectKey, versionID))
+	}
+	if info.IsDeleteMarker {
+		return "", nil
+	}
+
+	return r.GetURL(bucketName, objectKey) + "?versionId=" + neturl.QueryEscape(versionID), nil
+}
+
+// DeleteVersion permanently deletes a single version of the object.
+func (r *repositoryImpl) DeleteVersion(ctx context.Context, bucketName string, objectKey string, versionID string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Second)
+	defer cancel()
+
+	err = r.storeClient.RemoveObject(ctx, bucketName, objectKey, minio.RemoveObjectOptions{VersionID: versionID})
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Couldn't delete object %v/%v version %v.", bucketName, objectKey, versionID))
+	}
+
+	return nil
+}
+
+// ListVersions returns every version and delete marker of the object, newest first.
+func (r *repositoryImpl) ListVersions(ctx context.Context, bucketName string, objectKey string) (versions []ObjectVersion, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for info := range r.storeClient.ListObjects(ctx, bucketName, minio.ListObjectsOptions{Prefix: objectKey, WithVersions: true}) {
+		if info.Err != nil {
+			return nil, errors.Wrap(info.Err, fmt.Sprintf("Couldn't list versions of object %v/%v.", bucketName, objectKey))
+		}
+		if info.Key != objectKey {
+			continue
+		}
+		versions = append(versions, ObjectVersion{
+			VersionID:      info.VersionID,
+			Size:           info.Size,
+			LastModified:   info.LastModified,
+			IsLatest:       info.IsLatest,
+			IsDeleteMarker: info.IsDeleteMarker,
+		})
+	}
+
+	return versions, nil
+}
+
+// RestoreVersion copies an older version over the object, making the copy its new latest version.
+// url is empty if the version doesn't exist.
+func (r *repositoryImpl) RestoreVersion(ctx context.Context, bucketName string, objectKey string, versionID string) (url string, newVersionID string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Second)
+	defer cancel()
+
+	info, err := r.storeClient.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: bucketName, Object: objectKey},
+		minio.CopySrcOptions{Bucket: bucketName, Object: objectKey, VersionID: versionID})
+	if err != nil {
+		if isNotFound(err) {
+			return "", "", nil
+		}
+		return "", "", errors.Wrap(err, fmt.Sprintf("Couldn't restore object %v/%v version %v.", bucketName, objectKey, versionID))
+	}
+
+	return r.GetURL(bucketName, objectKey), info.VersionID, nil
+}
+
+func isNotFound(err error) bool {
+	code := minio.ToErrorResponse(err).Code
+	return code == "NoSuchKey" || code == "NoSuchVersion"
+}
+
+func putObjectOptions(opts UploadOptions) minio.PutObjectOptions {
+	putOpts := minio.PutObjectOptions{
+		ContentType:  opts.ContentType,
+		CacheControl: immutableCacheControl,
+	}
+
+	if opts.Retention.Mode.IsValid() {
+		putOpts.Mode = opts.Retention.Mode
+		putOpts.RetainUntilDate = opts.Retention.RetainUntil
+	}
+	if opts.Retention.LegalHold {
+		putOpts.LegalHold = minio.LegalHoldEnabled
+	}
+	if opts.Checksums != (Checksums{}) {
+		// minio-go sends the Content-MD5 of each request, or of each part of a multipart upload,
+		// which the backend checks the data it receives against
+		putOpts.SendContentMd5 = true
+		putOpts.UserMetadata = map[string]string{
+			checksumSHA256Metadata: hex.EncodeToString(opts.Checksums.SHA256[:]),
+			checksumMD5Metadata:    hex.EncodeToString(opts.Checksums.MD5[:]),
+		}
+	}
+
+	return putOpts
+}
+
+// Get returns the public URL of the object, checked with a HEAD request to it; url is empty if
+// the object isn't found there.
+func (r *repositoryImpl) Get(ctx context.Context, bucketName string, objectKey string) (url string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Second)
 	defer cancel()
 
 	url = r.GetURL(bucketName, objectKey)
 
-	resp, err := r.httpClient.Get(url)
+	resp, err := r.httpClient.Head(ctx, url)
 	if err != nil {
 		return "", errors.Wrap(err, fmt.Sprintf("Couldn't get object %v/%v.", bucketName, objectKey))
 	}
+	defer resp.Body.Close()
+
 	if resp.StatusCode != http.StatusOK {
 		return "", nil
 	}
@@ -88,3 +313,98 @@ func (r *repositoryImpl) Get(bucketName string, objectKey string) (url string, e
 func (r *repositoryImpl) GetURL(bucketName string, objectKey string) string {
 	return "https://" + r.conf.Endpoint + "/" + bucketName + "/" + objectKey
 }
+
+func (r *repositoryImpl) SetAlias(ctx context.Context, bucketName string, alias string, objectKey string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Second)
+	defer cancel()
+
+	opts := minio.PutObjectOptions{
+		UserMetadata: map[string]string{aliasTargetMetadata: objectKey},
+		CacheControl: "no-cache",
+	}
+	_, err = r.storeClient.PutObject(ctx, bucketName, constant.AliasPrefix+alias, bytes.NewReader(nil), 0, opts)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Couldn't point alias %v/%v to %v.", bucketName, alias, objectKey))
+	}
+
+	return nil
+}
+
+func (r *repositoryImpl) ResolveAlias(ctx context.Context, bucketName string, alias string) (key string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Second)
+	defer cancel()
+
+	info, err := r.storeClient.StatObject(ctx, bucketName, constant.AliasPrefix+alias, minio.StatObjectOptions{})
+	if err != nil {
+		if isNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrap(err, fmt.Sprintf("Couldn't resolve alias %v/%v.", bucketName, alias))
+	}
+
+	return info.UserMetadata[aliasTargetMetadata], nil
+}
+
+func (r *repositoryImpl) List(ctx context.Context, bucketName string, prefix string) (objects []StoredObject, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for info := range r.storeClient.ListObjects(ctx, bucketName, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if info.Err != nil {
+			return nil, errors.Wrap(info.Err, fmt.Sprintf("Couldn't list objects in %v/%v.", bucketName, prefix))
+		}
+		objects = append(objects, StoredObject{
+			Key:          info.Key,
+			Size:         info.Size,
+			LastModified: info.LastModified,
+		})
+	}
+
+	return objects, nil
+}
+
+// Trash moves the object to the same key in the private trash bucket. The copy's last-modified
+// time records when it was trashed, which is what the purge job compares the retention against.
+func (r *repositoryImpl) Trash(ctx context.Context, bucketName string, objectKey string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Second)
+	defer cancel()
+
+	err = r.move(ctx, bucketName, objectKey, r.conf.TrashBucketName, objectKey)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Couldn't move object %v/%v to trash.", bucketName, objectKey))
+	}
+
+	return nil
+}
+
+// Restore moves a trashed object back to its original key. url is empty if the object isn't in the trash.
+func (r *repositoryImpl) Restore(ctx context.Context, bucketName string, objectKey string) (url string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Second)
+	defer cancel()
+
+	_, err = r.storeClient.StatObject(ctx, r.conf.TrashBucketName, objectKey, minio.StatObjectOptions{})
+	if err != nil {
+		if isNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrap(err, fmt.Sprintf("Couldn't find object %v/%v in trash.", bucketName, objectKey))
+	}
+
+	err = r.move(ctx, r.conf.TrashBucketName, objectKey, bucketName, objectKey)
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("Couldn't restore object %v/%v from trash.", bucketName, objectKey))
+	}
+
+	return r.GetURL(bucketName, objectKey), nil
+}
+
+func (r *repositoryImpl) move(ctx context.Context, srcBucket string, srcKey string, dstBucket string, dstKey string) error {
+	_, err := r.storeClient.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: dstBucket, Object: dstKey},
+		minio.CopySrcOptions{Bucket: srcBucket, Object: srcKey})
+	if err != nil {
+		return err
+	}
+
+	return r.storeClient.RemoveObject(ctx, srcBucket, srcKey, minio.RemoveObjectOptions{})
+}
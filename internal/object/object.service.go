@@ -2,82 +2,384 @@ package object
 
 import (
 	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"net/http"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	proto "github.com/isd-sgcu/rpkm67-go-proto/rpkm67/store/object/v1"
 	"github.com/isd-sgcu/rpkm67-store/config"
 	"github.com/isd-sgcu/rpkm67-store/constant"
+	"github.com/isd-sgcu/rpkm67-store/internal/cache"
+	"github.com/isd-sgcu/rpkm67-store/internal/catalog"
+	"github.com/isd-sgcu/rpkm67-store/internal/metrics"
+	"github.com/isd-sgcu/rpkm67-store/internal/model"
 	"github.com/isd-sgcu/rpkm67-store/internal/utils"
+	"github.com/isd-sgcu/rpkm67-store/logger"
+	adminProto "github.com/isd-sgcu/rpkm67-store/proto/rpkm67/store/admin/v1"
+	"github.com/minio/minio-go/v7"
 	"go.uber.org/zap"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
 )
 
 type Service interface {
 	proto.ObjectServiceServer
+	adminProto.AdminServiceServer
+	PurgeTrash(ctx context.Context) (purged int, err error)
+	Reload(conf *config.Store)
 }
 
+// trashPurgeLock is the catalog lock held by the replica purging the trash
+const trashPurgeLock = "trash-purge"
+
+var errQuotaExceeded = errors.New("quota exceeded")
+
 type serviceImpl struct {
 	proto.UnimplementedObjectServiceServer
-	conf  *config.Store
-	repo  Repository
-	utils utils.Utils
-	log   *zap.Logger
+	adminProto.UnimplementedAdminServiceServer
+	conf        atomic.Pointer[config.Store]
+	cacheConf   *config.Cache
+	repo        Repository
+	catalogRepo catalog.Repository
+	cacheRepo   cache.Repository
+	lookupGroup singleflight.Group
+	uploadGroup singleflight.Group
+	utils       utils.Utils
+	log         *zap.Logger
+}
+
+func NewService(repo Repository, catalogRepo catalog.Repository, cacheRepo cache.Repository, conf *config.Store, cacheConf *config.Cache, log *zap.Logger, utils utils.Utils) Service {
+	s := &serviceImpl{
+		repo:        repo,
+		catalogRepo: catalogRepo,
+		cacheRepo:   cacheRepo,
+		cacheConf:   cacheConf,
+		utils:       utils,
+		log:         log,
+	}
+	s.conf.Store(conf)
+
+	return s
 }
 
-func NewService(repo Repository, conf *config.Store, log *zap.Logger, utils utils.Utils) Service {
-	return &serviceImpl{
-		repo:  repo,
-		conf:  conf,
-		utils: utils,
-		log:   log,
+// Reload takes over the limits of conf, the maximum file size and the quotas. The swap is
+// atomic, so calls in flight see either the old or the new limits. gRPC keeps rejecting messages
+// over the file size the server started with, so raising the maximum past it needs a restart.
+func (s *serviceImpl) Reload(conf *config.Store) {
+	next := *s.conf.Load()
+	next.MaxFileSize = conf.MaxFileSize
+	next.Quotas = conf.Quotas
+	s.conf.Store(&next)
+}
+
+// logger returns the service logger named after op and annotated with the request scope of ctx.
+func (s *serviceImpl) logger(ctx context.Context, op string) *zap.Logger {
+	return logger.FromContext(ctx, s.log).Named(op)
+}
+
+func (s *serviceImpl) Upload(ctx context.Context, req *proto.UploadObjectRequest) (*proto.UploadObjectResponse, error) {
+	if maxFileSize := s.conf.Load().MaxFileSize; maxFileSize > 0 && int64(len(req.Data)) > maxFileSize {
+		s.logger(ctx, "Upload").Warn("File too large", zap.Int("size", len(req.Data)), zap.Int64("max_size", maxFileSize))
+		return nil, newTooLargeError("data")
+	}
+
+	checksums := computeChecksums(req.Data)
+	if err := verifyChecksums(ctx, checksums); err != nil {
+		s.logger(ctx, "Upload").Warn("Checksum verification failed", zap.Error(err))
+		return nil, err
 	}
+
+	result, err := s.uploadOnce(ctx, req, checksums)
+	if err != nil {
+		return nil, err
+	}
+	utils.SetHeaderValue(ctx, constant.VersionIdMetadataKey, result.VersionID)
+	setChecksumHeaders(ctx, checksums)
+
+	return &proto.UploadObjectResponse{
+		Object: &proto.Object{
+			Url: result.Url,
+			Key: result.Key,
+		},
+	}, nil
+}
+
+// uploadResult is what an upload with an idempotency key is remembered as, to answer its retries.
+type uploadResult struct {
+	Checksum  string
+	Url       string
+	Key       string
+	VersionID string
+}
+
+func idempotencyGroupKey(owner string, key string) string {
+	return owner + ":" + key
+}
+
+// uploadOnce uploads req, unless the caller's idempotency key was already used within the
+// window: then the earlier result is returned if the data is the same, and a conflict if it
+// isn't. The key is reserved in the catalog before any data is sent, so only one upload runs
+// per key across replicas; a retry arriving while it's still in progress gets a conflict.
+// Concurrent calls with one key on the same replica share the upload, which therefore isn't
+// cancelled along with the caller that started it, so a retry after a timeout still finds it.
+// Keys belong to the x-user-id of the call; calls without one ignore the key, as every
+// anonymous caller would otherwise share the same keys.
+func (s *serviceImpl) uploadOnce(ctx context.Context, req *proto.UploadObjectRequest, checksums Checksums) (*uploadResult, error) {
+	idempotencyKey := utils.GetMetadataValue(ctx, constant.IdempotencyKeyMetadataKey)
+	owner := utils.GetMetadataValue(ctx, constant.UserIdMetadataKey)
+	window := s.conf.Load().IdempotencyWindow
+	if idempotencyKey == "" || owner == "" || window <= 0 {
+		return s.upload(ctx, req, checksums)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	v, err, _ := s.uploadGroup.Do(idempotencyGroupKey(owner, idempotencyKey), func() (interface{}, error) {
+		return s.reserveAndUpload(ctx, req, checksums, &model.IdempotencyKey{
+			Owner:     owner,
+			Key:       idempotencyKey,
+			Checksum:  hex.EncodeToString(checksums.SHA256[:]),
+			Pending:   true,
+			ExpiresAt: time.Now().Add(window),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := v.(*uploadResult)
+	if result.Checksum != hex.EncodeToString(checksums.SHA256[:]) {
+		s.logger(ctx, "Upload").Warn("Idempotency key reused for different data", zap.String("idempotency_key", idempotencyKey), zap.String("key", result.Key))
+		return nil, newConflictError(constant.IdempotencyKeyReusedErrorMessage)
+	}
+
+	return result, nil
+}
+
+// reserveAndUpload uploads req once it holds record's key, or returns the upload that holds it.
+func (s *serviceImpl) reserveAndUpload(ctx context.Context, req *proto.UploadObjectRequest, checksums Checksums, record *model.IdempotencyKey) (*uploadResult, error) {
+	reserved, err := s.catalogRepo.ReserveIdempotencyKey(record)
+	if err != nil {
+		s.logger(ctx, "Upload").Error("ReserveIdempotencyKey: ", zap.Error(err))
+		return nil, newInternalError(err)
+	}
+	if !reserved {
+		existing := &model.IdempotencyKey{}
+		if err := s.catalogRepo.FindIdempotencyKey(record.Owner, record.Key, existing); err != nil {
+			s.logger(ctx, "Upload").Error("FindIdempotencyKey: ", zap.Error(err))
+			return nil, newInternalError(err)
+		}
+		if existing.Pending && existing.Checksum == record.Checksum {
+			return nil, newConflictError(constant.IdempotencyKeyInProgressErrorMessage)
+		}
+
+		return &uploadResult{Checksum: existing.Checksum, Url: existing.Url, Key: existing.ObjectKey, VersionID: existing.VersionID}, nil
+	}
+
+	result, err := s.upload(ctx, req, checksums)
+	if err != nil {
+		if err := s.catalogRepo.ReleaseIdempotencyKey(record.Owner, record.Key); err != nil {
+			// the key stays reserved until it expires
+			s.logger(ctx, "Upload").Error("ReleaseIdempotencyKey: ", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	record.Url, record.ObjectKey, record.VersionID = result.Url, result.Key, result.VersionID
+	record.ExpiresAt = time.Now().Add(s.conf.Load().IdempotencyWindow)
+	if err := s.catalogRepo.CompleteIdempotencyKey(record); err != nil {
+		// the object is stored; retries are told the upload is in progress until the key expires
+		s.logger(ctx, "Upload").Error("CompleteIdempotencyKey: ", zap.Error(err))
+	}
+
+	return result, nil
 }
 
-func (s *serviceImpl) Upload(_ context.Context, req *proto.UploadObjectRequest) (*proto.UploadObjectResponse, error) {
+func (s *serviceImpl) upload(ctx context.Context, req *proto.UploadObjectRequest, checksums Checksums) (*uploadResult, error) {
 	randomString, err := s.utils.GenerateRandomString(10)
 	if err != nil {
-		s.log.Named("Upload").Error("GenerateRandomString: ", zap.Error(err))
-		return nil, status.Error(codes.Internal, constant.InternalServerErrorMessage)
+		s.logger(ctx, "Upload").Error("GenerateRandomString: ", zap.Error(err))
+		return nil, newInternalError(err)
 	}
 
 	ext := filepath.Ext(req.Filename)
 	filename := strings.TrimSuffix(req.Filename, ext)
 	objectKey := filename + "_" + randomString + ext
 
-	url, key, err := s.repo.Upload(req.Data, s.conf.BucketName, objectKey)
+	record := &model.Object{
+		Bucket:      s.conf.Load().BucketName,
+		Key:         objectKey,
+		Owner:       utils.GetMetadataValue(ctx, constant.UserIdMetadataKey),
+		Category:    getCategory(ctx),
+		Size:        int64(len(req.Data)),
+		ContentType: http.DetectContentType(req.Data),
+		Checksum:    hex.EncodeToString(checksums.SHA256[:]),
+	}
+
+	// the catalog entry and the quota are reserved in a short transaction of their own, so no
+	// connection or usage row lock is held while the data is sent to the bucket; the entry stays
+	// pending, invisible to lookups, until the object is stored
+	record.Pending = true
+	err = s.catalogRepo.WithTransaction(func(catalogRepo catalog.Repository) error {
+		if err := catalogRepo.Create(record); err != nil {
+			return err
+		}
+		return s.reserveQuota(catalogRepo, record)
+	})
+	if errors.Is(err, errQuotaExceeded) {
+		s.logger(ctx, "Upload").Warn("Quota exceeded", zap.String("owner", record.Owner), zap.String("category", record.Category))
+		return nil, newQuotaExceededError(record.Owner, record.Category)
+	}
 	if err != nil {
-		s.log.Named("Upload").Error("Upload: ", zap.Error(err))
-		return nil, status.Error(codes.Internal, constant.InternalServerErrorMessage)
+		s.logger(ctx, "Upload").Error("Create: ", zap.Error(err))
+		return nil, newInternalError(err)
 	}
 
-	return &proto.UploadObjectResponse{
+	result := &uploadResult{Checksum: record.Checksum}
+	result.Url, result.Key, result.VersionID, err = s.repo.Upload(ctx, req.Data, s.conf.Load().BucketName, objectKey, UploadOptions{
+		ContentType: record.ContentType,
+		Retention:   s.retentionFor(record.Category),
+		Checksums:   checksums,
+	})
+	if err != nil {
+		s.logger(ctx, "Upload").Error("Upload: ", zap.Error(err))
+		s.releaseReservation(ctx, record)
+		return nil, newInternalError(err)
+	}
+
+	if err := s.catalogRepo.Commit(record.Bucket, record.Key); err != nil {
+		s.logger(ctx, "Upload").Error("Commit: ", zap.Error(err))
+		s.discardUpload(ctx, record, result.VersionID)
+		return nil, newInternalError(err)
+	}
+	s.invalidateCache(objectCacheKey(result.Key))
+	metrics.ObserveUpload(record.Category, record.Size)
+
+	return result, nil
+}
+
+// discardUpload removes an object stored for an upload that failed afterwards, then releases its
+// reservation. Objects that can't be removed, e.g. under compliance retention, are left to the
+// orphan reconciliation.
+func (s *serviceImpl) discardUpload(ctx context.Context, record *model.Object, versionID string) {
+	var err error
+	if versionID != "" {
+		err = s.repo.DeleteVersion(ctx, record.Bucket, record.Key, versionID)
+	} else {
+		err = s.repo.Delete(ctx, record.Bucket, record.Key)
+	}
+	if err != nil {
+		s.logger(ctx, "Upload").Error("Discard: ", zap.String("key", record.Key), zap.Error(err))
+	}
+
+	s.releaseReservation(ctx, record)
+}
+
+// releaseReservation drops the pending catalog entry of a failed upload and gives its quota back.
+func (s *serviceImpl) releaseReservation(ctx context.Context, record *model.Object) {
+	err := s.catalogRepo.WithTransaction(func(catalogRepo catalog.Repository) error {
+		if err := catalogRepo.Purge(record.Bucket, record.Key); err != nil {
+			return err
+		}
+		if record.Owner == "" {
+			return nil
+		}
+
+		_, err := catalogRepo.IncrementUsage(record.Owner, record.Category, -record.Size, -1, 0, 0)
+		return err
+	})
+	if err != nil {
+		s.logger(ctx, "Upload").Error("Release: ", zap.String("key", record.Key), zap.Error(err))
+	}
+}
+
+func (s *serviceImpl) FindByKey(ctx context.Context, req *proto.FindByKeyObjectRequest) (*proto.FindByKeyObjectResponse, error) {
+	if req.Key == "" {
+		s.logger(ctx, "FindByKey").Warn("Key is empty")
+		return nil, newInvalidArgumentError("key", constant.KeyEmptyErrorMessage)
+	}
+
+	if versionID := utils.GetMetadataValue(ctx, constant.VersionIdMetadataKey); versionID != "" {
+		return s.findVersion(ctx, req.Key, versionID)
+	}
+
+	result, err := s.findObject(ctx, req.Key)
+	if err != nil {
+		s.logger(ctx, "FindByKey").Error("findObject: ", zap.Error(err))
+		return nil, newInternalError(err)
+	}
+	if !result.Found {
+		s.logger(ctx, "FindByKey").Debug(fmt.Sprintf("Object with key %v not found", req.Key))
+		return nil, newNotFoundError()
+	}
+
+	return &proto.FindByKeyObjectResponse{
+		Object: &proto.Object{
+			Url: result.Url,
+			Key: result.Key,
+		},
+	}, nil
+}
+
+func (s *serviceImpl) findVersion(ctx context.Context, key string, versionID string) (*proto.FindByKeyObjectResponse, error) {
+	target, err := s.resolveAlias(ctx, key)
+	if err != nil {
+		s.logger(ctx, "FindByKey").Error("resolveAlias: ", zap.Error(err))
+		return nil, newInternalError(err)
+	}
+
+	url, err := s.repo.GetVersion(ctx, s.conf.Load().BucketName, target, versionID)
+	if err != nil {
+		s.logger(ctx, "FindByKey").Error("GetVersion: ", zap.Error(err))
+		return nil, newInternalError(err)
+	}
+	if url == "" {
+		s.logger(ctx, "FindByKey").Debug(fmt.Sprintf("Object with key %v and version %v not found", key, versionID))
+		return nil, newNotFoundError()
+	}
+
+	return &proto.FindByKeyObjectResponse{
 		Object: &proto.Object{
 			Url: url,
-			Key: key,
+			Key: target,
 		},
 	}, nil
 }
 
-func (s *serviceImpl) FindByKey(_ context.Context, req *proto.FindByKeyObjectRequest) (*proto.FindByKeyObjectResponse, error) {
+// SetAlias points an alias at an existing object, replacing any previous target.
+// FindByKey called with the alias then returns the object it currently points to.
+func (s *serviceImpl) SetAlias(ctx context.Context, req *adminProto.SetAliasRequest) (*adminProto.SetAliasResponse, error) {
+	if req.Alias == "" {
+		s.logger(ctx, "SetAlias").Warn("Alias is empty")
+		return nil, newInvalidArgumentError("alias", constant.AliasEmptyErrorMessage)
+	}
 	if req.Key == "" {
-		s.log.Named("FindByKey").Error("Key is empty")
-		return nil, status.Error(codes.InvalidArgument, constant.KeyEmptyErrorMessage)
+		s.logger(ctx, "SetAlias").Warn("Key is empty")
+		return nil, newInvalidArgumentError("key", constant.KeyEmptyErrorMessage)
 	}
 
-	url, err := s.repo.Get(s.conf.BucketName, req.Key)
+	url, err := s.repo.Get(ctx, s.conf.Load().BucketName, req.Key)
 	if err != nil {
-		s.log.Named("FindByKey").Error("Get: ", zap.Error(err))
-		return nil, status.Error(codes.Internal, constant.InternalServerErrorMessage)
+		s.logger(ctx, "SetAlias").Error("Get: ", zap.Error(err))
+		return nil, newInternalError(err)
 	}
 	if url == "" {
-		s.log.Named("FindByKey").Error(fmt.Sprintf("Object with key %v not found", req.Key))
-		return nil, status.Error(codes.NotFound, constant.ObjectNotFoundErrorMessage)
+		s.logger(ctx, "SetAlias").Debug(fmt.Sprintf("Object with key %v not found", req.Key))
+		return nil, newNotFoundError()
 	}
 
-	return &proto.FindByKeyObjectResponse{
+	if err := s.repo.SetAlias(ctx, s.conf.Load().BucketName, req.Alias, req.Key); err != nil {
+		s.logger(ctx, "SetAlias").Error("SetAlias: ", zap.Error(err))
+		return nil, newInternalError(err)
+	}
+	s.invalidateCache(aliasCacheKey(req.Alias))
+
+	return &adminProto.SetAliasResponse{
 		Object: &proto.Object{
 			Url: url,
 			Key: req.Key,
@@ -85,27 +387,720 @@ func (s *serviceImpl) FindByKey(_ context.Context, req *proto.FindByKeyObjectReq
 	}, nil
 }
 
-func (s *serviceImpl) DeleteByKey(_ context.Context, req *proto.DeleteByKeyObjectRequest) (*proto.DeleteByKeyObjectResponse, error) {
+func (s *serviceImpl) DeleteByKey(ctx context.Context, req *proto.DeleteByKeyObjectRequest) (*proto.DeleteByKeyObjectResponse, error) {
 	if req.Key == "" {
-		s.log.Named("DeleteByKey").Error("Key is empty")
+		s.logger(ctx, "DeleteByKey").Warn("Key is empty")
+		return &proto.DeleteByKeyObjectResponse{
+			Success: false,
+		}, newInvalidArgumentError("key", constant.KeyEmptyErrorMessage)
+	}
+
+	if versionID := utils.GetMetadataValue(ctx, constant.VersionIdMetadataKey); versionID != "" {
+		return s.deleteVersion(ctx, req.Key, versionID)
+	}
+
+	// deleting a missing object succeeds, like the storage backend's own delete
+	if s.conf.Load().HardDelete {
+		if _, err := s.purge(ctx, "DeleteByKey", req.Key, false); err != nil {
+			return &proto.DeleteByKeyObjectResponse{
+				Success: false,
+			}, err
+		}
+
+		return &proto.DeleteByKeyObjectResponse{
+			Success: true,
+		}, nil
+	}
+
+	found, err := s.checkRetention(ctx, "DeleteByKey", req.Key, "", false)
+	if err != nil {
+		return &proto.DeleteByKeyObjectResponse{
+			Success: false,
+		}, err
+	}
+	if !found {
+		return &proto.DeleteByKeyObjectResponse{
+			Success: true,
+		}, nil
+	}
+
+	// the entry is hidden in a short transaction of its own, so no usage row lock is held while
+	// the object is moved; it is brought back if the move fails
+	hidden, err := s.hideEntry(req.Key)
+	if err != nil {
+		s.logger(ctx, "DeleteByKey").Error("Delete: ", zap.Error(err))
+		return &proto.DeleteByKeyObjectResponse{
+			Success: false,
+		}, newInternalError(err)
+	}
+
+	if err := s.repo.Trash(ctx, s.conf.Load().BucketName, req.Key); err != nil {
+		s.logger(ctx, "DeleteByKey").Error("Trash: ", zap.Error(err))
+		if hidden {
+			s.unhideEntry(ctx, "DeleteByKey", req.Key)
+		}
+		return &proto.DeleteByKeyObjectResponse{
+			Success: false,
+		}, newInternalError(err)
+	}
+	s.invalidateCache(objectCacheKey(req.Key))
+
+	return &proto.DeleteByKeyObjectResponse{
+		Success: true,
+	}, nil
+}
+
+// deleteVersion permanently removes a single version; the trash doesn't apply since
+// older versions are already the bucket's own history. Deleting the current version makes
+// the next one current, so the catalog entry and usage follow it.
+func (s *serviceImpl) deleteVersion(ctx context.Context, key string, versionID string) (*proto.DeleteByKeyObjectResponse, error) {
+	found, err := s.checkRetention(ctx, "DeleteByKey", key, versionID, false)
+	if err != nil {
 		return &proto.DeleteByKeyObjectResponse{
 			Success: false,
-		}, status.Error(codes.InvalidArgument, constant.KeyEmptyErrorMessage)
+		}, err
+	}
+	if !found {
+		return &proto.DeleteByKeyObjectResponse{
+			Success: true,
+		}, nil
 	}
 
-	err := s.repo.Delete(s.conf.BucketName, req.Key)
+	versions, err := s.repo.ListVersions(ctx, s.conf.Load().BucketName, key)
 	if err != nil {
-		s.log.Named("DeleteByKey").Error("Delete: ", zap.Error(err))
+		s.logger(ctx, "DeleteByKey").Error("ListVersions: ", zap.Error(err))
+		return &proto.DeleteByKeyObjectResponse{
+			Success: false,
+		}, newInternalError(err)
+	}
+
+	// the catalog follows the version becoming current in a short transaction of its own, so no
+	// usage row lock is held while the version is deleted, and goes back if the delete fails
+	current, changed := currentAfterDelete(versions, versionID)
+	if changed {
+		err = s.catalogRepo.WithTransaction(func(catalogRepo catalog.Repository) error {
+			return s.syncCatalog(catalogRepo, key, current)
+		})
+		if err != nil {
+			s.logger(ctx, "DeleteByKey").Error("Sync: ", zap.Error(err))
+			return &proto.DeleteByKeyObjectResponse{
+				Success: false,
+			}, newInternalError(err)
+		}
+	}
+
+	if err := s.repo.DeleteVersion(ctx, s.conf.Load().BucketName, key, versionID); err != nil {
+		s.logger(ctx, "DeleteByKey").Error("DeleteVersion: ", zap.Error(err))
+		if changed {
+			s.resyncCatalog(ctx, "DeleteByKey", key, latestVersion(versions))
+		}
 		return &proto.DeleteByKeyObjectResponse{
 			Success: false,
-		}, status.Error(codes.Internal, constant.InternalServerErrorMessage)
+		}, newInternalError(err)
+	}
+
+	// no version is left, so the entry deleted above goes for good
+	if changed && current == nil {
+		if err := s.catalogRepo.Purge(s.conf.Load().BucketName, key); err != nil {
+			s.logger(ctx, "DeleteByKey").Error("Purge catalog: ", zap.String("key", key), zap.Error(err))
+		}
 	}
+	s.invalidateCache(objectCacheKey(key))
 
 	return &proto.DeleteByKeyObjectResponse{
 		Success: true,
 	}, nil
 }
 
+// currentAfterDelete returns the version that becomes current once versionID is deleted from
+// versions (newest first), nil if none is left, and whether the current version changes at all.
+func currentAfterDelete(versions []ObjectVersion, versionID string) (current *ObjectVersion, changed bool) {
+	for i, version := range versions {
+		if version.VersionID != versionID {
+			continue
+		}
+		if !version.IsLatest {
+			return nil, false
+		}
+		if i+1 < len(versions) {
+			return &versions[i+1], true
+		}
+		return nil, true
+	}
+
+	return nil, false
+}
+
+// latestVersion returns the current version among versions, nil if there is none.
+func latestVersion(versions []ObjectVersion) *ObjectVersion {
+	for i := range versions {
+		if versions[i].IsLatest {
+			return &versions[i]
+		}
+	}
+
+	return nil
+}
+
+// syncCatalog moves the key's catalog entry and its owner's usage over to current, the version
+// now current in the bucket. A delete marker or nil deletes the entry.
+func (s *serviceImpl) syncCatalog(catalogRepo catalog.Repository, key string, current *ObjectVersion) error {
+	if err := s.adjustUsage(catalogRepo, key, -1); err != nil {
+		return err
+	}
+
+	if current == nil || current.IsDeleteMarker {
+		return catalogRepo.Delete(s.conf.Load().BucketName, key)
+	}
+
+	if err := catalogRepo.Restore(s.conf.Load().BucketName, key); err != nil {
+		return err
+	}
+	if err := catalogRepo.Resize(s.conf.Load().BucketName, key, current.Size); err != nil {
+		return err
+	}
+
+	return s.adjustUsage(catalogRepo, key, 1)
+}
+
+// resyncCatalog moves the catalog back to current after the bucket operation it was synced
+// ahead of failed.
+func (s *serviceImpl) resyncCatalog(ctx context.Context, method string, key string, current *ObjectVersion) {
+	err := s.catalogRepo.WithTransaction(func(catalogRepo catalog.Repository) error {
+		return s.syncCatalog(catalogRepo, key, current)
+	})
+	if err != nil {
+		s.logger(ctx, method).Error("Resync: ", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// ListVersions returns the versions and delete markers of an object in a versioned bucket, newest first.
+func (s *serviceImpl) ListVersions(ctx context.Context, req *adminProto.ListVersionsRequest) (*adminProto.ListVersionsResponse, error) {
+	if req.Key == "" {
+		s.logger(ctx, "ListVersions").Warn("Key is empty")
+		return nil, newInvalidArgumentError("key", constant.KeyEmptyErrorMessage)
+	}
+
+	versions, err := s.repo.ListVersions(ctx, s.conf.Load().BucketName, req.Key)
+	if err != nil {
+		s.logger(ctx, "ListVersions").Error("ListVersions: ", zap.Error(err))
+		return nil, newInternalError(err)
+	}
+	if len(versions) == 0 {
+		s.logger(ctx, "ListVersions").Debug(fmt.Sprintf("Object with key %v not found", req.Key))
+		return nil, newNotFoundError()
+	}
+
+	resp := &adminProto.ListVersionsResponse{
+		Versions: make([]*adminProto.ObjectVersion, 0, len(versions)),
+	}
+	for _, version := range versions {
+		resp.Versions = append(resp.Versions, &adminProto.ObjectVersion{
+			VersionId:      version.VersionID,
+			Size:           version.Size,
+			LastModified:   timestamppb.New(version.LastModified),
+			IsLatest:       version.IsLatest,
+			IsDeleteMarker: version.IsDeleteMarker,
+		})
+	}
+
+	return resp, nil
+}
+
+// RestoreVersion makes an older version the current one by copying it over the object. The owner's
+// usage follows the size of the restored version; like Restore, it is never refused for quota.
+func (s *serviceImpl) RestoreVersion(ctx context.Context, req *adminProto.RestoreVersionRequest) (*adminProto.RestoreVersionResponse, error) {
+	if req.Key == "" {
+		s.logger(ctx, "RestoreVersion").Warn("Key is empty")
+		return nil, newInvalidArgumentError("key", constant.KeyEmptyErrorMessage)
+	}
+	if req.VersionId == "" {
+		s.logger(ctx, "RestoreVersion").Warn("Version ID is empty")
+		return nil, newInvalidArgumentError("version_id", constant.VersionIdEmptyErrorMessage)
+	}
+
+	versions, err := s.repo.ListVersions(ctx, s.conf.Load().BucketName, req.Key)
+	if err != nil {
+		s.logger(ctx, "RestoreVersion").Error("ListVersions: ", zap.Error(err))
+		return nil, newInternalError(err)
+	}
+	var source *ObjectVersion
+	for i := range versions {
+		if versions[i].VersionID == req.VersionId && !versions[i].IsDeleteMarker {
+			source = &versions[i]
+		}
+	}
+	if source == nil {
+		s.logger(ctx, "RestoreVersion").Debug(fmt.Sprintf("Object with key %v and version %v not found", req.Key, req.VersionId))
+		return nil, newNotFoundError()
+	}
+
+	// the copy of source becomes the current version; the catalog follows it in a short
+	// transaction of its own and goes back if the copy can't be made
+	err = s.catalogRepo.WithTransaction(func(catalogRepo catalog.Repository) error {
+		return s.syncCatalog(catalogRepo, req.Key, source)
+	})
+	if err != nil {
+		s.logger(ctx, "RestoreVersion").Error("Sync: ", zap.Error(err))
+		return nil, newInternalError(err)
+	}
+
+	url, newVersionID, err := s.repo.RestoreVersion(ctx, s.conf.Load().BucketName, req.Key, req.VersionId)
+	if err != nil {
+		s.logger(ctx, "RestoreVersion").Error("RestoreVersion: ", zap.Error(err))
+		s.resyncCatalog(ctx, "RestoreVersion", req.Key, latestVersion(versions))
+		return nil, newInternalError(err)
+	}
+	if url == "" {
+		s.logger(ctx, "RestoreVersion").Debug(fmt.Sprintf("Object with key %v and version %v not found", req.Key, req.VersionId))
+		s.resyncCatalog(ctx, "RestoreVersion", req.Key, latestVersion(versions))
+		return nil, newNotFoundError()
+	}
+	s.invalidateCache(objectCacheKey(req.Key))
+
+	return &adminProto.RestoreVersionResponse{
+		Object: &proto.Object{
+			Url: url,
+			Key: req.Key,
+		},
+		VersionId: newVersionID,
+	}, nil
+}
+
+// ForceDelete permanently deletes every version of an object, bypassing governance retention.
+// Compliance retention and legal holds are still honoured, and nothing is deleted if any version is locked.
+func (s *serviceImpl) ForceDelete(ctx context.Context, req *adminProto.ForceDeleteObjectRequest) (*adminProto.ForceDeleteObjectResponse, error) {
+	if req.Key == "" {
+		s.logger(ctx, "ForceDelete").Warn("Key is empty")
+		return &adminProto.ForceDeleteObjectResponse{
+			Success: false,
+		}, newInvalidArgumentError("key", constant.KeyEmptyErrorMessage)
+	}
+
+	found, err := s.purge(ctx, "ForceDelete", req.Key, true)
+	if err != nil {
+		return &adminProto.ForceDeleteObjectResponse{
+			Success: false,
+		}, err
+	}
+	if !found {
+		s.logger(ctx, "ForceDelete").Debug(fmt.Sprintf("Object with key %v not found", req.Key))
+		return &adminProto.ForceDeleteObjectResponse{
+			Success: false,
+		}, newNotFoundError()
+	}
+
+	s.logger(ctx, "ForceDelete").Warn("Object deleted bypassing governance retention", zap.String("key", req.Key))
+
+	return &adminProto.ForceDeleteObjectResponse{
+		Success: true,
+	}, nil
+}
+
+// purge permanently deletes every version of key together with its catalog entry. Nothing is
+// deleted if any version is retained; found is false if the key has no versions at all.
+func (s *serviceImpl) purge(ctx context.Context, method string, key string, bypassGovernance bool) (found bool, err error) {
+	versions, err := s.repo.ListVersions(ctx, s.conf.Load().BucketName, key)
+	if err != nil {
+		s.logger(ctx, method).Error("ListVersions: ", zap.Error(err))
+		return false, newInternalError(err)
+	}
+	if len(versions) == 0 {
+		return false, nil
+	}
+	for _, version := range versions {
+		if version.IsDeleteMarker {
+			continue
+		}
+		if _, err := s.checkRetention(ctx, method, key, version.VersionID, bypassGovernance); err != nil {
+			return true, err
+		}
+	}
+
+	hidden, err := s.hideEntry(key)
+	if err != nil {
+		s.logger(ctx, method).Error("Delete: ", zap.Error(err))
+		return true, newInternalError(err)
+	}
+
+	if err := s.repo.Purge(ctx, s.conf.Load().BucketName, key, bypassGovernance); err != nil {
+		s.logger(ctx, method).Error("Purge: ", zap.Error(err))
+		if hidden {
+			s.unhideEntry(ctx, method, key)
+		}
+		return true, newInternalError(err)
+	}
+
+	// the object is gone either way; an entry left behind is only ever seen as deleted
+	if err := s.catalogRepo.Purge(s.conf.Load().BucketName, key); err != nil {
+		s.logger(ctx, method).Error("Purge catalog: ", zap.String("key", key), zap.Error(err))
+	}
+	s.invalidateCache(objectCacheKey(key))
+
+	return true, nil
+}
+
+// checkRetention reports whether key (or one version of it) exists, and returns the error to
+// reply with if it cannot be deleted.
+func (s *serviceImpl) checkRetention(ctx context.Context, method string, key string, versionID string, bypassGovernance bool) (found bool, err error) {
+	retention, err := s.repo.GetRetention(ctx, s.conf.Load().BucketName, key, versionID)
+	if err != nil {
+		s.logger(ctx, method).Error("GetRetention: ", zap.Error(err))
+		return false, newInternalError(err)
+	}
+	if retention == nil {
+		s.logger(ctx, method).Debug(fmt.Sprintf("Object with key %v not found", key))
+		return false, nil
+	}
+	if retention.Locked(time.Now(), bypassGovernance) {
+		s.logger(ctx, method).Warn(fmt.Sprintf("Object with key %v is under retention", key))
+		return true, newRetainedError(key)
+	}
+
+	return true, nil
+}
+
+func (s *serviceImpl) retentionFor(category string) Retention {
+	policy, ok := s.conf.Load().Retention[category]
+	if !ok {
+		return Retention{}
+	}
+
+	return Retention{
+		Mode:        minio.RetentionMode(policy.Mode),
+		RetainUntil: time.Now().Add(policy.Period),
+		LegalHold:   policy.LegalHold,
+	}
+}
+
+// Restore moves a deleted object back out of the trash, as long as it hasn't been purged yet.
+func (s *serviceImpl) Restore(ctx context.Context, req *adminProto.RestoreObjectRequest) (*adminProto.RestoreObjectResponse, error) {
+	if req.Key == "" {
+		s.logger(ctx, "Restore").Warn("Key is empty")
+		return nil, newInvalidArgumentError("key", constant.KeyEmptyErrorMessage)
+	}
+
+	url, err := s.repo.Restore(ctx, s.conf.Load().BucketName, req.Key)
+	if err != nil {
+		s.logger(ctx, "Restore").Error("Restore: ", zap.Error(err))
+		return nil, newInternalError(err)
+	}
+	if url == "" {
+		s.logger(ctx, "Restore").Debug(fmt.Sprintf("Object with key %v not found in trash", req.Key))
+		return nil, newNotFoundError()
+	}
+
+	// the object is moved first so that nothing changes when it isn't in the trash, and moved
+	// back if its entry can't be restored
+	err = s.catalogRepo.WithTransaction(func(catalogRepo catalog.Repository) error {
+		if err := catalogRepo.Restore(s.conf.Load().BucketName, req.Key); err != nil {
+			return err
+		}
+
+		// restoring is never refused for quota, the object was already accounted for once
+		return s.adjustUsage(catalogRepo, req.Key, 1)
+	})
+	if err != nil {
+		s.logger(ctx, "Restore").Error("Restore catalog: ", zap.Error(err))
+		if err := s.repo.Trash(ctx, s.conf.Load().BucketName, req.Key); err != nil {
+			s.logger(ctx, "Restore").Error("Trash: ", zap.String("key", req.Key), zap.Error(err))
+		}
+		return nil, newInternalError(err)
+	}
+	s.invalidateCache(objectCacheKey(req.Key))
+
+	return &adminProto.RestoreObjectResponse{
+		Object: &proto.Object{
+			Url: url,
+			Key: req.Key,
+		},
+	}, nil
+}
+
+// PurgeTrash permanently removes trashed objects older than the configured retention,
+// together with their catalog entries. Every replica calls it, but only the one that gets
+// the lock purges; the others return at once with nothing purged.
+func (s *serviceImpl) PurgeTrash(ctx context.Context) (purged int, err error) {
+	_, err = s.catalogRepo.TryLock(trashPurgeLock, func() error {
+		purged, err = s.purgeTrash(ctx)
+		return err
+	})
+
+	return purged, err
+}
+
+func (s *serviceImpl) purgeTrash(ctx context.Context) (purged int, err error) {
+	trashed, err := s.repo.List(ctx, s.conf.Load().TrashBucketName, "")
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-s.conf.Load().TrashRetention)
+	for _, obj := range trashed {
+		if obj.LastModified.After(cutoff) {
+			continue
+		}
+
+		if err := s.repo.Purge(ctx, s.conf.Load().TrashBucketName, obj.Key, false); err != nil {
+			s.logger(ctx, "PurgeTrash").Error("Purge: ", zap.String("key", obj.Key), zap.Error(err))
+			continue
+		}
+		purged++
+
+		// the entry of a trashed object is already deleted, so one left behind is never seen
+		if err := s.catalogRepo.Purge(s.conf.Load().BucketName, obj.Key); err != nil {
+			s.logger(ctx, "PurgeTrash").Error("Purge catalog: ", zap.String("key", obj.Key), zap.Error(err))
+		}
+	}
+
+	return purged, nil
+}
+
+// GetUsage returns the owner's storage per category. owner defaults to the x-user-id of the caller.
+func (s *serviceImpl) GetUsage(ctx context.Context, req *adminProto.GetUsageRequest) (*adminProto.GetUsageResponse, error) {
+	owner := req.Owner
+	if owner == "" {
+		owner = utils.GetMetadataValue(ctx, constant.UserIdMetadataKey)
+	}
+	if owner == "" {
+		s.logger(ctx, "GetUsage").Warn("Owner is empty")
+		return nil, newInvalidArgumentError("owner", constant.OwnerEmptyErrorMessage)
+	}
+
+	var records []*model.Usage
+	if err := s.catalogRepo.FindUsage(owner, &records); err != nil {
+		s.logger(ctx, "GetUsage").Error("FindUsage: ", zap.Error(err))
+		return nil, newInternalError(err)
+	}
+
+	resp := &adminProto.GetUsageResponse{
+		Usages: make([]*adminProto.Usage, 0, len(records)),
+	}
+	for _, record := range records {
+		quota := s.quotaFor(record.Category)
+		resp.Usages = append(resp.Usages, &adminProto.Usage{
+			Category:   record.Category,
+			Bytes:      record.Bytes,
+			Objects:    record.Objects,
+			MaxBytes:   quota.MaxBytes,
+			MaxObjects: quota.MaxObjects,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *serviceImpl) quotaFor(category string) config.Quota {
+	quotas := s.conf.Load().Quotas
+	if quota, ok := quotas[category]; ok {
+		return quota
+	}
+
+	return quotas["*"]
+}
+
+// reserveQuota charges the new object to its owner, failing with errQuotaExceeded if it doesn't fit.
+// Uploads without an owner aren't subject to quotas.
+func (s *serviceImpl) reserveQuota(catalogRepo catalog.Repository, record *model.Object) error {
+	if record.Owner == "" {
+		return nil
+	}
+
+	quota := s.quotaFor(record.Category)
+	ok, err := catalogRepo.IncrementUsage(record.Owner, record.Category, record.Size, 1, quota.MaxBytes, quota.MaxObjects)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errQuotaExceeded
+	}
+
+	return nil
+}
+
+// adjustUsage adds (sign 1) or removes (sign -1) the cataloged object from its owner's usage.
+// Objects that predate the catalog or have no owner aren't tracked.
+func (s *serviceImpl) adjustUsage(catalogRepo catalog.Repository, key string, sign int64) error {
+	record := &model.Object{}
+	err := catalogRepo.FindByKey(s.conf.Load().BucketName, key, record)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if record.Owner == "" {
+		return nil
+	}
+
+	_, err = catalogRepo.IncrementUsage(record.Owner, record.Category, sign*record.Size, sign, 0, 0)
+	return err
+}
+
+// hideEntry deletes the key's catalog entry and takes it off its owner's usage, in a short
+// transaction run before the object itself leaves the bucket. hidden is false if the key had
+// no live entry, e.g. an object that predates the catalog.
+func (s *serviceImpl) hideEntry(key string) (hidden bool, err error) {
+	err = s.catalogRepo.WithTransaction(func(catalogRepo catalog.Repository) error {
+		record := &model.Object{}
+		err := catalogRepo.FindByKey(s.conf.Load().BucketName, key, record)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if record.Owner != "" {
+			if _, err := catalogRepo.IncrementUsage(record.Owner, record.Category, -record.Size, -1, 0, 0); err != nil {
+				return err
+			}
+		}
+		hidden = true
+
+		return catalogRepo.Delete(s.conf.Load().BucketName, key)
+	})
+
+	return hidden, err
+}
+
+// unhideEntry undoes hideEntry after the object couldn't be removed from the bucket.
+func (s *serviceImpl) unhideEntry(ctx context.Context, method string, key string) {
+	err := s.catalogRepo.WithTransaction(func(catalogRepo catalog.Repository) error {
+		if err := catalogRepo.Restore(s.conf.Load().BucketName, key); err != nil {
+			return err
+		}
+
+		return s.adjustUsage(catalogRepo, key, 1)
+	})
+	if err != nil {
+		s.logger(ctx, method).Error("Unhide: ", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// lookupResult is what FindByKey caches per key; misses are cached too (Found == false)
+// so repeated lookups of missing keys don't reach the catalog or the bucket.
+type lookupResult struct {
+	Found bool   `json:"found"`
+	Url   string `json:"url"`
+	Key   string `json:"key"`
+}
+
+func aliasCacheKey(alias string) string {
+	return "alias:" + alias
+}
+
+func objectCacheKey(key string) string {
+	return "object:" + key
+}
+
+// findObject resolves key (an alias or a concrete key) to the object it refers to.
+// Concurrent calls for the same key share a single lookup, which therefore isn't cancelled
+// along with the caller that happened to start it.
+func (s *serviceImpl) findObject(ctx context.Context, key string) (*lookupResult, error) {
+	ctx = context.WithoutCancel(ctx)
+	v, err, _ := s.lookupGroup.Do(key, func() (interface{}, error) {
+		target, err := s.resolveAlias(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.lookupObject(ctx, target)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(*lookupResult), nil
+}
+
+func (s *serviceImpl) resolveAlias(ctx context.Context, alias string) (string, error) {
+	var target string
+	if s.getCache(aliasCacheKey(alias), &target) {
+		if target == "" {
+			return alias, nil
+		}
+		return target, nil
+	}
+
+	target, err := s.repo.ResolveAlias(ctx, s.conf.Load().BucketName, alias)
+	if err != nil {
+		return "", fmt.Errorf("ResolveAlias: %w", err)
+	}
+
+	if target == "" {
+		s.setCache(aliasCacheKey(alias), target, s.cacheConf.NegativeTTL)
+		return alias, nil
+	}
+	s.setCache(aliasCacheKey(alias), target, s.cacheConf.TTL)
+
+	return target, nil
+}
+
+func (s *serviceImpl) lookupObject(ctx context.Context, key string) (*lookupResult, error) {
+	result := &lookupResult{}
+	if s.getCache(objectCacheKey(key), result) {
+		return result, nil
+	}
+
+	err := s.catalogRepo.FindByKey(s.conf.Load().BucketName, key, &model.Object{})
+	if err == nil {
+		result = &lookupResult{Found: true, Url: s.repo.GetURL(s.conf.Load().BucketName, key), Key: key}
+		s.setCache(objectCacheKey(key), result, s.cacheConf.TTL)
+		return result, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("FindByKey: %w", err)
+	}
+
+	// objects uploaded before the catalog existed are only known to the bucket
+	url, err := s.repo.Get(ctx, s.conf.Load().BucketName, key)
+	if err != nil {
+		return nil, fmt.Errorf("Get: %w", err)
+	}
+	if url == "" {
+		result = &lookupResult{Found: false}
+		s.setCache(objectCacheKey(key), result, s.cacheConf.NegativeTTL)
+		return result, nil
+	}
+
+	result = &lookupResult{Found: true, Url: url, Key: key}
+	s.setCache(objectCacheKey(key), result, s.cacheConf.TTL)
+
+	return result, nil
+}
+
+// The cache is an optimisation only: its failures are logged and treated as misses.
+func (s *serviceImpl) getCache(key string, value interface{}) bool {
+	err := s.cacheRepo.GetValue(key, value)
+	if err != nil && !errors.Is(err, cache.ErrCacheMiss) {
+		s.log.Named("cache").Warn("GetValue: ", zap.Error(err))
+	}
+
+	return err == nil
+}
+
+func (s *serviceImpl) setCache(key string, value interface{}, ttl time.Duration) {
+	if err := s.cacheRepo.SetValue(key, value, ttl); err != nil {
+		s.log.Named("cache").Warn("SetValue: ", zap.Error(err))
+	}
+}
+
+func (s *serviceImpl) invalidateCache(key string) {
+	if err := s.cacheRepo.DeleteValue(key); err != nil {
+		s.log.Named("cache").Warn("DeleteValue: ", zap.Error(err))
+	}
+}
+
+func getCategory(ctx context.Context) string {
+	category := utils.GetMetadataValue(ctx, constant.CategoryMetadataKey)
+	if category == "" {
+		return constant.DefaultCategory
+	}
+
+	return category
+}
+
 func (s *serviceImpl) GetURL(bucketName string, objectKey string) string {
-	return "https://" + s.conf.Endpoint + "/" + bucketName + "/" + objectKey
+	return "https://" + s.conf.Load().Endpoint + "/" + bucketName + "/" + objectKey
 }
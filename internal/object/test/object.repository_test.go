@@ -1,9 +1,15 @@
 package test
 
 import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/isd-sgcu/rpkm67-store/config"
@@ -27,7 +33,8 @@ func TestObjectRepository(t *testing.T) {
 
 func (t *ObjectRepositoryTest) SetupTest() {
 	t.conf = &config.Store{
-		Endpoint: "mock-endpoint",
+		Endpoint:        "mock-endpoint",
+		TrashBucketName: "trash",
 	}
 	t.controller = gomock.NewController(t.T())
 	t.mockEndpoint = "https://mock-endpoint/bucket/object"
@@ -38,14 +45,16 @@ func (t *ObjectRepositoryTest) TestCreateObjectSuccess() {
 	storeClient.EXPECT().
 		PutObject(gomock.Any(), "mock-bucket", "mock-key", gomock.Any(), int64(0), gomock.Any()).
 		Return(minio.UploadInfo{
-			Key: "mock-key",
+			Key:       "mock-key",
+			VersionID: "v1",
 		}, nil)
 
 	repo := object.NewRepository(t.conf, storeClient, nil)
 
-	url, key, err := repo.Upload([]byte{}, "mock-bucket", "mock-key")
+	url, key, versionID, err := repo.Upload(context.Background(), []byte{}, "mock-bucket", "mock-key", object.UploadOptions{})
 	t.Nil(err)
 	t.Equal("mock-key", key)
+	t.Equal("v1", versionID)
 	t.Equal(repo.GetURL("mock-bucket", "mock-key"), url)
 }
 
@@ -55,7 +64,7 @@ func (t *ObjectRepositoryTest) TestUploadSuccess() {
 
 	repo := object.NewRepository(t.conf, storeClient, nil)
 
-	url, key, err := repo.Upload([]byte{}, "bucket", "object")
+	url, key, _, err := repo.Upload(context.Background(), []byte{}, "bucket", "object", object.UploadOptions{})
 	t.Nil(err)
 	t.Equal("object", key)
 	t.Equal(repo.GetURL("bucket", "object"), url)
@@ -67,7 +76,7 @@ func (t *ObjectRepositoryTest) TestUploadError() {
 
 	repo := object.NewRepository(t.conf, storeClient, nil)
 
-	url, key, err := repo.Upload([]byte{}, "bucket", "object")
+	url, key, _, err := repo.Upload(context.Background(), []byte{}, "bucket", "object", object.UploadOptions{})
 	t.NotNil(err)
 	t.Empty(url)
 	t.Empty(key)
@@ -75,11 +84,11 @@ func (t *ObjectRepositoryTest) TestUploadError() {
 
 func (t *ObjectRepositoryTest) TestDeleteSuccess() {
 	storeClient := storeClient.NewMockClient(t.controller)
-	storeClient.EXPECT().RemoveObject(gomock.Any(), "bucket", "object", gomock.Any()).Return(nil)
+	storeClient.EXPECT().RemoveObject(gomock.Any(), "bucket", "object", minio.RemoveObjectOptions{}).Return(nil)
 
 	repo := object.NewRepository(t.conf, storeClient, nil)
 
-	err:= repo.Delete("bucket", "object")
+	err:= repo.Delete(context.Background(), "bucket", "object")
 	t.Nil(err)
 }
 
@@ -89,44 +98,341 @@ func (t *ObjectRepositoryTest) TestDeleteError() {
 
 	repo := object.NewRepository(t.conf, storeClient, nil)
 
-	err:= repo.Delete("bucket", "object")
+	err:= repo.Delete(context.Background(), "bucket", "object")
 	t.NotNil(err)
 }
 
+func (t *ObjectRepositoryTest) TestPurgeBypassesGovernance() {
+	objects := make(chan minio.ObjectInfo, 2)
+	objects <- minio.ObjectInfo{Key: "object", VersionID: "v2", IsLatest: true, IsDeleteMarker: true}
+	objects <- minio.ObjectInfo{Key: "object", VersionID: "v1"}
+	close(objects)
+
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().ListObjects(gomock.Any(), "bucket", minio.ListObjectsOptions{Prefix: "object", WithVersions: true}).Return(objects)
+	storeClient.EXPECT().RemoveObject(gomock.Any(), "bucket", "object", minio.RemoveObjectOptions{VersionID: "v2", GovernanceBypass: true}).Return(nil)
+	storeClient.EXPECT().RemoveObject(gomock.Any(), "bucket", "object", minio.RemoveObjectOptions{VersionID: "v1", GovernanceBypass: true}).Return(nil)
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	err := repo.Purge(context.Background(), "bucket", "object", true)
+	t.Nil(err)
+}
+
+func (t *ObjectRepositoryTest) TestGetRetentionSuccess() {
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().StatObject(gomock.Any(), "bucket", "object", gomock.Any()).Return(minio.ObjectInfo{
+		Metadata: http.Header{
+			"X-Amz-Object-Lock-Mode":              []string{"GOVERNANCE"},
+			"X-Amz-Object-Lock-Retain-Until-Date": []string{"2030-01-02T03:04:05Z"},
+			"X-Amz-Object-Lock-Legal-Hold":        []string{"ON"},
+		}}, nil)
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	retention, err := repo.GetRetention(context.Background(), "bucket", "object", "")
+	t.Nil(err)
+	t.Equal(&object.Retention{
+		Mode:        minio.Governance,
+		RetainUntil: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		LegalHold:   true,
+	}, retention)
+}
+
+func (t *ObjectRepositoryTest) TestGetRetentionNotFound() {
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().StatObject(gomock.Any(), "bucket", "object", gomock.Any()).Return(minio.ObjectInfo{},
+		minio.ErrorResponse{Code: "NoSuchKey"})
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	retention, err := repo.GetRetention(context.Background(), "bucket", "object", "")
+	t.Nil(err)
+	t.Nil(retention)
+}
+
+func (t *ObjectRepositoryTest) TestUploadWithRetention() {
+	until := time.Now().Add(time.Hour)
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().PutObject(gomock.Any(), "bucket", "object", gomock.Any(), int64(0), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ string, _ io.Reader, _ int64, opts minio.PutObjectOptions) (minio.UploadInfo, error) {
+			t.Equal(minio.Governance, opts.Mode)
+			t.Equal(until, opts.RetainUntilDate)
+			t.Equal(minio.LegalHoldEnabled, opts.LegalHold)
+			return minio.UploadInfo{Key: "object"}, nil
+		})
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	_, _, _, err := repo.Upload(context.Background(), []byte{}, "bucket", "object", object.UploadOptions{
+		Retention: object.Retention{Mode: minio.Governance, RetainUntil: until, LegalHold: true},
+	})
+	t.Nil(err)
+}
+
+func (t *ObjectRepositoryTest) TestUploadWithChecksums() {
+	data := []byte("data")
+	sha256Sum := sha256.Sum256(data)
+	md5Sum := md5.Sum(data)
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().PutObject(gomock.Any(), "bucket", "object", gomock.Any(), int64(len(data)), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ string, _ io.Reader, _ int64, opts minio.PutObjectOptions) (minio.UploadInfo, error) {
+			t.True(opts.SendContentMd5)
+			t.False(opts.DisableMultipart)
+			t.NotContains(opts.UserMetadata, "X-Amz-Checksum-Sha256")
+			t.Equal(hex.EncodeToString(sha256Sum[:]), opts.UserMetadata["Checksum-Sha256"])
+			t.Equal(hex.EncodeToString(md5Sum[:]), opts.UserMetadata["Checksum-Md5"])
+			return minio.UploadInfo{Key: "object"}, nil
+		})
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	_, _, _, err := repo.Upload(context.Background(), data, "bucket", "object", object.UploadOptions{
+		Checksums: object.Checksums{SHA256: sha256Sum, MD5: md5Sum},
+	})
+	t.Nil(err)
+}
+
 func (t *ObjectRepositoryTest) TestGetSuccess() {
 	httpClient := httpClient.NewMockClient(t.controller)
-	httpClient.EXPECT().Get(t.mockEndpoint).Return(&http.Response{
-		StatusCode: http.StatusOK},nil)
+	httpClient.EXPECT().Head(gomock.Any(), t.mockEndpoint).Return(&http.Response{
+		StatusCode: http.StatusOK, Body: http.NoBody},nil)
 
 	repo := object.NewRepository(t.conf, nil, httpClient)
 
-	url,err:= repo.Get("bucket", "object")
+	url,err:= repo.Get(context.Background(), "bucket", "object")
 	t.Nil(err)
 	t.Equal(repo.GetURL("bucket","object"),url)
 }
 
 func (t *ObjectRepositoryTest) TestGetError() {
 	httpClient := httpClient.NewMockClient(t.controller)
-	httpClient.EXPECT().Get(t.mockEndpoint).Return(&http.Response{
-		StatusCode: http.StatusOK},errors.New("error"))
+	httpClient.EXPECT().Head(gomock.Any(), t.mockEndpoint).Return(nil, errors.New("error"))
 
 	repo := object.NewRepository(t.conf, nil, httpClient)
 
-	url,err:= repo.Get("bucket", "object")
+	url,err:= repo.Get(context.Background(), "bucket", "object")
 	t.NotNil(err)
 	t.Empty(url)
 }
 
 func (t *ObjectRepositoryTest) TestGetStatusNotOK() {
 	httpClient := httpClient.NewMockClient(t.controller)
-	httpClient.EXPECT().Get(t.mockEndpoint).Return(&http.Response{
-		StatusCode: http.StatusNotFound},nil)
+	httpClient.EXPECT().Head(gomock.Any(), t.mockEndpoint).Return(&http.Response{
+		StatusCode: http.StatusNotFound, Body: http.NoBody},nil)
 
 	repo := object.NewRepository(t.conf, nil, httpClient)
 
-	url,err:= repo.Get("bucket", "object")
+	url,err:= repo.Get(context.Background(), "bucket", "object")
+	t.Nil(err)
+	t.Empty(url)
+}
+
+func (t *ObjectRepositoryTest) TestSetAliasSuccess() {
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().PutObject(gomock.Any(), "bucket", ".aliases/users/1/avatar", gomock.Any(), int64(0), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ string, _ io.Reader, _ int64, opts minio.PutObjectOptions) (minio.UploadInfo, error) {
+			t.Equal("object", opts.UserMetadata["Alias-Target"])
+			return minio.UploadInfo{}, nil
+		})
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	err := repo.SetAlias(context.Background(), "bucket", "users/1/avatar", "object")
+	t.Nil(err)
+}
+
+func (t *ObjectRepositoryTest) TestResolveAliasSuccess() {
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().StatObject(gomock.Any(), "bucket", ".aliases/users/1/avatar", gomock.Any()).Return(minio.ObjectInfo{
+		UserMetadata: minio.StringMap{"Alias-Target": "object"}}, nil)
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	key, err := repo.ResolveAlias(context.Background(), "bucket", "users/1/avatar")
+	t.Nil(err)
+	t.Equal("object", key)
+}
+
+func (t *ObjectRepositoryTest) TestResolveAliasNotFound() {
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().StatObject(gomock.Any(), "bucket", ".aliases/object", gomock.Any()).Return(minio.ObjectInfo{},
+		minio.ErrorResponse{Code: "NoSuchKey"})
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	key, err := repo.ResolveAlias(context.Background(), "bucket", "object")
+	t.Nil(err)
+	t.Empty(key)
+}
+
+func (t *ObjectRepositoryTest) TestResolveAliasError() {
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().StatObject(gomock.Any(), "bucket", ".aliases/object", gomock.Any()).Return(minio.ObjectInfo{}, errors.New("error"))
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	key, err := repo.ResolveAlias(context.Background(), "bucket", "object")
+	t.NotNil(err)
+	t.Empty(key)
+}
+
+func (t *ObjectRepositoryTest) TestListSuccess() {
+	objects := make(chan minio.ObjectInfo, 2)
+	objects <- minio.ObjectInfo{Key: "a", Size: 1}
+	objects <- minio.ObjectInfo{Key: "b", Size: 2}
+	close(objects)
+
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().ListObjects(gomock.Any(), "bucket", minio.ListObjectsOptions{Prefix: "prefix", Recursive: true}).Return(objects)
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	actual, err := repo.List(context.Background(), "bucket", "prefix")
+	t.Nil(err)
+	t.Equal([]object.StoredObject{{Key: "a", Size: 1}, {Key: "b", Size: 2}}, actual)
+}
+
+func (t *ObjectRepositoryTest) TestListError() {
+	objects := make(chan minio.ObjectInfo, 1)
+	objects <- minio.ObjectInfo{Err: errors.New("error")}
+	close(objects)
+
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().ListObjects(gomock.Any(), "bucket", gomock.Any()).Return(objects)
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	actual, err := repo.List(context.Background(), "bucket", "")
+	t.NotNil(err)
+	t.Nil(actual)
+}
+
+func (t *ObjectRepositoryTest) TestTrashSuccess() {
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().CopyObject(gomock.Any(),
+		minio.CopyDestOptions{Bucket: "trash", Object: "object"},
+		minio.CopySrcOptions{Bucket: "bucket", Object: "object"}).Return(minio.UploadInfo{}, nil)
+	storeClient.EXPECT().RemoveObject(gomock.Any(), "bucket", "object", gomock.Any()).Return(nil)
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	err := repo.Trash(context.Background(), "bucket", "object")
+	t.Nil(err)
+}
+
+func (t *ObjectRepositoryTest) TestTrashCopyError() {
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().CopyObject(gomock.Any(), gomock.Any(), gomock.Any()).Return(minio.UploadInfo{}, errors.New("error"))
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	err := repo.Trash(context.Background(), "bucket", "object")
+	t.NotNil(err)
+}
+
+func (t *ObjectRepositoryTest) TestRestoreSuccess() {
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().StatObject(gomock.Any(), "trash", "object", gomock.Any()).Return(minio.ObjectInfo{}, nil)
+	storeClient.EXPECT().CopyObject(gomock.Any(),
+		minio.CopyDestOptions{Bucket: "bucket", Object: "object"},
+		minio.CopySrcOptions{Bucket: "trash", Object: "object"}).Return(minio.UploadInfo{}, nil)
+	storeClient.EXPECT().RemoveObject(gomock.Any(), "trash", "object", gomock.Any()).Return(nil)
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	url, err := repo.Restore(context.Background(), "bucket", "object")
+	t.Nil(err)
+	t.Equal(t.mockEndpoint, url)
+}
+
+func (t *ObjectRepositoryTest) TestRestoreNotInTrash() {
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().StatObject(gomock.Any(), "trash", "object", gomock.Any()).Return(minio.ObjectInfo{},
+		minio.ErrorResponse{Code: "NoSuchKey"})
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	url, err := repo.Restore(context.Background(), "bucket", "object")
+	t.Nil(err)
+	t.Empty(url)
+}
+
+func (t *ObjectRepositoryTest) TestGetVersionSuccess() {
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().StatObject(gomock.Any(), "bucket", "object", minio.StatObjectOptions{VersionID: "v1"}).Return(minio.ObjectInfo{}, nil)
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	url, err := repo.GetVersion(context.Background(), "bucket", "object", "v1")
+	t.Nil(err)
+	t.Equal(t.mockEndpoint+"?versionId=v1", url)
+}
+
+func (t *ObjectRepositoryTest) TestGetVersionNotFound() {
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().StatObject(gomock.Any(), "bucket", "object", gomock.Any()).Return(minio.ObjectInfo{},
+		minio.ErrorResponse{Code: "NoSuchVersion"})
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	url, err := repo.GetVersion(context.Background(), "bucket", "object", "v1")
+	t.Nil(err)
+	t.Empty(url)
+}
+
+func (t *ObjectRepositoryTest) TestDeleteVersionSuccess() {
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().RemoveObject(gomock.Any(), "bucket", "object", minio.RemoveObjectOptions{VersionID: "v1"}).Return(nil)
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	err := repo.DeleteVersion(context.Background(), "bucket", "object", "v1")
+	t.Nil(err)
+}
+
+func (t *ObjectRepositoryTest) TestListVersionsSuccess() {
+	objects := make(chan minio.ObjectInfo, 3)
+	objects <- minio.ObjectInfo{Key: "object", VersionID: "v2", IsLatest: true}
+	objects <- minio.ObjectInfo{Key: "object", VersionID: "v1"}
+	objects <- minio.ObjectInfo{Key: "object-other", VersionID: "v3"}
+	close(objects)
+
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().ListObjects(gomock.Any(), "bucket", minio.ListObjectsOptions{Prefix: "object", WithVersions: true}).Return(objects)
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	versions, err := repo.ListVersions(context.Background(), "bucket", "object")
+	t.Nil(err)
+	t.Equal([]object.ObjectVersion{{VersionID: "v2", IsLatest: true}, {VersionID: "v1"}}, versions)
+}
+
+func (t *ObjectRepositoryTest) TestRestoreVersionSuccess() {
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().CopyObject(gomock.Any(),
+		minio.CopyDestOptions{Bucket: "bucket", Object: "object"},
+		minio.CopySrcOptions{Bucket: "bucket", Object: "object", VersionID: "v1"}).Return(minio.UploadInfo{VersionID: "v3"}, nil)
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	url, versionID, err := repo.RestoreVersion(context.Background(), "bucket", "object", "v1")
+	t.Nil(err)
+	t.Equal(t.mockEndpoint, url)
+	t.Equal("v3", versionID)
+}
+
+func (t *ObjectRepositoryTest) TestRestoreVersionNotFound() {
+	storeClient := storeClient.NewMockClient(t.controller)
+	storeClient.EXPECT().CopyObject(gomock.Any(), gomock.Any(), gomock.Any()).Return(minio.UploadInfo{},
+		minio.ErrorResponse{Code: "NoSuchVersion"})
+
+	repo := object.NewRepository(t.conf, storeClient, nil)
+
+	url, versionID, err := repo.RestoreVersion(context.Background(), "bucket", "object", "v1")
 	t.Nil(err)
 	t.Empty(url)
+	t.Empty(versionID)
 }
 
 func (t *ObjectRepositoryTest) TestGetURL() {
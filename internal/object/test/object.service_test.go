@@ -2,8 +2,14 @@ package test
 
 import (
 	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"google.golang.org/grpc/codes"
@@ -11,11 +17,23 @@ import (
 
 	proto "github.com/isd-sgcu/rpkm67-go-proto/rpkm67/store/object/v1"
 	"github.com/isd-sgcu/rpkm67-store/constant"
+	"github.com/isd-sgcu/rpkm67-store/internal/cache"
+	"github.com/isd-sgcu/rpkm67-store/internal/catalog"
+	"github.com/isd-sgcu/rpkm67-store/internal/model"
 	"github.com/isd-sgcu/rpkm67-store/internal/object"
+	"github.com/isd-sgcu/rpkm67-store/internal/resilience"
 	"github.com/isd-sgcu/rpkm67-store/internal/utils"
+	mock_cache "github.com/isd-sgcu/rpkm67-store/mocks/cache"
+	mock_catalog "github.com/isd-sgcu/rpkm67-store/mocks/catalog"
 	mock_object "github.com/isd-sgcu/rpkm67-store/mocks/object"
+	adminProto "github.com/isd-sgcu/rpkm67-store/proto/rpkm67/store/admin/v1"
+	"github.com/minio/minio-go/v7"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
 
 	"github.com/isd-sgcu/rpkm67-store/config"
 )
@@ -24,6 +42,7 @@ type ObjectServiceTest struct {
 	suite.Suite
 	controller          *gomock.Controller
 	conf                *config.Store
+	cacheConf           *config.Cache
 	logger              *zap.Logger
 	uploadObjectRequest *proto.UploadObjectRequest
 }
@@ -39,6 +58,10 @@ func (t *ObjectServiceTest) SetupTest() {
 		BucketName: "mock-bucket",
 		Endpoint:   "mock-endpoint",
 	}
+	t.cacheConf = &config.Cache{
+		TTL:         time.Minute,
+		NegativeTTL: time.Second,
+	}
 	t.uploadObjectRequest = &proto.UploadObjectRequest{
 		Filename: "object",
 		Data:     []byte("data"),
@@ -47,23 +70,66 @@ func (t *ObjectServiceTest) SetupTest() {
 
 func (t *ObjectServiceTest) TestUploadInternalError() {
 	repo := mock_object.NewMockRepository(t.controller)
-	repo.EXPECT().Upload(t.uploadObjectRequest.Data, t.conf.BucketName, gomock.Any()).Return("", "", fmt.Errorf("error"))
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	t.expectTransaction(catalogRepo)
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().Create(gomock.Any()).Return(nil)
+	catalogRepo.EXPECT().Purge(t.conf.BucketName, gomock.Any()).Return(nil)
+	repo.EXPECT().Upload(gomock.Any(), t.uploadObjectRequest.Data, t.conf.BucketName, gomock.Any(), gomock.Any()).Return("", "", "", fmt.Errorf("error"))
 
-	svc := object.NewService(repo, t.conf, t.logger, utils.NewRandomUtils())
+	svc := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
 
 	expectedErr := status.Error(codes.Internal, constant.InternalServerErrorMessage).Error()
 
 	actual, err := svc.Upload(context.Background(), t.uploadObjectRequest)
 
 	t.Nil(actual)
-	t.EqualError(err, expectedErr)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestUploadStorageUnavailableError() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	t.expectTransaction(catalogRepo)
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().Create(gomock.Any()).Return(nil)
+	catalogRepo.EXPECT().Purge(t.conf.BucketName, gomock.Any()).Return(nil)
+	repo.EXPECT().Upload(gomock.Any(), t.uploadObjectRequest.Data, t.conf.BucketName, gomock.Any(), gomock.Any()).Return("", "", "", resilience.ErrCircuitOpen)
+
+	svc := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.Unavailable, constant.StorageUnavailableErrorMessage).Error()
+
+	actual, err := svc.Upload(context.Background(), t.uploadObjectRequest)
+
+	t.Nil(actual)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
 }
 
 func (t *ObjectServiceTest) TestUploadSuccess() {
 	repo := mock_object.NewMockRepository(t.controller)
-	repo.EXPECT().Upload(t.uploadObjectRequest.Data, t.conf.BucketName, gomock.Any()).Return("url", "key", nil)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().Create(gomock.Any()).DoAndReturn(func(record *model.Object) error {
+		t.Equal(t.conf.BucketName, record.Bucket)
+		t.Equal(int64(len(t.uploadObjectRequest.Data)), record.Size)
+		t.Equal("text/plain; charset=utf-8", record.ContentType)
+		t.True(record.Pending)
+		return nil
+	})
+	repo.EXPECT().Upload(gomock.Any(), t.uploadObjectRequest.Data, t.conf.BucketName, gomock.Any(), object.UploadOptions{
+		ContentType: "text/plain; charset=utf-8",
+		Checksums: object.Checksums{
+			SHA256: sha256.Sum256(t.uploadObjectRequest.Data),
+			MD5:    md5.Sum(t.uploadObjectRequest.Data),
+		},
+	}).Return("url", "key", "", nil)
+	catalogRepo.EXPECT().Commit(t.conf.BucketName, gomock.Any()).Return(nil)
 
-	svc := object.NewService(repo, t.conf, t.logger, utils.NewRandomUtils())
+	svc := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
 
 	expected := &proto.UploadObjectResponse{
 		Object: &proto.Object{
@@ -78,9 +144,321 @@ func (t *ObjectServiceTest) TestUploadSuccess() {
 	t.Equal(expected, actual)
 }
 
+func (t *ObjectServiceTest) TestUploadFailureReleasesReservation() {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-user-id", "user"))
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	t.expectTransaction(catalogRepo)
+	t.expectTransaction(catalogRepo)
+	var key string
+	catalogRepo.EXPECT().Create(gomock.Any()).DoAndReturn(func(record *model.Object) error {
+		key = record.Key
+		return nil
+	})
+	catalogRepo.EXPECT().IncrementUsage("user", constant.DefaultCategory, int64(4), int64(1), int64(0), int64(0)).Return(true, nil)
+	repo.EXPECT().Upload(gomock.Any(), t.uploadObjectRequest.Data, t.conf.BucketName, gomock.Any(), gomock.Any()).Return("", "", "", fmt.Errorf("error"))
+	catalogRepo.EXPECT().Purge(t.conf.BucketName, gomock.Any()).DoAndReturn(func(_ string, objectKey string) error {
+		t.Equal(key, objectKey)
+		return nil
+	})
+	catalogRepo.EXPECT().IncrementUsage("user", constant.DefaultCategory, int64(-4), int64(-1), int64(0), int64(0)).Return(true, nil)
+
+	svc := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	actual, err := svc.Upload(ctx, t.uploadObjectRequest)
+
+	t.Nil(actual)
+	t.Equal(codes.Internal, status.Code(err))
+}
+
+func (t *ObjectServiceTest) TestUploadCommitFailureDiscardsObject() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	t.expectTransaction(catalogRepo)
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().Create(gomock.Any()).Return(nil)
+	repo.EXPECT().Upload(gomock.Any(), t.uploadObjectRequest.Data, t.conf.BucketName, gomock.Any(), gomock.Any()).Return("url", "key", "v1", nil)
+	catalogRepo.EXPECT().Commit(t.conf.BucketName, gomock.Any()).Return(fmt.Errorf("error"))
+	repo.EXPECT().DeleteVersion(gomock.Any(), t.conf.BucketName, gomock.Any(), "v1").Return(nil)
+	catalogRepo.EXPECT().Purge(t.conf.BucketName, gomock.Any()).Return(nil)
+
+	svc := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	actual, err := svc.Upload(context.Background(), t.uploadObjectRequest)
+
+	t.Nil(actual)
+	t.Equal(codes.Internal, status.Code(err))
+}
+
+func (t *ObjectServiceTest) TestUploadTooLargeError() {
+	t.conf.MaxFileSize = 1
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	svc := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	actual, err := svc.Upload(context.Background(), t.uploadObjectRequest)
+
+	t.Nil(actual)
+	t.Equal(codes.InvalidArgument, status.Code(err))
+	t.Equal(constant.FileTooLargeErrorMessage, status.Convert(err).Message())
+	t.Equal("data", t.fieldViolation(err).Field)
+}
+
+func (t *ObjectServiceTest) TestReloadMaxFileSize() {
+	t.conf.MaxFileSize = 1
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().Create(gomock.Any()).Return(nil)
+	repo.EXPECT().Upload(gomock.Any(), t.uploadObjectRequest.Data, t.conf.BucketName, gomock.Any(), gomock.Any()).Return("url", "key", "", nil)
+	catalogRepo.EXPECT().Commit(t.conf.BucketName, gomock.Any()).Return(nil)
+
+	svc := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+	svc.Reload(&config.Store{MaxFileSize: 1024, BucketName: "ignored"})
+
+	actual, err := svc.Upload(context.Background(), t.uploadObjectRequest)
+
+	t.Nil(err)
+	t.Equal("key", actual.Object.Key)
+}
+
+func (t *ObjectServiceTest) TestUploadChecksumsMatch() {
+	sha256Sum := sha256.Sum256(t.uploadObjectRequest.Data)
+	md5Sum := md5.Sum(t.uploadObjectRequest.Data)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"x-checksum-sha256", hex.EncodeToString(sha256Sum[:]),
+		"content-md5", base64.StdEncoding.EncodeToString(md5Sum[:]),
+	))
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().Create(gomock.Any()).Return(nil)
+	repo.EXPECT().Upload(gomock.Any(), t.uploadObjectRequest.Data, t.conf.BucketName, gomock.Any(), gomock.Any()).Return("url", "key", "", nil)
+	catalogRepo.EXPECT().Commit(t.conf.BucketName, gomock.Any()).Return(nil)
+
+	svc := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	_, err := svc.Upload(ctx, t.uploadObjectRequest)
+
+	t.Nil(err)
+}
+
+func (t *ObjectServiceTest) TestUploadChecksumMismatchError() {
+	md5Sum := md5.Sum([]byte("corrupted"))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("content-md5", base64.StdEncoding.EncodeToString(md5Sum[:])))
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	svc := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	actual, err := svc.Upload(ctx, t.uploadObjectRequest)
+
+	t.Nil(actual)
+	t.Equal(codes.InvalidArgument, status.Code(err))
+	t.Equal(constant.ChecksumMismatchErrorMessage, status.Convert(err).Message())
+	t.Equal("content-md5", t.fieldViolation(err).Field)
+}
+
+func (t *ObjectServiceTest) TestUploadInvalidChecksumError() {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-checksum-sha256", "not-a-checksum"))
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	svc := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	actual, err := svc.Upload(ctx, t.uploadObjectRequest)
+
+	t.Nil(actual)
+	t.Equal(codes.InvalidArgument, status.Code(err))
+	t.Equal(constant.InvalidChecksumErrorMessage, status.Convert(err).Message())
+	t.Equal("x-checksum-sha256", t.fieldViolation(err).Field)
+}
+
+func (t *ObjectServiceTest) idempotentContext(key string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-user-id", "user", "x-idempotency-key", key))
+}
+
+// expectUploadStored expects one upload of the request to be reserved in the catalog and stored.
+func (t *ObjectServiceTest) expectUploadStored(repo *mock_object.MockRepository, catalogRepo *mock_catalog.MockRepository) {
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().IncrementUsage("user", constant.DefaultCategory, int64(len(t.uploadObjectRequest.Data)), int64(1), int64(0), int64(0)).Return(true, nil)
+	catalogRepo.EXPECT().Create(gomock.Any()).Return(nil)
+	repo.EXPECT().Upload(gomock.Any(), t.uploadObjectRequest.Data, t.conf.BucketName, gomock.Any(), gomock.Any()).Return("url", "key", "v1", nil)
+	catalogRepo.EXPECT().Commit(t.conf.BucketName, gomock.Any()).Return(nil)
+}
+
+func (t *ObjectServiceTest) TestUploadIdempotentRetryReturnsOriginal() {
+	t.conf.IdempotencyWindow = time.Minute
+	sum := sha256.Sum256(t.uploadObjectRequest.Data)
+	stored := &model.IdempotencyKey{Owner: "user", Key: "request-1", Checksum: hex.EncodeToString(sum[:]), Url: "url", ObjectKey: "key", VersionID: "v1"}
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().IncrementUsage("user", constant.DefaultCategory, int64(len(t.uploadObjectRequest.Data)), int64(1), int64(0), int64(0)).Return(true, nil)
+	catalogRepo.EXPECT().Create(gomock.Any()).Return(nil)
+	catalogRepo.EXPECT().Commit(t.conf.BucketName, gomock.Any()).Return(nil)
+	gomock.InOrder(
+		// the key is reserved before any data is sent
+		catalogRepo.EXPECT().ReserveIdempotencyKey(gomock.Any()).DoAndReturn(func(record *model.IdempotencyKey) (bool, error) {
+			t.Equal("user", record.Owner)
+			t.Equal("request-1", record.Key)
+			t.Equal(stored.Checksum, record.Checksum)
+			t.True(record.Pending)
+			return true, nil
+		}),
+		repo.EXPECT().Upload(gomock.Any(), t.uploadObjectRequest.Data, t.conf.BucketName, gomock.Any(), gomock.Any()).Return("url", "key", "v1", nil),
+		catalogRepo.EXPECT().CompleteIdempotencyKey(gomock.Any()).DoAndReturn(func(record *model.IdempotencyKey) error {
+			t.Equal("key", record.ObjectKey)
+			t.Equal("v1", record.VersionID)
+			return nil
+		}),
+		catalogRepo.EXPECT().ReserveIdempotencyKey(gomock.Any()).Return(false, nil),
+		catalogRepo.EXPECT().FindIdempotencyKey("user", "request-1", gomock.Any()).SetArg(2, *stored).Return(nil),
+	)
+
+	svc := object.NewService(repo, catalogRepo, cache.NewLRURepository(10), t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	first, err := svc.Upload(t.idempotentContext("request-1"), t.uploadObjectRequest)
+	t.Require().Nil(err)
+	retry, err := svc.Upload(t.idempotentContext("request-1"), t.uploadObjectRequest)
+
+	t.Nil(err)
+	t.Equal(first, retry)
+}
+
+func (t *ObjectServiceTest) TestUploadIdempotencyKeyMismatchError() {
+	t.conf.IdempotencyWindow = time.Minute
+	sum := sha256.Sum256(t.uploadObjectRequest.Data)
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	catalogRepo.EXPECT().ReserveIdempotencyKey(gomock.Any()).Return(false, nil)
+	catalogRepo.EXPECT().FindIdempotencyKey("user", "request-1", gomock.Any()).SetArg(2, model.IdempotencyKey{Checksum: hex.EncodeToString(sum[:]), ObjectKey: "key"}).Return(nil)
+
+	svc := object.NewService(repo, catalogRepo, cache.NewLRURepository(10), t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	actual, err := svc.Upload(t.idempotentContext("request-1"), &proto.UploadObjectRequest{Filename: "object", Data: []byte("other")})
+
+	t.Nil(actual)
+	t.EqualError(status.Convert(err).Err(), status.Error(codes.AlreadyExists, constant.IdempotencyKeyReusedErrorMessage).Error())
+}
+
+func (t *ObjectServiceTest) TestUploadIdempotencyKeyInProgressError() {
+	t.conf.IdempotencyWindow = time.Minute
+	sum := sha256.Sum256(t.uploadObjectRequest.Data)
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	catalogRepo.EXPECT().ReserveIdempotencyKey(gomock.Any()).Return(false, nil)
+	catalogRepo.EXPECT().FindIdempotencyKey("user", "request-1", gomock.Any()).SetArg(2, model.IdempotencyKey{Checksum: hex.EncodeToString(sum[:]), Pending: true}).Return(nil)
+
+	svc := object.NewService(repo, catalogRepo, cache.NewLRURepository(10), t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	actual, err := svc.Upload(t.idempotentContext("request-1"), t.uploadObjectRequest)
+
+	t.Nil(actual)
+	t.EqualError(status.Convert(err).Err(), status.Error(codes.AlreadyExists, constant.IdempotencyKeyInProgressErrorMessage).Error())
+}
+
+func (t *ObjectServiceTest) TestUploadIdempotencyKeyIgnoredWithoutOwner() {
+	t.conf.IdempotencyWindow = time.Minute
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-idempotency-key", "request-1"))
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	t.expectTransaction(catalogRepo)
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().Create(gomock.Any()).Return(nil).Times(2)
+	repo.EXPECT().Upload(gomock.Any(), t.uploadObjectRequest.Data, t.conf.BucketName, gomock.Any(), gomock.Any()).Return("url", "key", "", nil).Times(2)
+	catalogRepo.EXPECT().Commit(t.conf.BucketName, gomock.Any()).Return(nil).Times(2)
+
+	svc := object.NewService(repo, catalogRepo, cache.NewLRURepository(10), t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	_, err := svc.Upload(ctx, t.uploadObjectRequest)
+	t.Require().Nil(err)
+	_, err = svc.Upload(ctx, t.uploadObjectRequest)
+
+	t.Nil(err)
+}
+
+func (t *ObjectServiceTest) TestUploadIdempotencyKeysAreIndependent() {
+	t.conf.IdempotencyWindow = time.Minute
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	t.expectUploadStored(repo, catalogRepo)
+	t.expectUploadStored(repo, catalogRepo)
+	catalogRepo.EXPECT().ReserveIdempotencyKey(gomock.Any()).Return(true, nil).Times(2)
+	catalogRepo.EXPECT().CompleteIdempotencyKey(gomock.Any()).Return(nil).Times(2)
+
+	svc := object.NewService(repo, catalogRepo, cache.NewLRURepository(10), t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	_, err := svc.Upload(t.idempotentContext("request-1"), t.uploadObjectRequest)
+	t.Require().Nil(err)
+	_, err = svc.Upload(t.idempotentContext("request-2"), t.uploadObjectRequest)
+
+	t.Nil(err)
+}
+
+func (t *ObjectServiceTest) TestUploadIdempotentFailureReleasesKey() {
+	t.conf.IdempotencyWindow = time.Minute
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	t.expectTransaction(catalogRepo)
+	t.expectTransaction(catalogRepo)
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().IncrementUsage("user", constant.DefaultCategory, int64(len(t.uploadObjectRequest.Data)), int64(1), int64(0), int64(0)).Return(true, nil).AnyTimes()
+	catalogRepo.EXPECT().IncrementUsage("user", constant.DefaultCategory, -int64(len(t.uploadObjectRequest.Data)), int64(-1), int64(0), int64(0)).Return(true, nil)
+	catalogRepo.EXPECT().Create(gomock.Any()).Return(nil).Times(2)
+	catalogRepo.EXPECT().Purge(t.conf.BucketName, gomock.Any()).Return(nil)
+	catalogRepo.EXPECT().ReserveIdempotencyKey(gomock.Any()).Return(true, nil).Times(2)
+	gomock.InOrder(
+		repo.EXPECT().Upload(gomock.Any(), t.uploadObjectRequest.Data, t.conf.BucketName, gomock.Any(), gomock.Any()).Return("", "", "", fmt.Errorf("error")),
+		catalogRepo.EXPECT().ReleaseIdempotencyKey("user", "request-1").Return(nil),
+		repo.EXPECT().Upload(gomock.Any(), t.uploadObjectRequest.Data, t.conf.BucketName, gomock.Any(), gomock.Any()).Return("url", "key", "", nil),
+	)
+	catalogRepo.EXPECT().Commit(t.conf.BucketName, gomock.Any()).Return(nil)
+	catalogRepo.EXPECT().CompleteIdempotencyKey(gomock.Any()).Return(nil)
+
+	svc := object.NewService(repo, catalogRepo, cache.NewLRURepository(10), t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	_, err := svc.Upload(t.idempotentContext("request-1"), t.uploadObjectRequest)
+	t.Equal(codes.Internal, status.Code(err))
+	actual, err := svc.Upload(t.idempotentContext("request-1"), t.uploadObjectRequest)
+
+	t.Nil(err)
+	t.Equal("key", actual.Object.Key)
+}
+
+func (t *ObjectServiceTest) fieldViolation(err error) *errdetails.BadRequest_FieldViolation {
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok && len(badRequest.FieldViolations) > 0 {
+			return badRequest.FieldViolations[0]
+		}
+	}
+	t.FailNow("no field violation in error details")
+
+	return nil
+}
+
 func (t *ObjectServiceTest) TestFindByKeyEmptyError() {
 	repo := mock_object.NewMockRepository(t.controller)
-	srv := object.NewService(repo, t.conf, t.logger, utils.NewRandomUtils())
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
 
 	findByKeyInput := &proto.FindByKeyObjectRequest{
 		Key: "",
@@ -91,7 +469,8 @@ func (t *ObjectServiceTest) TestFindByKeyEmptyError() {
 	actual, err := srv.FindByKey(context.Background(), findByKeyInput)
 
 	t.Nil(actual)
-	t.EqualError(err, expectedErr)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+	t.Equal("key", t.fieldViolation(err).Field)
 }
 
 func (t *ObjectServiceTest) TestFindByKeyInternalError() {
@@ -100,16 +479,20 @@ func (t *ObjectServiceTest) TestFindByKeyInternalError() {
 	}
 
 	repo := mock_object.NewMockRepository(t.controller)
-	repo.EXPECT().Get(t.conf.BucketName, findByKeyInput.Key).Return("", fmt.Errorf("error"))
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().ResolveAlias(gomock.Any(), t.conf.BucketName, findByKeyInput.Key).Return("", nil)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, findByKeyInput.Key, gomock.Any()).Return(gorm.ErrRecordNotFound)
+	repo.EXPECT().Get(gomock.Any(), t.conf.BucketName, findByKeyInput.Key).Return("", fmt.Errorf("error"))
 
-	srv := object.NewService(repo, t.conf, t.logger, utils.NewRandomUtils())
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
 
 	expectedErr := status.Error(codes.Internal, constant.InternalServerErrorMessage).Error()
 
 	actual, err := srv.FindByKey(context.Background(), findByKeyInput)
 
 	t.Nil(actual)
-	t.EqualError(err, expectedErr)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
 }
 
 func (t *ObjectServiceTest) TestFindByKeyNotFoundError() {
@@ -118,16 +501,20 @@ func (t *ObjectServiceTest) TestFindByKeyNotFoundError() {
 	}
 
 	repo := mock_object.NewMockRepository(t.controller)
-	repo.EXPECT().Get(t.conf.BucketName, findByKeyInput.Key).Return("", nil)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().ResolveAlias(gomock.Any(), t.conf.BucketName, findByKeyInput.Key).Return("", nil)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, findByKeyInput.Key, gomock.Any()).Return(gorm.ErrRecordNotFound)
+	repo.EXPECT().Get(gomock.Any(), t.conf.BucketName, findByKeyInput.Key).Return("", nil)
 
-	srv := object.NewService(repo, t.conf, t.logger, utils.NewRandomUtils())
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
 
 	expectedErr := status.Error(codes.NotFound, constant.ObjectNotFoundErrorMessage).Error()
 
 	actual, err := srv.FindByKey(context.Background(), findByKeyInput)
 
 	t.Nil(actual)
-	t.EqualError(err, expectedErr)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
 }
 
 func (t *ObjectServiceTest) TestFindByKeySuccess() {
@@ -136,9 +523,13 @@ func (t *ObjectServiceTest) TestFindByKeySuccess() {
 	}
 
 	repo := mock_object.NewMockRepository(t.controller)
-	repo.EXPECT().Get(t.conf.BucketName, findByKeyInput.Key).Return("url", nil)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().ResolveAlias(gomock.Any(), t.conf.BucketName, findByKeyInput.Key).Return("", nil)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, findByKeyInput.Key, gomock.Any()).Return(gorm.ErrRecordNotFound)
+	repo.EXPECT().Get(gomock.Any(), t.conf.BucketName, findByKeyInput.Key).Return("url", nil)
 
-	srv := object.NewService(repo, t.conf, t.logger, utils.NewRandomUtils())
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
 
 	expected := &proto.FindByKeyObjectResponse{
 		Object: &proto.Object{
@@ -153,52 +544,1163 @@ func (t *ObjectServiceTest) TestFindByKeySuccess() {
 	t.Equal(expected, actual)
 }
 
-func (t *ObjectServiceTest) TestDeleteByKeyEmptyError() {
+func (t *ObjectServiceTest) TestFindByKeyCatalogSuccess() {
+	findByKeyInput := &proto.FindByKeyObjectRequest{
+		Key: "key",
+	}
+
 	repo := mock_object.NewMockRepository(t.controller)
-	srv := object.NewService(repo, t.conf, t.logger, utils.NewRandomUtils())
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().ResolveAlias(gomock.Any(), t.conf.BucketName, findByKeyInput.Key).Return("", nil)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, findByKeyInput.Key, gomock.Any()).Return(nil)
+	repo.EXPECT().GetURL(t.conf.BucketName, findByKeyInput.Key).Return("url")
 
-	deleteByKeyInput := &proto.DeleteByKeyObjectRequest{
-		Key: "",
-	}
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
 
-	expectedErr := status.Error(codes.InvalidArgument, constant.KeyEmptyErrorMessage).Error()
+	expected := &proto.FindByKeyObjectResponse{
+		Object: &proto.Object{
+			Key: findByKeyInput.Key,
+			Url: "url",
+		},
+	}
 
-	actual, err := srv.DeleteByKey(context.Background(), deleteByKeyInput)
+	actual, err := srv.FindByKey(context.Background(), findByKeyInput)
 
-	t.Equal(actual.Success, false)
-	t.EqualError(err, expectedErr)
+	t.Nil(err)
+	t.Equal(expected, actual)
 }
 
-func (t *ObjectServiceTest) TestDeleteByKeyInternalError() {
-	deleteByKeyInput := &proto.DeleteByKeyObjectRequest{
+func (t *ObjectServiceTest) TestFindByKeyCatalogInternalError() {
+	findByKeyInput := &proto.FindByKeyObjectRequest{
 		Key: "key",
 	}
 
 	repo := mock_object.NewMockRepository(t.controller)
-	repo.EXPECT().Delete(t.conf.BucketName, deleteByKeyInput.Key).Return(fmt.Errorf("error"))
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().ResolveAlias(gomock.Any(), t.conf.BucketName, findByKeyInput.Key).Return("", nil)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, findByKeyInput.Key, gomock.Any()).Return(fmt.Errorf("error"))
 
-	srv := object.NewService(repo, t.conf, t.logger, utils.NewRandomUtils())
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
 
 	expectedErr := status.Error(codes.Internal, constant.InternalServerErrorMessage).Error()
 
-	actual, err := srv.DeleteByKey(context.Background(), deleteByKeyInput)
+	actual, err := srv.FindByKey(context.Background(), findByKeyInput)
 
-	t.Equal(actual.Success, false)
-	t.EqualError(err, expectedErr)
+	t.Nil(actual)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
 }
 
-func (t *ObjectServiceTest) TestDeleteByKeySuccess() {
-	deleteByKeyInput := &proto.DeleteByKeyObjectRequest{
+func (t *ObjectServiceTest) TestFindByKeyAliasSuccess() {
+	findByKeyInput := &proto.FindByKeyObjectRequest{
+		Key: "users/1/avatar",
+	}
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().ResolveAlias(gomock.Any(), t.conf.BucketName, findByKeyInput.Key).Return("avatar_abc.png", nil)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, "avatar_abc.png", gomock.Any()).Return(nil)
+	repo.EXPECT().GetURL(t.conf.BucketName, "avatar_abc.png").Return("url")
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expected := &proto.FindByKeyObjectResponse{
+		Object: &proto.Object{
+			Key: "avatar_abc.png",
+			Url: "url",
+		},
+	}
+
+	actual, err := srv.FindByKey(context.Background(), findByKeyInput)
+
+	t.Nil(err)
+	t.Equal(expected, actual)
+}
+
+func (t *ObjectServiceTest) TestFindByKeyAliasInternalError() {
+	findByKeyInput := &proto.FindByKeyObjectRequest{
+		Key: "users/1/avatar",
+	}
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().ResolveAlias(gomock.Any(), t.conf.BucketName, findByKeyInput.Key).Return("", fmt.Errorf("error"))
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.Internal, constant.InternalServerErrorMessage).Error()
+
+	actual, err := srv.FindByKey(context.Background(), findByKeyInput)
+
+	t.Nil(actual)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestFindByKeyCacheHit() {
+	findByKeyInput := &proto.FindByKeyObjectRequest{
 		Key: "key",
 	}
 
 	repo := mock_object.NewMockRepository(t.controller)
-	repo.EXPECT().Delete(t.conf.BucketName, deleteByKeyInput.Key).Return(nil)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := cache.NewLRURepository(10)
+	t.Nil(cacheRepo.SetValue("alias:key", "", time.Minute))
+	t.Nil(cacheRepo.SetValue("object:key", map[string]interface{}{"found": true, "url": "url", "key": "key"}, time.Minute))
 
-	srv := object.NewService(repo, t.conf, t.logger, utils.NewRandomUtils())
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
 
-	actual, err := srv.DeleteByKey(context.Background(), deleteByKeyInput)
+	expected := &proto.FindByKeyObjectResponse{
+		Object: &proto.Object{
+			Key: findByKeyInput.Key,
+			Url: "url",
+		},
+	}
+
+	actual, err := srv.FindByKey(context.Background(), findByKeyInput)
 
 	t.Nil(err)
-	t.Equal(actual.Success, true)
+	t.Equal(expected, actual)
+}
+
+func (t *ObjectServiceTest) TestFindByKeyNegativeCache() {
+	findByKeyInput := &proto.FindByKeyObjectRequest{
+		Key: "key",
+	}
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	repo.EXPECT().ResolveAlias(gomock.Any(), t.conf.BucketName, findByKeyInput.Key).Return("", nil).Times(1)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, findByKeyInput.Key, gomock.Any()).Return(gorm.ErrRecordNotFound).Times(1)
+	repo.EXPECT().Get(gomock.Any(), t.conf.BucketName, findByKeyInput.Key).Return("", nil).Times(1)
+
+	srv := object.NewService(repo, catalogRepo, cache.NewLRURepository(10), t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.NotFound, constant.ObjectNotFoundErrorMessage).Error()
+
+	for i := 0; i < 2; i++ {
+		actual, err := srv.FindByKey(context.Background(), findByKeyInput)
+
+		t.Nil(actual)
+		t.EqualError(status.Convert(err).Err(), expectedErr)
+	}
+}
+
+func (t *ObjectServiceTest) TestFindByKeyCacheErrorFallsThrough() {
+	findByKeyInput := &proto.FindByKeyObjectRequest{
+		Key: "key",
+	}
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := mock_cache.NewMockRepository(t.controller)
+	cacheRepo.EXPECT().GetValue(gomock.Any(), gomock.Any()).Return(fmt.Errorf("error")).AnyTimes()
+	cacheRepo.EXPECT().SetValue(gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("error")).AnyTimes()
+	repo.EXPECT().ResolveAlias(gomock.Any(), t.conf.BucketName, findByKeyInput.Key).Return("", nil)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, findByKeyInput.Key, gomock.Any()).Return(nil)
+	repo.EXPECT().GetURL(t.conf.BucketName, findByKeyInput.Key).Return("url")
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	actual, err := srv.FindByKey(context.Background(), findByKeyInput)
+
+	t.Nil(err)
+	t.Equal("url", actual.Object.Url)
+}
+
+func (t *ObjectServiceTest) TestFindByKeyConcurrentLookupsShared() {
+	findByKeyInput := &proto.FindByKeyObjectRequest{
+		Key: "key",
+	}
+
+	release := make(chan struct{})
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().ResolveAlias(gomock.Any(), t.conf.BucketName, findByKeyInput.Key).DoAndReturn(func(_ context.Context, _ string, _ string) (string, error) {
+		<-release
+		return "", nil
+	}).Times(1)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, findByKeyInput.Key, gomock.Any()).Return(nil).Times(1)
+	repo.EXPECT().GetURL(t.conf.BucketName, findByKeyInput.Key).Return("url").Times(1)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			actual, err := srv.FindByKey(context.Background(), findByKeyInput)
+			t.Nil(err)
+			t.Equal("url", actual.Object.Url)
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+}
+
+func (t *ObjectServiceTest) TestSetAliasEmptyError() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.InvalidArgument, constant.AliasEmptyErrorMessage).Error()
+
+	actual, err := srv.SetAlias(context.Background(), &adminProto.SetAliasRequest{Key: "key"})
+
+	t.Nil(actual)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestSetAliasTargetNotFoundError() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().Get(gomock.Any(), t.conf.BucketName, "key").Return("", nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.NotFound, constant.ObjectNotFoundErrorMessage).Error()
+
+	actual, err := srv.SetAlias(context.Background(), &adminProto.SetAliasRequest{Alias: "users/1/avatar", Key: "key"})
+
+	t.Nil(actual)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestSetAliasSuccess() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().Get(gomock.Any(), t.conf.BucketName, "key").Return("url", nil)
+	repo.EXPECT().SetAlias(gomock.Any(), t.conf.BucketName, "users/1/avatar", "key").Return(nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expected := &adminProto.SetAliasResponse{
+		Object: &proto.Object{
+			Key: "key",
+			Url: "url",
+		},
+	}
+
+	actual, err := srv.SetAlias(context.Background(), &adminProto.SetAliasRequest{Alias: "users/1/avatar", Key: "key"})
+
+	t.Nil(err)
+	t.Equal(expected, actual)
+}
+
+func (t *ObjectServiceTest) TestDeleteByKeyEmptyError() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	deleteByKeyInput := &proto.DeleteByKeyObjectRequest{
+		Key: "",
+	}
+
+	expectedErr := status.Error(codes.InvalidArgument, constant.KeyEmptyErrorMessage).Error()
+
+	actual, err := srv.DeleteByKey(context.Background(), deleteByKeyInput)
+
+	t.Equal(actual.Success, false)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestDeleteByKeyInternalError() {
+	deleteByKeyInput := &proto.DeleteByKeyObjectRequest{
+		Key: "key",
+	}
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().GetRetention(gomock.Any(), t.conf.BucketName, deleteByKeyInput.Key, "").Return(&object.Retention{}, nil)
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, deleteByKeyInput.Key, gomock.Any()).DoAndReturn(func(_, _ string, record *model.Object) error {
+		*record = model.Object{Key: "key", Owner: "user", Category: "avatar", Size: 4}
+		return nil
+	})
+	catalogRepo.EXPECT().IncrementUsage("user", "avatar", int64(-4), int64(-1), int64(0), int64(0)).Return(true, nil)
+	catalogRepo.EXPECT().Delete(t.conf.BucketName, deleteByKeyInput.Key).Return(nil)
+	repo.EXPECT().Trash(gomock.Any(), t.conf.BucketName, deleteByKeyInput.Key).Return(fmt.Errorf("error"))
+	// the entry and its usage are brought back
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().Restore(t.conf.BucketName, deleteByKeyInput.Key).Return(nil)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, deleteByKeyInput.Key, gomock.Any()).DoAndReturn(func(_, _ string, record *model.Object) error {
+		*record = model.Object{Key: "key", Owner: "user", Category: "avatar", Size: 4}
+		return nil
+	})
+	catalogRepo.EXPECT().IncrementUsage("user", "avatar", int64(4), int64(1), int64(0), int64(0)).Return(true, nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.Internal, constant.InternalServerErrorMessage).Error()
+
+	actual, err := srv.DeleteByKey(context.Background(), deleteByKeyInput)
+
+	t.Equal(actual.Success, false)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestDeleteByKeyCatalogError() {
+	deleteByKeyInput := &proto.DeleteByKeyObjectRequest{
+		Key: "key",
+	}
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().GetRetention(gomock.Any(), t.conf.BucketName, deleteByKeyInput.Key, "").Return(&object.Retention{}, nil)
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, deleteByKeyInput.Key, gomock.Any()).DoAndReturn(func(_, _ string, record *model.Object) error {
+		*record = model.Object{Key: "key"}
+		return nil
+	})
+	catalogRepo.EXPECT().Delete(t.conf.BucketName, deleteByKeyInput.Key).Return(fmt.Errorf("error"))
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.Internal, constant.InternalServerErrorMessage).Error()
+
+	actual, err := srv.DeleteByKey(context.Background(), deleteByKeyInput)
+
+	t.Equal(actual.Success, false)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestDeleteByKeySuccess() {
+	deleteByKeyInput := &proto.DeleteByKeyObjectRequest{
+		Key: "key",
+	}
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().GetRetention(gomock.Any(), t.conf.BucketName, deleteByKeyInput.Key, "").Return(&object.Retention{}, nil)
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, deleteByKeyInput.Key, gomock.Any()).Return(gorm.ErrRecordNotFound)
+	repo.EXPECT().Trash(gomock.Any(), t.conf.BucketName, deleteByKeyInput.Key).Return(nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	actual, err := srv.DeleteByKey(context.Background(), deleteByKeyInput)
+
+	t.Nil(err)
+	t.Equal(actual.Success, true)
+}
+
+func (t *ObjectServiceTest) expectTransaction(catalogRepo *mock_catalog.MockRepository) {
+	catalogRepo.EXPECT().WithTransaction(gomock.Any()).DoAndReturn(func(txFunc func(catalog.Repository) error) error {
+		return txFunc(catalogRepo)
+	})
+}
+
+// expectLock grants (or, with locked false, refuses) the catalog lock to the next TryLock.
+func (t *ObjectServiceTest) expectLock(catalogRepo *mock_catalog.MockRepository, locked bool) {
+	catalogRepo.EXPECT().TryLock(gomock.Any(), gomock.Any()).DoAndReturn(func(_ string, fn func() error) (bool, error) {
+		if !locked {
+			return false, nil
+		}
+		return true, fn()
+	})
+}
+
+func (t *ObjectServiceTest) TestDeleteByKeyHardDeleteSuccess() {
+	deleteByKeyInput := &proto.DeleteByKeyObjectRequest{
+		Key: "key",
+	}
+	t.conf.HardDelete = true
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().ListVersions(gomock.Any(), t.conf.BucketName, deleteByKeyInput.Key).Return([]object.ObjectVersion{
+		{VersionID: "v2", IsLatest: true},
+		{VersionID: "v1"},
+	}, nil)
+	repo.EXPECT().GetRetention(gomock.Any(), t.conf.BucketName, deleteByKeyInput.Key, "v2").Return(&object.Retention{}, nil)
+	repo.EXPECT().GetRetention(gomock.Any(), t.conf.BucketName, deleteByKeyInput.Key, "v1").Return(&object.Retention{}, nil)
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, deleteByKeyInput.Key, gomock.Any()).Return(gorm.ErrRecordNotFound)
+	catalogRepo.EXPECT().Purge(t.conf.BucketName, deleteByKeyInput.Key).Return(nil)
+	repo.EXPECT().Purge(gomock.Any(), t.conf.BucketName, deleteByKeyInput.Key, false).Return(nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	actual, err := srv.DeleteByKey(context.Background(), deleteByKeyInput)
+
+	t.Nil(err)
+	t.Equal(actual.Success, true)
+}
+
+func (t *ObjectServiceTest) TestDeleteByKeyMissingSuccess() {
+	deleteByKeyInput := &proto.DeleteByKeyObjectRequest{
+		Key: "key",
+	}
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().GetRetention(gomock.Any(), t.conf.BucketName, deleteByKeyInput.Key, "").Return(nil, nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	actual, err := srv.DeleteByKey(context.Background(), deleteByKeyInput)
+
+	t.Nil(err)
+	t.Equal(actual.Success, true)
+}
+
+func (t *ObjectServiceTest) TestDeleteByKeyRetainedError() {
+	deleteByKeyInput := &proto.DeleteByKeyObjectRequest{
+		Key: "key",
+	}
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().GetRetention(gomock.Any(), t.conf.BucketName, deleteByKeyInput.Key, "").Return(&object.Retention{
+		Mode:        minio.Governance,
+		RetainUntil: time.Now().Add(time.Hour),
+	}, nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.FailedPrecondition, constant.ObjectRetainedErrorMessage).Error()
+
+	actual, err := srv.DeleteByKey(context.Background(), deleteByKeyInput)
+
+	t.Equal(actual.Success, false)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestForceDeleteBypassesGovernance() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().ListVersions(gomock.Any(), t.conf.BucketName, "key").Return([]object.ObjectVersion{
+		{VersionID: "v3", IsLatest: true, IsDeleteMarker: true},
+		{VersionID: "v2"},
+		{VersionID: "v1"},
+	}, nil)
+	for _, versionID := range []string{"v2", "v1"} {
+		repo.EXPECT().GetRetention(gomock.Any(), t.conf.BucketName, "key", versionID).Return(&object.Retention{
+			Mode:        minio.Governance,
+			RetainUntil: time.Now().Add(time.Hour),
+		}, nil)
+	}
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, "key", gomock.Any()).Return(gorm.ErrRecordNotFound)
+	catalogRepo.EXPECT().Purge(t.conf.BucketName, "key").Return(nil)
+	repo.EXPECT().Purge(gomock.Any(), t.conf.BucketName, "key", true).Return(nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	actual, err := srv.ForceDelete(context.Background(), &adminProto.ForceDeleteObjectRequest{Key: "key"})
+
+	t.Nil(err)
+	t.Equal(actual.Success, true)
+}
+
+func (t *ObjectServiceTest) TestForceDeletePurgeErrorUnhidesEntry() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().ListVersions(gomock.Any(), t.conf.BucketName, "key").Return([]object.ObjectVersion{
+		{VersionID: "v1", IsLatest: true},
+	}, nil)
+	repo.EXPECT().GetRetention(gomock.Any(), t.conf.BucketName, "key", "v1").Return(&object.Retention{}, nil)
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, "key", gomock.Any()).DoAndReturn(func(_, _ string, record *model.Object) error {
+		*record = model.Object{Key: "key"}
+		return nil
+	})
+	catalogRepo.EXPECT().Delete(t.conf.BucketName, "key").Return(nil)
+	repo.EXPECT().Purge(gomock.Any(), t.conf.BucketName, "key", true).Return(fmt.Errorf("error"))
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().Restore(t.conf.BucketName, "key").Return(nil)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, "key", gomock.Any()).DoAndReturn(func(_, _ string, record *model.Object) error {
+		*record = model.Object{Key: "key"}
+		return nil
+	})
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.Internal, constant.InternalServerErrorMessage).Error()
+
+	actual, err := srv.ForceDelete(context.Background(), &adminProto.ForceDeleteObjectRequest{Key: "key"})
+
+	t.Equal(actual.Success, false)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestForceDeleteComplianceError() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().ListVersions(gomock.Any(), t.conf.BucketName, "key").Return([]object.ObjectVersion{
+		{VersionID: "v2", IsLatest: true},
+		{VersionID: "v1"},
+	}, nil)
+	repo.EXPECT().GetRetention(gomock.Any(), t.conf.BucketName, "key", "v2").Return(&object.Retention{}, nil)
+	repo.EXPECT().GetRetention(gomock.Any(), t.conf.BucketName, "key", "v1").Return(&object.Retention{
+		Mode:        minio.Compliance,
+		RetainUntil: time.Now().Add(time.Hour),
+	}, nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.FailedPrecondition, constant.ObjectRetainedErrorMessage).Error()
+
+	actual, err := srv.ForceDelete(context.Background(), &adminProto.ForceDeleteObjectRequest{Key: "key"})
+
+	t.Equal(actual.Success, false)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestForceDeleteNotFoundError() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().ListVersions(gomock.Any(), t.conf.BucketName, "key").Return(nil, nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.NotFound, constant.ObjectNotFoundErrorMessage).Error()
+
+	actual, err := srv.ForceDelete(context.Background(), &adminProto.ForceDeleteObjectRequest{Key: "key"})
+
+	t.Equal(actual.Success, false)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestUploadAppliesCategoryRetention() {
+	t.conf.Retention = map[string]config.RetentionPolicy{
+		"evidence": {Mode: "COMPLIANCE", Period: time.Hour, LegalHold: true},
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-object-category", "evidence"))
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().Create(gomock.Any()).Return(nil)
+	repo.EXPECT().Upload(gomock.Any(), t.uploadObjectRequest.Data, t.conf.BucketName, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ []byte, _ string, _ string, opts object.UploadOptions) (string, string, string, error) {
+			t.Equal(minio.Compliance, opts.Retention.Mode)
+			t.True(opts.Retention.LegalHold)
+			t.WithinDuration(time.Now().Add(time.Hour), opts.Retention.RetainUntil, time.Minute)
+			return "url", "key", "", nil
+		})
+	catalogRepo.EXPECT().Commit(t.conf.BucketName, gomock.Any()).Return(nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	_, err := srv.Upload(ctx, t.uploadObjectRequest)
+
+	t.Nil(err)
+}
+
+func (t *ObjectServiceTest) TestRetentionLocked() {
+	now := time.Now()
+
+	t.False((&object.Retention{}).Locked(now, false))
+	t.True((&object.Retention{LegalHold: true}).Locked(now, true))
+	t.True((&object.Retention{Mode: minio.Governance, RetainUntil: now.Add(time.Hour)}).Locked(now, false))
+	t.False((&object.Retention{Mode: minio.Governance, RetainUntil: now.Add(time.Hour)}).Locked(now, true))
+	t.True((&object.Retention{Mode: minio.Compliance, RetainUntil: now.Add(time.Hour)}).Locked(now, true))
+	t.False((&object.Retention{Mode: minio.Compliance, RetainUntil: now.Add(-time.Hour)}).Locked(now, false))
+}
+
+func (t *ObjectServiceTest) TestRestoreEmptyError() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.InvalidArgument, constant.KeyEmptyErrorMessage).Error()
+
+	actual, err := srv.Restore(context.Background(), &adminProto.RestoreObjectRequest{})
+
+	t.Nil(actual)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestRestoreNotInTrashError() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().Restore(gomock.Any(), t.conf.BucketName, "key").Return("", nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.NotFound, constant.ObjectNotFoundErrorMessage).Error()
+
+	actual, err := srv.Restore(context.Background(), &adminProto.RestoreObjectRequest{Key: "key"})
+
+	t.Nil(actual)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestRestoreInternalError() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().Restore(gomock.Any(), t.conf.BucketName, "key").Return("", fmt.Errorf("error"))
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.Internal, constant.InternalServerErrorMessage).Error()
+
+	actual, err := srv.Restore(context.Background(), &adminProto.RestoreObjectRequest{Key: "key"})
+
+	t.Nil(actual)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestRestoreCatalogErrorTrashesAgain() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().Restore(gomock.Any(), t.conf.BucketName, "key").Return("url", nil)
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().Restore(t.conf.BucketName, "key").Return(fmt.Errorf("error"))
+	repo.EXPECT().Trash(gomock.Any(), t.conf.BucketName, "key").Return(nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.Internal, constant.InternalServerErrorMessage).Error()
+
+	actual, err := srv.Restore(context.Background(), &adminProto.RestoreObjectRequest{Key: "key"})
+
+	t.Nil(actual)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestRestoreSuccess() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().Restore(gomock.Any(), t.conf.BucketName, "key").Return("url", nil)
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().Restore(t.conf.BucketName, "key").Return(nil)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, "key", gomock.Any()).DoAndReturn(func(_, _ string, record *model.Object) error {
+		*record = model.Object{Key: "key", Owner: "user", Category: "default", Size: 4}
+		return nil
+	})
+	catalogRepo.EXPECT().IncrementUsage("user", "default", int64(4), int64(1), int64(0), int64(0)).Return(true, nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expected := &adminProto.RestoreObjectResponse{
+		Object: &proto.Object{
+			Key: "key",
+			Url: "url",
+		},
+	}
+
+	actual, err := srv.Restore(context.Background(), &adminProto.RestoreObjectRequest{Key: "key"})
+
+	t.Nil(err)
+	t.Equal(expected, actual)
+}
+
+func (t *ObjectServiceTest) TestPurgeTrashSuccess() {
+	t.conf.TrashRetention = 24 * time.Hour
+	t.conf.TrashBucketName = "mock-trash"
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	t.expectLock(catalogRepo, true)
+	repo.EXPECT().List(gomock.Any(), "mock-trash", "").Return([]object.StoredObject{
+		{Key: "expired", LastModified: time.Now().Add(-48 * time.Hour)},
+		{Key: "recent", LastModified: time.Now()},
+	}, nil)
+	repo.EXPECT().Purge(gomock.Any(), "mock-trash", "expired", false).Return(nil)
+	catalogRepo.EXPECT().Purge(t.conf.BucketName, "expired").Return(nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	purged, err := srv.PurgeTrash(context.Background())
+
+	t.Nil(err)
+	t.Equal(1, purged)
+}
+
+func (t *ObjectServiceTest) TestPurgeTrashListError() {
+	t.conf.TrashBucketName = "mock-trash"
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	t.expectLock(catalogRepo, true)
+	repo.EXPECT().List(gomock.Any(), "mock-trash", "").Return(nil, fmt.Errorf("error"))
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	purged, err := srv.PurgeTrash(context.Background())
+
+	t.NotNil(err)
+	t.Equal(0, purged)
+}
+
+func (t *ObjectServiceTest) TestPurgeTrashSkippedWhileLocked() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	t.expectLock(catalogRepo, false)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	purged, err := srv.PurgeTrash(context.Background())
+
+	t.Nil(err)
+	t.Equal(0, purged)
+}
+
+func (t *ObjectServiceTest) TestFindByKeyVersionSuccess() {
+	findByKeyInput := &proto.FindByKeyObjectRequest{
+		Key: "key",
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-version-id", "v1"))
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().ResolveAlias(gomock.Any(), t.conf.BucketName, findByKeyInput.Key).Return("", nil)
+	repo.EXPECT().GetVersion(gomock.Any(), t.conf.BucketName, findByKeyInput.Key, "v1").Return("url?versionId=v1", nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expected := &proto.FindByKeyObjectResponse{
+		Object: &proto.Object{
+			Key: findByKeyInput.Key,
+			Url: "url?versionId=v1",
+		},
+	}
+
+	actual, err := srv.FindByKey(ctx, findByKeyInput)
+
+	t.Nil(err)
+	t.Equal(expected, actual)
+}
+
+func (t *ObjectServiceTest) TestFindByKeyVersionNotFoundError() {
+	findByKeyInput := &proto.FindByKeyObjectRequest{
+		Key: "key",
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-version-id", "v1"))
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().ResolveAlias(gomock.Any(), t.conf.BucketName, findByKeyInput.Key).Return("", nil)
+	repo.EXPECT().GetVersion(gomock.Any(), t.conf.BucketName, findByKeyInput.Key, "v1").Return("", nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.NotFound, constant.ObjectNotFoundErrorMessage).Error()
+
+	actual, err := srv.FindByKey(ctx, findByKeyInput)
+
+	t.Nil(actual)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestDeleteByKeyVersionSuccess() {
+	deleteByKeyInput := &proto.DeleteByKeyObjectRequest{
+		Key: "key",
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-version-id", "v1"))
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().GetRetention(gomock.Any(), t.conf.BucketName, deleteByKeyInput.Key, "v1").Return(&object.Retention{}, nil)
+	repo.EXPECT().ListVersions(gomock.Any(), t.conf.BucketName, deleteByKeyInput.Key).Return([]object.ObjectVersion{
+		{VersionID: "v2", IsLatest: true},
+		{VersionID: "v1"},
+	}, nil)
+	repo.EXPECT().DeleteVersion(gomock.Any(), t.conf.BucketName, deleteByKeyInput.Key, "v1").Return(nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	actual, err := srv.DeleteByKey(ctx, deleteByKeyInput)
+
+	t.Nil(err)
+	t.Equal(actual.Success, true)
+}
+
+func (t *ObjectServiceTest) TestDeleteByKeyCurrentVersionMovesCatalog() {
+	deleteByKeyInput := &proto.DeleteByKeyObjectRequest{
+		Key: "key",
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-version-id", "v2"))
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().GetRetention(gomock.Any(), t.conf.BucketName, deleteByKeyInput.Key, "v2").Return(&object.Retention{}, nil)
+	repo.EXPECT().ListVersions(gomock.Any(), t.conf.BucketName, deleteByKeyInput.Key).Return([]object.ObjectVersion{
+		{VersionID: "v2", Size: 20, IsLatest: true},
+		{VersionID: "v1", Size: 10},
+	}, nil)
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, deleteByKeyInput.Key, gomock.Any()).DoAndReturn(
+		func(_ string, _ string, record *model.Object) error {
+			*record = model.Object{Owner: "user", Category: "profile", Size: 20}
+			return nil
+		})
+	catalogRepo.EXPECT().IncrementUsage("user", "profile", int64(-20), int64(-1), int64(0), int64(0)).Return(true, nil)
+	catalogRepo.EXPECT().Restore(t.conf.BucketName, deleteByKeyInput.Key).Return(nil)
+	catalogRepo.EXPECT().Resize(t.conf.BucketName, deleteByKeyInput.Key, int64(10)).Return(nil)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, deleteByKeyInput.Key, gomock.Any()).DoAndReturn(
+		func(_ string, _ string, record *model.Object) error {
+			*record = model.Object{Owner: "user", Category: "profile", Size: 10}
+			return nil
+		})
+	catalogRepo.EXPECT().IncrementUsage("user", "profile", int64(10), int64(1), int64(0), int64(0)).Return(true, nil)
+	repo.EXPECT().DeleteVersion(gomock.Any(), t.conf.BucketName, deleteByKeyInput.Key, "v2").Return(nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	actual, err := srv.DeleteByKey(ctx, deleteByKeyInput)
+
+	t.Nil(err)
+	t.Equal(actual.Success, true)
+}
+
+func (t *ObjectServiceTest) TestDeleteByKeyLastVersionPurgesCatalog() {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-version-id", "v1"))
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().GetRetention(gomock.Any(), t.conf.BucketName, "key", "v1").Return(&object.Retention{}, nil)
+	repo.EXPECT().ListVersions(gomock.Any(), t.conf.BucketName, "key").Return([]object.ObjectVersion{
+		{VersionID: "v1", IsLatest: true},
+	}, nil)
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, "key", gomock.Any()).Return(gorm.ErrRecordNotFound)
+	catalogRepo.EXPECT().Delete(t.conf.BucketName, "key").Return(nil)
+	repo.EXPECT().DeleteVersion(gomock.Any(), t.conf.BucketName, "key", "v1").Return(nil)
+	catalogRepo.EXPECT().Purge(t.conf.BucketName, "key").Return(nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	actual, err := srv.DeleteByKey(ctx, &proto.DeleteByKeyObjectRequest{Key: "key"})
+
+	t.Nil(err)
+	t.Equal(actual.Success, true)
+}
+
+func (t *ObjectServiceTest) TestDeleteByKeyCurrentVersionErrorResyncsCatalog() {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-version-id", "v2"))
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().GetRetention(gomock.Any(), t.conf.BucketName, "key", "v2").Return(&object.Retention{}, nil)
+	repo.EXPECT().ListVersions(gomock.Any(), t.conf.BucketName, "key").Return([]object.ObjectVersion{
+		{VersionID: "v2", Size: 20, IsLatest: true},
+		{VersionID: "v1", Size: 10},
+	}, nil)
+	// synced to v1 ahead of the delete
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, "key", gomock.Any()).Return(gorm.ErrRecordNotFound).Times(2)
+	catalogRepo.EXPECT().Restore(t.conf.BucketName, "key").Return(nil)
+	catalogRepo.EXPECT().Resize(t.conf.BucketName, "key", int64(10)).Return(nil)
+	repo.EXPECT().DeleteVersion(gomock.Any(), t.conf.BucketName, "key", "v2").Return(fmt.Errorf("error"))
+	// and back to v2 once it fails
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, "key", gomock.Any()).Return(gorm.ErrRecordNotFound).Times(2)
+	catalogRepo.EXPECT().Restore(t.conf.BucketName, "key").Return(nil)
+	catalogRepo.EXPECT().Resize(t.conf.BucketName, "key", int64(20)).Return(nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.Internal, constant.InternalServerErrorMessage).Error()
+
+	actual, err := srv.DeleteByKey(ctx, &proto.DeleteByKeyObjectRequest{Key: "key"})
+
+	t.Equal(actual.Success, false)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestListVersionsSuccess() {
+	versions := []object.ObjectVersion{{VersionID: "v2", IsLatest: true}, {VersionID: "v1"}}
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().ListVersions(gomock.Any(), t.conf.BucketName, "key").Return(versions, nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expected := &adminProto.ListVersionsResponse{
+		Versions: []*adminProto.ObjectVersion{
+			{VersionId: "v2", LastModified: timestamppb.New(time.Time{}), IsLatest: true},
+			{VersionId: "v1", LastModified: timestamppb.New(time.Time{})},
+		},
+	}
+
+	actual, err := srv.ListVersions(context.Background(), &adminProto.ListVersionsRequest{Key: "key"})
+
+	t.Nil(err)
+	t.Equal(expected, actual)
+}
+
+func (t *ObjectServiceTest) TestListVersionsNotFoundError() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().ListVersions(gomock.Any(), t.conf.BucketName, "key").Return(nil, nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.NotFound, constant.ObjectNotFoundErrorMessage).Error()
+
+	actual, err := srv.ListVersions(context.Background(), &adminProto.ListVersionsRequest{Key: "key"})
+
+	t.Nil(actual)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestRestoreVersionEmptyError() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.InvalidArgument, constant.VersionIdEmptyErrorMessage).Error()
+
+	actual, err := srv.RestoreVersion(context.Background(), &adminProto.RestoreVersionRequest{Key: "key"})
+
+	t.Nil(actual)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestRestoreVersionNotFoundError() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().ListVersions(gomock.Any(), t.conf.BucketName, "key").Return([]object.ObjectVersion{
+		{VersionID: "v2", IsLatest: true},
+	}, nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.NotFound, constant.ObjectNotFoundErrorMessage).Error()
+
+	actual, err := srv.RestoreVersion(context.Background(), &adminProto.RestoreVersionRequest{Key: "key", VersionId: "v1"})
+
+	t.Nil(actual)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestRestoreVersionMissingResyncsCatalog() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().ListVersions(gomock.Any(), t.conf.BucketName, "key").Return([]object.ObjectVersion{
+		{VersionID: "v2", Size: 20, IsLatest: true},
+		{VersionID: "v1", Size: 10},
+	}, nil)
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, "key", gomock.Any()).Return(gorm.ErrRecordNotFound).Times(2)
+	catalogRepo.EXPECT().Restore(t.conf.BucketName, "key").Return(nil)
+	catalogRepo.EXPECT().Resize(t.conf.BucketName, "key", int64(10)).Return(nil)
+	repo.EXPECT().RestoreVersion(gomock.Any(), t.conf.BucketName, "key", "v1").Return("", "", nil)
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, "key", gomock.Any()).Return(gorm.ErrRecordNotFound).Times(2)
+	catalogRepo.EXPECT().Restore(t.conf.BucketName, "key").Return(nil)
+	catalogRepo.EXPECT().Resize(t.conf.BucketName, "key", int64(20)).Return(nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.NotFound, constant.ObjectNotFoundErrorMessage).Error()
+
+	actual, err := srv.RestoreVersion(context.Background(), &adminProto.RestoreVersionRequest{Key: "key", VersionId: "v1"})
+
+	t.Nil(actual)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestRestoreVersionSuccess() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().ListVersions(gomock.Any(), t.conf.BucketName, "key").Return([]object.ObjectVersion{
+		{VersionID: "v2", Size: 20, IsLatest: true},
+		{VersionID: "v1", Size: 10},
+	}, nil)
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, "key", gomock.Any()).DoAndReturn(
+		func(_ string, _ string, record *model.Object) error {
+			*record = model.Object{Owner: "user", Category: "profile", Size: 20}
+			return nil
+		})
+	catalogRepo.EXPECT().IncrementUsage("user", "profile", int64(-20), int64(-1), int64(0), int64(0)).Return(true, nil)
+	catalogRepo.EXPECT().Restore(t.conf.BucketName, "key").Return(nil)
+	catalogRepo.EXPECT().Resize(t.conf.BucketName, "key", int64(10)).Return(nil)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, "key", gomock.Any()).DoAndReturn(
+		func(_ string, _ string, record *model.Object) error {
+			*record = model.Object{Owner: "user", Category: "profile", Size: 10}
+			return nil
+		})
+	catalogRepo.EXPECT().IncrementUsage("user", "profile", int64(10), int64(1), int64(0), int64(0)).Return(true, nil)
+	repo.EXPECT().RestoreVersion(gomock.Any(), t.conf.BucketName, "key", "v1").Return("url", "v3", nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expected := &adminProto.RestoreVersionResponse{
+		Object: &proto.Object{
+			Key: "key",
+			Url: "url",
+		},
+		VersionId: "v3",
+	}
+
+	actual, err := srv.RestoreVersion(context.Background(), &adminProto.RestoreVersionRequest{Key: "key", VersionId: "v1"})
+
+	t.Nil(err)
+	t.Equal(expected, actual)
+}
+
+func (t *ObjectServiceTest) newCacheMissRepository() *mock_cache.MockRepository {
+	cacheRepo := mock_cache.NewMockRepository(t.controller)
+	cacheRepo.EXPECT().GetValue(gomock.Any(), gomock.Any()).Return(cache.ErrCacheMiss).AnyTimes()
+	cacheRepo.EXPECT().SetValue(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	cacheRepo.EXPECT().DeleteValue(gomock.Any()).Return(nil).AnyTimes()
+	return cacheRepo
+}
+
+func (t *ObjectServiceTest) TestUploadQuotaExceeded() {
+	t.conf.Quotas = map[string]config.Quota{
+		"*": {MaxBytes: 1, MaxObjects: 10},
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-user-id", "user"))
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().Create(gomock.Any()).Return(nil)
+	catalogRepo.EXPECT().IncrementUsage("user", constant.DefaultCategory, int64(len(t.uploadObjectRequest.Data)), int64(1), int64(1), int64(10)).Return(false, nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.ResourceExhausted, constant.QuotaExceededErrorMessage).Error()
+
+	actual, err := srv.Upload(ctx, t.uploadObjectRequest)
+
+	t.Nil(actual)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+	t.Require().Len(status.Convert(err).Details(), 1)
+	violation := status.Convert(err).Details()[0].(*errdetails.QuotaFailure).Violations[0]
+	t.Equal("user:"+constant.DefaultCategory, violation.Subject)
+}
+
+func (t *ObjectServiceTest) TestUploadCategoryQuotaOverridesFallback() {
+	t.conf.Quotas = map[string]config.Quota{
+		"*":      {MaxBytes: 1},
+		"avatar": {MaxBytes: 100, MaxObjects: 1},
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-user-id", "user", "x-object-category", "avatar"))
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().Create(gomock.Any()).Return(nil)
+	catalogRepo.EXPECT().IncrementUsage("user", "avatar", int64(len(t.uploadObjectRequest.Data)), int64(1), int64(100), int64(1)).Return(true, nil)
+	repo.EXPECT().Upload(gomock.Any(), t.uploadObjectRequest.Data, t.conf.BucketName, gomock.Any(), gomock.Any()).Return("url", "key", "", nil)
+	catalogRepo.EXPECT().Commit(t.conf.BucketName, gomock.Any()).Return(nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	_, err := srv.Upload(ctx, t.uploadObjectRequest)
+
+	t.Nil(err)
+}
+
+func (t *ObjectServiceTest) TestDeleteByKeyReleasesUsage() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	repo.EXPECT().GetRetention(gomock.Any(), t.conf.BucketName, "key", "").Return(&object.Retention{}, nil)
+	t.expectTransaction(catalogRepo)
+	catalogRepo.EXPECT().FindByKey(t.conf.BucketName, "key", gomock.Any()).DoAndReturn(func(_, _ string, record *model.Object) error {
+		*record = model.Object{Key: "key", Owner: "user", Category: "avatar", Size: 4}
+		return nil
+	})
+	catalogRepo.EXPECT().IncrementUsage("user", "avatar", int64(-4), int64(-1), int64(0), int64(0)).Return(true, nil)
+	catalogRepo.EXPECT().Delete(t.conf.BucketName, "key").Return(nil)
+	repo.EXPECT().Trash(gomock.Any(), t.conf.BucketName, "key").Return(nil)
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	actual, err := srv.DeleteByKey(context.Background(), &proto.DeleteByKeyObjectRequest{Key: "key"})
+
+	t.Nil(err)
+	t.True(actual.Success)
+}
+
+func (t *ObjectServiceTest) TestGetUsageEmptyOwnerError() {
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expectedErr := status.Error(codes.InvalidArgument, constant.OwnerEmptyErrorMessage).Error()
+
+	actual, err := srv.GetUsage(context.Background(), &adminProto.GetUsageRequest{})
+
+	t.Nil(actual)
+	t.EqualError(status.Convert(err).Err(), expectedErr)
+}
+
+func (t *ObjectServiceTest) TestGetUsageSuccess() {
+	t.conf.Quotas = map[string]config.Quota{
+		"*":      {MaxBytes: 10},
+		"avatar": {MaxBytes: 100, MaxObjects: 1},
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-user-id", "user"))
+
+	repo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	cacheRepo := t.newCacheMissRepository()
+	catalogRepo.EXPECT().FindUsage("user", gomock.Any()).DoAndReturn(func(_ string, records *[]*model.Usage) error {
+		*records = []*model.Usage{
+			{Owner: "user", Category: "avatar", Bytes: 50, Objects: 1},
+			{Owner: "user", Category: "default", Bytes: 5, Objects: 2},
+		}
+		return nil
+	})
+
+	srv := object.NewService(repo, catalogRepo, cacheRepo, t.conf, t.cacheConf, t.logger, utils.NewRandomUtils())
+
+	expected := &adminProto.GetUsageResponse{
+		Usages: []*adminProto.Usage{
+			{Category: "avatar", Bytes: 50, Objects: 1, MaxBytes: 100, MaxObjects: 1},
+			{Category: "default", Bytes: 5, Objects: 2, MaxBytes: 10},
+		},
+	}
+
+	actual, err := srv.GetUsage(ctx, &adminProto.GetUsageRequest{})
+
+	t.Nil(err)
+	t.Equal(expected, actual)
 }
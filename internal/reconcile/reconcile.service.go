@@ -0,0 +1,223 @@
+package reconcile
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/isd-sgcu/rpkm67-store/constant"
+	"github.com/isd-sgcu/rpkm67-store/internal/catalog"
+	"github.com/isd-sgcu/rpkm67-store/internal/model"
+	"github.com/isd-sgcu/rpkm67-store/internal/object"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type Options struct {
+	// Prefix limits reconciliation to keys under it; referenced keys outside it are ignored.
+	Prefix string
+	// DeleteOrphans moves orphans older than MinAge to the trash bucket, from which the purge job
+	// removes them after the retention. When false the run only reports.
+	DeleteOrphans bool
+	MinAge        time.Duration
+	// FromCatalog tells that the referenced keys came from CatalogKeys. Orphans are then only
+	// deleted once the catalog has been backfilled, since objects stored before the catalog
+	// existed would otherwise all look like orphans.
+	FromCatalog bool
+}
+
+type Orphan struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+	// Deleted tells that the orphan was moved to the trash bucket.
+	Deleted bool `json:"deleted"`
+}
+
+type Report struct {
+	DryRun  bool      `json:"dry_run"`
+	Orphans []*Orphan `json:"orphans"`
+	Missing []string  `json:"missing"`
+}
+
+type Service interface {
+	// Reconcile compares the bucket against the set of referenced keys. Objects in the bucket
+	// that aren't referenced are orphans; referenced keys absent from the bucket are missing.
+	Reconcile(ctx context.Context, referenced map[string]struct{}, opts Options) (*Report, error)
+	CatalogKeys(prefix string) (map[string]struct{}, error)
+	// Backfill adds a catalog entry for every object under prefix that has none, returning how
+	// many were added. The catalog is marked as backfilled when the whole bucket was walked.
+	Backfill(ctx context.Context, prefix string) (added int, err error)
+}
+
+var ErrCatalogNotBackfilled = errors.New("catalog hasn't been backfilled")
+
+type serviceImpl struct {
+	conf        *config.Store
+	objectRepo  object.Repository
+	catalogRepo catalog.Repository
+	log         *zap.Logger
+}
+
+func NewService(objectRepo object.Repository, catalogRepo catalog.Repository, conf *config.Store, log *zap.Logger) Service {
+	return &serviceImpl{
+		conf:        conf,
+		objectRepo:  objectRepo,
+		catalogRepo: catalogRepo,
+		log:         log,
+	}
+}
+
+func (s *serviceImpl) Reconcile(ctx context.Context, referenced map[string]struct{}, opts Options) (*Report, error) {
+	if opts.DeleteOrphans && opts.FromCatalog {
+		backfilled, err := s.catalogRepo.IsBackfilled(s.conf.BucketName)
+		if err != nil {
+			return nil, errors.Wrap(err, "Couldn't check catalog backfill")
+		}
+		if !backfilled {
+			return nil, ErrCatalogNotBackfilled
+		}
+	}
+
+	stored, err := s.objectRepo.List(ctx, s.conf.BucketName, opts.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		DryRun:  !opts.DeleteOrphans,
+		Orphans: []*Orphan{},
+		Missing: []string{},
+	}
+	now := time.Now()
+	seen := make(map[string]struct{}, len(stored))
+
+	for _, obj := range stored {
+		if isInternal(obj.Key) {
+			continue
+		}
+		seen[obj.Key] = struct{}{}
+
+		if _, ok := referenced[obj.Key]; ok {
+			continue
+		}
+
+		orphan := &Orphan{
+			Key:          obj.Key,
+			Size:         obj.Size,
+			LastModified: obj.LastModified,
+		}
+		report.Orphans = append(report.Orphans, orphan)
+
+		if !opts.DeleteOrphans || now.Sub(obj.LastModified) < opts.MinAge {
+			continue
+		}
+
+		if err := s.deleteOrphan(ctx, obj.Key); err != nil {
+			s.log.Named("Reconcile").Error("deleteOrphan: ", zap.String("key", obj.Key), zap.Error(err))
+			continue
+		}
+		orphan.Deleted = true
+	}
+
+	for key := range referenced {
+		if !strings.HasPrefix(key, opts.Prefix) {
+			continue
+		}
+		if _, ok := seen[key]; !ok {
+			report.Missing = append(report.Missing, key)
+		}
+	}
+	sort.Strings(report.Missing)
+
+	return report, nil
+}
+
+// deleteOrphan moves the orphan to the trash bucket rather than deleting it, so that a wrongly
+// reported orphan can still be copied back until the purge job removes it.
+func (s *serviceImpl) deleteOrphan(ctx context.Context, key string) error {
+	if err := s.objectRepo.Trash(ctx, s.conf.BucketName, key); err != nil {
+		return err
+	}
+
+	// a referenced list from a keys file may leave a catalog entry behind the orphan
+	return s.catalogRepo.Delete(s.conf.BucketName, key)
+}
+
+func (s *serviceImpl) Backfill(ctx context.Context, prefix string) (added int, err error) {
+	stored, err := s.objectRepo.List(ctx, s.conf.BucketName, prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, obj := range stored {
+		if isInternal(obj.Key) {
+			continue
+		}
+
+		// the owner of an object stored before the catalog is unknown, so it isn't charged to anyone
+		created, err := s.catalogRepo.Backfill(&model.Object{
+			Bucket:    s.conf.BucketName,
+			Key:       obj.Key,
+			Category:  constant.DefaultCategory,
+			Size:      obj.Size,
+			CreatedAt: obj.LastModified,
+		})
+		if err != nil {
+			return added, errors.Wrap(err, fmt.Sprintf("Couldn't backfill %v", obj.Key))
+		}
+		if created {
+			added++
+		}
+	}
+
+	if prefix != "" {
+		return added, nil
+	}
+
+	if err := s.catalogRepo.CompleteBackfill(s.conf.BucketName); err != nil {
+		return added, errors.Wrap(err, "Couldn't mark catalog as backfilled")
+	}
+
+	return added, nil
+}
+
+// isInternal tells whether key belongs to the service itself rather than to a caller.
+func isInternal(key string) bool {
+	return strings.HasPrefix(key, constant.AliasPrefix) || strings.HasPrefix(key, constant.HealthPrefix)
+}
+
+func (s *serviceImpl) CatalogKeys(prefix string) (map[string]struct{}, error) {
+	var objects []*model.Object
+	if err := s.catalogRepo.List(s.conf.BucketName, prefix, 0, &objects); err != nil {
+		return nil, errors.Wrap(err, "Couldn't list catalog")
+	}
+
+	keys := make(map[string]struct{}, len(objects))
+	for _, obj := range objects {
+		keys[obj.Key] = struct{}{}
+	}
+
+	return keys, nil
+}
+
+// ReadKeys parses one key per line, ignoring blank lines and lines starting with '#'.
+func ReadKeys(r io.Reader) (map[string]struct{}, error) {
+	keys := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keys[line] = struct{}{}
+	}
+
+	return keys, scanner.Err()
+}
@@ -0,0 +1,216 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/isd-sgcu/rpkm67-store/constant"
+	"github.com/isd-sgcu/rpkm67-store/internal/model"
+	"github.com/isd-sgcu/rpkm67-store/internal/object"
+	"github.com/isd-sgcu/rpkm67-store/internal/reconcile"
+	mock_catalog "github.com/isd-sgcu/rpkm67-store/mocks/catalog"
+	mock_object "github.com/isd-sgcu/rpkm67-store/mocks/object"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type ReconcileServiceTest struct {
+	suite.Suite
+	controller *gomock.Controller
+	conf       *config.Store
+	logger     *zap.Logger
+	stored     []object.StoredObject
+	referenced map[string]struct{}
+}
+
+func TestReconcileService(t *testing.T) {
+	suite.Run(t, new(ReconcileServiceTest))
+}
+
+func (t *ReconcileServiceTest) SetupTest() {
+	t.controller = gomock.NewController(t.T())
+	t.conf = &config.Store{
+		BucketName: "mock-bucket",
+	}
+	t.logger = zap.NewNop()
+	t.stored = []object.StoredObject{
+		{Key: "kept", LastModified: time.Now().Add(-48 * time.Hour)},
+		{Key: "old-orphan", Size: 10, LastModified: time.Now().Add(-48 * time.Hour)},
+		{Key: "new-orphan", Size: 20, LastModified: time.Now()},
+		{Key: ".aliases/users/1/avatar", LastModified: time.Now().Add(-48 * time.Hour)},
+	}
+	t.referenced = map[string]struct{}{
+		"kept":    {},
+		"missing": {},
+	}
+}
+
+func (t *ReconcileServiceTest) TestReconcileDryRun() {
+	objectRepo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	objectRepo.EXPECT().List(gomock.Any(), t.conf.BucketName, "").Return(t.stored, nil)
+
+	svc := reconcile.NewService(objectRepo, catalogRepo, t.conf, t.logger)
+
+	report, err := svc.Reconcile(context.Background(), t.referenced, reconcile.Options{MinAge: 24 * time.Hour})
+
+	t.Nil(err)
+	t.True(report.DryRun)
+	t.Len(report.Orphans, 2)
+	t.Equal("old-orphan", report.Orphans[0].Key)
+	t.False(report.Orphans[0].Deleted)
+	t.Equal("new-orphan", report.Orphans[1].Key)
+	t.False(report.Orphans[1].Deleted)
+	t.Equal([]string{"missing"}, report.Missing)
+}
+
+func (t *ReconcileServiceTest) TestReconcileDeletesOldOrphans() {
+	objectRepo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	objectRepo.EXPECT().List(gomock.Any(), t.conf.BucketName, "").Return(t.stored, nil)
+	objectRepo.EXPECT().Trash(gomock.Any(), t.conf.BucketName, "old-orphan").Return(nil)
+	catalogRepo.EXPECT().Delete(t.conf.BucketName, "old-orphan").Return(nil)
+
+	svc := reconcile.NewService(objectRepo, catalogRepo, t.conf, t.logger)
+
+	report, err := svc.Reconcile(context.Background(), t.referenced, reconcile.Options{DeleteOrphans: true, MinAge: 24 * time.Hour})
+
+	t.Nil(err)
+	t.False(report.DryRun)
+	t.Len(report.Orphans, 2)
+	t.True(report.Orphans[0].Deleted)
+	t.False(report.Orphans[1].Deleted)
+}
+
+func (t *ReconcileServiceTest) TestReconcileDeleteError() {
+	objectRepo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	objectRepo.EXPECT().List(gomock.Any(), t.conf.BucketName, "").Return(t.stored, nil)
+	objectRepo.EXPECT().Trash(gomock.Any(), t.conf.BucketName, "old-orphan").Return(fmt.Errorf("error"))
+
+	svc := reconcile.NewService(objectRepo, catalogRepo, t.conf, t.logger)
+
+	report, err := svc.Reconcile(context.Background(), t.referenced, reconcile.Options{DeleteOrphans: true, MinAge: 24 * time.Hour})
+
+	t.Nil(err)
+	t.False(report.Orphans[0].Deleted)
+}
+
+func (t *ReconcileServiceTest) TestReconcileFromCatalogDeletesAfterBackfill() {
+	objectRepo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	catalogRepo.EXPECT().IsBackfilled(t.conf.BucketName).Return(true, nil)
+	objectRepo.EXPECT().List(gomock.Any(), t.conf.BucketName, "").Return(t.stored, nil)
+	objectRepo.EXPECT().Trash(gomock.Any(), t.conf.BucketName, "old-orphan").Return(nil)
+	catalogRepo.EXPECT().Delete(t.conf.BucketName, "old-orphan").Return(nil)
+
+	svc := reconcile.NewService(objectRepo, catalogRepo, t.conf, t.logger)
+
+	report, err := svc.Reconcile(context.Background(), t.referenced, reconcile.Options{DeleteOrphans: true, MinAge: 24 * time.Hour, FromCatalog: true})
+
+	t.Nil(err)
+	t.True(report.Orphans[0].Deleted)
+}
+
+func (t *ReconcileServiceTest) TestReconcileFromCatalogNotBackfilled() {
+	objectRepo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	catalogRepo.EXPECT().IsBackfilled(t.conf.BucketName).Return(false, nil)
+
+	svc := reconcile.NewService(objectRepo, catalogRepo, t.conf, t.logger)
+
+	report, err := svc.Reconcile(context.Background(), t.referenced, reconcile.Options{DeleteOrphans: true, FromCatalog: true})
+
+	t.Nil(report)
+	t.ErrorIs(err, reconcile.ErrCatalogNotBackfilled)
+}
+
+func (t *ReconcileServiceTest) TestReconcileListError() {
+	objectRepo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	objectRepo.EXPECT().List(gomock.Any(), t.conf.BucketName, "").Return(nil, fmt.Errorf("error"))
+
+	svc := reconcile.NewService(objectRepo, catalogRepo, t.conf, t.logger)
+
+	report, err := svc.Reconcile(context.Background(), t.referenced, reconcile.Options{})
+
+	t.Nil(report)
+	t.NotNil(err)
+}
+
+func (t *ReconcileServiceTest) TestCatalogKeys() {
+	objectRepo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	catalogRepo.EXPECT().List(t.conf.BucketName, "users/", 0, gomock.Any()).DoAndReturn(
+		func(_ string, _ string, _ int, objects *[]*model.Object) error {
+			*objects = []*model.Object{{Key: "users/a"}, {Key: "users/b"}}
+			return nil
+		})
+
+	svc := reconcile.NewService(objectRepo, catalogRepo, t.conf, t.logger)
+
+	keys, err := svc.CatalogKeys("users/")
+
+	t.Nil(err)
+	t.Equal(map[string]struct{}{"users/a": {}, "users/b": {}}, keys)
+}
+
+func (t *ReconcileServiceTest) TestBackfill() {
+	objectRepo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	objectRepo.EXPECT().List(gomock.Any(), t.conf.BucketName, "").Return(t.stored, nil)
+	catalogRepo.EXPECT().Backfill(&model.Object{
+		Bucket:    t.conf.BucketName,
+		Key:       "kept",
+		Category:  constant.DefaultCategory,
+		CreatedAt: t.stored[0].LastModified,
+	}).Return(false, nil)
+	catalogRepo.EXPECT().Backfill(gomock.Any()).Return(true, nil).Times(2)
+	catalogRepo.EXPECT().CompleteBackfill(t.conf.BucketName).Return(nil)
+
+	svc := reconcile.NewService(objectRepo, catalogRepo, t.conf, t.logger)
+
+	added, err := svc.Backfill(context.Background(), "")
+
+	t.Nil(err)
+	t.Equal(2, added)
+}
+
+func (t *ReconcileServiceTest) TestBackfillPrefixDoesNotComplete() {
+	objectRepo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	objectRepo.EXPECT().List(gomock.Any(), t.conf.BucketName, "users/").Return([]object.StoredObject{{Key: "users/a"}}, nil)
+	catalogRepo.EXPECT().Backfill(gomock.Any()).Return(true, nil)
+
+	svc := reconcile.NewService(objectRepo, catalogRepo, t.conf, t.logger)
+
+	added, err := svc.Backfill(context.Background(), "users/")
+
+	t.Nil(err)
+	t.Equal(1, added)
+}
+
+func (t *ReconcileServiceTest) TestBackfillError() {
+	objectRepo := mock_object.NewMockRepository(t.controller)
+	catalogRepo := mock_catalog.NewMockRepository(t.controller)
+	objectRepo.EXPECT().List(gomock.Any(), t.conf.BucketName, "").Return(t.stored, nil)
+	catalogRepo.EXPECT().Backfill(gomock.Any()).Return(false, fmt.Errorf("error"))
+
+	svc := reconcile.NewService(objectRepo, catalogRepo, t.conf, t.logger)
+
+	_, err := svc.Backfill(context.Background(), "")
+
+	t.NotNil(err)
+}
+
+func (t *ReconcileServiceTest) TestReadKeys() {
+	keys, err := reconcile.ReadKeys(strings.NewReader("a\n\n# comment\n  b  \n"))
+
+	t.Nil(err)
+	t.Equal(map[string]struct{}{"a": {}, "b": {}}, keys)
+}
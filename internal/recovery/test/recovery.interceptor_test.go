@@ -0,0 +1,76 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/isd-sgcu/rpkm67-store/constant"
+	"github.com/isd-sgcu/rpkm67-store/internal/recovery"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type RecoveryInterceptorTest struct {
+	suite.Suite
+	log  *zap.Logger
+	logs *observer.ObservedLogs
+	info *grpc.UnaryServerInfo
+}
+
+func TestRecoveryInterceptor(t *testing.T) {
+	suite.Run(t, new(RecoveryInterceptorTest))
+}
+
+func (t *RecoveryInterceptorTest) SetupTest() {
+	core, logs := observer.New(zapcore.DebugLevel)
+	t.log = zap.New(core)
+	t.logs = logs
+	t.info = &grpc.UnaryServerInfo{FullMethod: "/rpkm67.file.image.v1.ObjectService/FindByKey"}
+}
+
+func (t *RecoveryInterceptorTest) TestPanicReturnsInternal() {
+	resp, err := recovery.UnaryServerInterceptor(t.log)(context.Background(), nil, t.info, func(context.Context, interface{}) (interface{}, error) {
+		panic("boom")
+	})
+
+	t.Nil(resp)
+	t.Equal(codes.Internal, status.Code(err))
+	t.Equal(constant.InternalServerErrorMessage, status.Convert(err).Message())
+	t.Require().Len(t.logs.All(), 1)
+	entry := t.logs.All()[0]
+	t.Equal(zapcore.ErrorLevel, entry.Level)
+	t.Equal("boom", entry.ContextMap()["panic"])
+	t.Contains(entry.ContextMap()["stack"], "TestPanicReturnsInternal")
+}
+
+func (t *RecoveryInterceptorTest) TestPassesThrough() {
+	resp, err := recovery.UnaryServerInterceptor(t.log)(context.Background(), nil, t.info, func(context.Context, interface{}) (interface{}, error) {
+		return "response", status.Error(codes.NotFound, "error")
+	})
+
+	t.Equal("response", resp)
+	t.Equal(codes.NotFound, status.Code(err))
+	t.Empty(t.logs.All())
+}
+
+func (t *RecoveryInterceptorTest) TestStreamPanicReturnsInternal() {
+	err := recovery.StreamServerInterceptor(t.log)(nil, &serverStream{}, &grpc.StreamServerInfo{}, func(interface{}, grpc.ServerStream) error {
+		panic("boom")
+	})
+
+	t.Equal(codes.Internal, status.Code(err))
+	t.Require().Len(t.logs.All(), 1)
+}
+
+type serverStream struct {
+	grpc.ServerStream
+}
+
+func (s *serverStream) Context() context.Context {
+	return context.Background()
+}
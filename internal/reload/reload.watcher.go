@@ -0,0 +1,137 @@
+package reload
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"go.uber.org/zap"
+)
+
+// Target applies the reloadable part of a new configuration. Targets swap their settings in
+// atomically, so calls in flight are never interrupted. An error leaves the target as it was.
+type Target func(conf *config.Config) error
+
+type Watcher interface {
+	// Run reloads on SIGHUP and whenever a file the configuration was read from changes, until ctx is done.
+	Run(ctx context.Context)
+	// Reload loads the configuration again and hands it to every target.
+	Reload() error
+}
+
+type watcherImpl struct {
+	mu       sync.Mutex
+	load     func() (*config.Config, error)
+	targets  []Target
+	interval time.Duration
+	modTimes map[string]time.Time
+	log      *zap.Logger
+}
+
+// NewWatcher returns a Watcher that reloads with load, which fails rather than handing an
+// invalid configuration to the targets. conf is the configuration currently in use.
+func NewWatcher(load func() (*config.Config, error), conf *config.Config, log *zap.Logger, targets ...Target) Watcher {
+	return &watcherImpl{
+		load:     load,
+		targets:  targets,
+		interval: conf.App.ReloadInterval,
+		modTimes: statFiles(watchedFiles(conf)),
+		log:      log,
+	}
+}
+
+func (w *watcherImpl) Run(ctx context.Context) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	var tick <-chan time.Time
+	if w.interval > 0 {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sighup:
+			w.log.Named("Run").Info("Reloading configuration on SIGHUP")
+		case <-tick:
+			if !w.changed() {
+				continue
+			}
+			w.log.Named("Run").Info("Reloading configuration on file change")
+		}
+
+		if err := w.Reload(); err != nil {
+			w.log.Named("Run").Error("Failed to reload configuration", zap.Error(err))
+			continue
+		}
+		w.log.Named("Run").Info("Reloaded configuration")
+	}
+}
+
+func (w *watcherImpl) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	conf, err := w.load()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, target := range w.targets {
+		if err := target(conf); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	w.modTimes = statFiles(watchedFiles(conf))
+
+	return errors.Join(errs...)
+}
+
+func (w *watcherImpl) changed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for file, modTime := range w.modTimes {
+		info, err := os.Stat(file)
+		if err != nil || !info.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// watchedFiles are the files the configuration was read from, plus the authorization policy.
+func watchedFiles(conf *config.Config) []string {
+	files := append([]string{}, conf.Files...)
+	if conf.Auth.PolicyFile != "" {
+		files = append(files, conf.Auth.PolicyFile)
+	}
+
+	return files
+}
+
+// statFiles records the modification times of files; missing files are recorded as the zero time.
+func statFiles(files []string) map[string]time.Time {
+	modTimes := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			modTimes[file] = info.ModTime()
+		} else {
+			modTimes[file] = time.Time{}
+		}
+	}
+
+	return modTimes
+}
@@ -0,0 +1,98 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/isd-sgcu/rpkm67-store/internal/reload"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type WatcherTest struct {
+	suite.Suite
+	logger *zap.Logger
+	file   string
+	conf   *config.Config
+}
+
+func TestWatcher(t *testing.T) {
+	suite.Run(t, new(WatcherTest))
+}
+
+func (t *WatcherTest) SetupTest() {
+	t.logger = zap.NewNop()
+	t.file = filepath.Join(t.T().TempDir(), "config.yaml")
+	t.Require().Nil(os.WriteFile(t.file, []byte("app:\n  max_file_size_mb: 10\n"), 0o600))
+	t.conf = &config.Config{
+		App:   config.App{ReloadInterval: 10 * time.Millisecond},
+		Store: config.Store{MaxFileSize: 10},
+		Files: []string{t.file},
+	}
+}
+
+func (t *WatcherTest) TestReloadAppliesTargets() {
+	next := &config.Config{Store: config.Store{MaxFileSize: 20}}
+	var applied []int64
+	target := func(c *config.Config) error {
+		applied = append(applied, c.Store.MaxFileSize)
+		return nil
+	}
+	watcher := reload.NewWatcher(func() (*config.Config, error) { return next, nil }, t.conf, t.logger, target, target)
+
+	t.Nil(watcher.Reload())
+	t.Equal([]int64{20, 20}, applied)
+}
+
+func (t *WatcherTest) TestReloadLoadErrorSkipsTargets() {
+	called := false
+	watcher := reload.NewWatcher(func() (*config.Config, error) {
+		return nil, errors.New("invalid configuration")
+	}, t.conf, t.logger, func(c *config.Config) error {
+		called = true
+		return nil
+	})
+
+	t.EqualError(watcher.Reload(), "invalid configuration")
+	t.False(called)
+}
+
+func (t *WatcherTest) TestReloadTargetErrorAppliesOthers() {
+	called := false
+	watcher := reload.NewWatcher(func() (*config.Config, error) { return t.conf, nil }, t.conf, t.logger,
+		func(c *config.Config) error { return errors.New("policy") },
+		func(c *config.Config) error {
+			called = true
+			return nil
+		},
+	)
+
+	t.EqualError(watcher.Reload(), "policy")
+	t.True(called)
+}
+
+func (t *WatcherTest) TestRunReloadsOnFileChange() {
+	var reloads atomic.Int32
+	watcher := reload.NewWatcher(func() (*config.Config, error) {
+		reloads.Add(1)
+		return t.conf, nil
+	}, t.conf, t.logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watcher.Run(ctx)
+
+	time.Sleep(30 * time.Millisecond)
+	t.Equal(int32(0), reloads.Load())
+
+	modTime := time.Now().Add(time.Minute)
+	t.Require().Nil(os.Chtimes(t.file, modTime, modTime))
+
+	t.Eventually(func() bool { return reloads.Load() == 1 }, time.Second, 5*time.Millisecond)
+}
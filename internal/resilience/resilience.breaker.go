@@ -0,0 +1,153 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"go.uber.org/zap"
+)
+
+// ErrCircuitOpen is returned without calling the backend while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("storage backend is unavailable")
+
+type breakerState int
+
+const (
+	stateClosed breakerState = iota
+	stateOpen
+	stateHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case stateOpen:
+		return "open"
+	case stateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// breaker opens after threshold consecutive transient failures. Once cooldown has passed it
+// lets a single trial call through, closing again if it succeeds and reopening if it doesn't.
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     breakerState
+	failures  int
+	openedAt  time.Time
+	trial     bool
+	log       *zap.Logger
+}
+
+func newBreaker(threshold int, cooldown time.Duration, log *zap.Logger) *breaker {
+	return &breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		log:       log,
+	}
+}
+
+// allow reports whether a call may go to the backend; every allowed call must be recorded.
+func (b *breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case stateClosed:
+		return nil
+	case stateOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.transition(stateHalfOpen)
+	}
+
+	if b.trial {
+		return ErrCircuitOpen
+	}
+	b.trial = true
+
+	return nil
+}
+
+// rejecting reports whether the breaker is open, without taking up the trial call.
+func (b *breaker) rejecting() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == stateOpen && time.Since(b.openedAt) < b.cooldown {
+		return ErrCircuitOpen
+	}
+
+	return nil
+}
+
+func (b *breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	failed := isTransient(err)
+	if b.state == stateHalfOpen {
+		b.trial = false
+		if failed {
+			b.open()
+		} else {
+			b.failures = 0
+			b.transition(stateClosed)
+		}
+		return
+	}
+
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == stateClosed && b.failures >= b.threshold {
+		b.open()
+	}
+}
+
+func (b *breaker) open() {
+	b.openedAt = time.Now()
+	b.transition(stateOpen)
+}
+
+func (b *breaker) transition(state breakerState) {
+	if b.state == state {
+		return
+	}
+	b.log.Named("breaker").Warn("Circuit breaker changed state", zap.Stringer("from", b.state), zap.Stringer("to", state))
+	b.state = state
+}
+
+// isTransient reports whether err is a failure of the backend that may go away on its own:
+// throttling, 5xx responses and network errors. Errors about the request itself, such as a
+// missing object, and cancelled or expired contexts are not.
+func isTransient(err error) bool {
+	if err == nil || errors.Is(err, ErrCircuitOpen) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	resp := minio.ToErrorResponse(err)
+	switch resp.Code {
+	case "RequestTimeout", "SlowDown", "Throttling", "InternalError", "ServiceUnavailable", "XMinioServerNotInitialized":
+		return true
+	}
+	switch resp.StatusCode {
+	case 0:
+		// not a response from the backend, i.e. the request never completed
+		return resp.Code == ""
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
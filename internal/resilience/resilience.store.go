@@ -0,0 +1,154 @@
+package resilience
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"time"
+
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/isd-sgcu/rpkm67-store/internal/client/store"
+	"github.com/minio/minio-go/v7"
+	"go.uber.org/zap"
+)
+
+type storeClient struct {
+	client  store.Client
+	conf    *config.Store
+	breaker *breaker
+}
+
+// NewStoreClient wraps client to retry transient backend failures with jittered exponential
+// backoff and to fail fast with ErrCircuitOpen while the backend keeps failing. Reads, removals of
+// a given version and writes are retried; writes go to keys with a random suffix, or to the trash
+// copy of one, so repeating one stores the same data again. Removing the latest version is not,
+// as each attempt that reaches the backend adds a delete marker.
+func NewStoreClient(client store.Client, conf *config.Store, log *zap.Logger) store.Client {
+	return &storeClient{
+		client:  client,
+		conf:    conf,
+		breaker: newBreaker(conf.BreakerFailures, conf.BreakerCooldown, log),
+	}
+}
+
+// call runs op until it succeeds, fails for a reason retrying won't fix, runs out of attempts,
+// or the next attempt wouldn't start before ctx expires. It returns the last error.
+func (c *storeClient) call(ctx context.Context, attempts int, op func() error) error {
+	for attempt := 1; ; attempt++ {
+		if err := c.breaker.allow(); err != nil {
+			return err
+		}
+		err := op()
+		c.breaker.record(err)
+		if !isTransient(err) || attempt >= attempts {
+			return err
+		}
+
+		delay := c.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff doubles the base delay per attempt up to the maximum, then picks a random delay
+// in its upper half so that callers failing together don't retry together.
+func (c *storeClient) backoff(attempt int) time.Duration {
+	delay := c.conf.RetryMaxDelay
+	if shift := attempt - 1; shift < 32 && c.conf.RetryBaseDelay<<shift < delay {
+		delay = c.conf.RetryBaseDelay << shift
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// PutObject is only retried when reader can be rewound to send the object again.
+func (c *storeClient) PutObject(ctx context.Context, bucketName string, objectName string, reader io.Reader, objectSize int64, opts minio.PutObjectOptions) (info minio.UploadInfo, err error) {
+	attempts := 1
+	seeker, ok := reader.(io.Seeker)
+	var offset int64
+	if ok {
+		if offset, err = seeker.Seek(0, io.SeekCurrent); err == nil {
+			attempts = c.conf.RetryAttempts
+		}
+	}
+
+	attempt := 0
+	err = c.call(ctx, attempts, func() error {
+		if attempt++; attempt > 1 {
+			if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		info, err = c.client.PutObject(ctx, bucketName, objectName, reader, objectSize, opts)
+		return err
+	})
+
+	return info, err
+}
+
+// RemoveObject is only retried for a version; removing the latest object adds a delete marker.
+func (c *storeClient) RemoveObject(ctx context.Context, bucketName string, objectName string, opts minio.RemoveObjectOptions) error {
+	attempts := 1
+	if opts.VersionID != "" {
+		attempts = c.conf.RetryAttempts
+	}
+
+	return c.call(ctx, attempts, func() error {
+		return c.client.RemoveObject(ctx, bucketName, objectName, opts)
+	})
+}
+
+func (c *storeClient) StatObject(ctx context.Context, bucketName string, objectName string, opts minio.StatObjectOptions) (info minio.ObjectInfo, err error) {
+	err = c.call(ctx, c.conf.RetryAttempts, func() error {
+		var err error
+		info, err = c.client.StatObject(ctx, bucketName, objectName, opts)
+		return err
+	})
+
+	return info, err
+}
+
+// ListObjects is only guarded by the breaker; the listing is streamed, so it can't be retried.
+func (c *storeClient) ListObjects(ctx context.Context, bucketName string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+	if err := c.breaker.rejecting(); err != nil {
+		objects := make(chan minio.ObjectInfo, 1)
+		objects <- minio.ObjectInfo{Err: err}
+		close(objects)
+		return objects
+	}
+
+	return c.client.ListObjects(ctx, bucketName, opts)
+}
+
+func (c *storeClient) CopyObject(ctx context.Context, dst minio.CopyDestOptions, src minio.CopySrcOptions) (info minio.UploadInfo, err error) {
+	err = c.call(ctx, c.conf.RetryAttempts, func() error {
+		var err error
+		info, err = c.client.CopyObject(ctx, dst, src)
+		return err
+	})
+
+	return info, err
+}
+
+func (c *storeClient) BucketExists(ctx context.Context, bucketName string) (exists bool, err error) {
+	err = c.call(ctx, c.conf.RetryAttempts, func() error {
+		var err error
+		exists, err = c.client.BucketExists(ctx, bucketName)
+		return err
+	})
+
+	return exists, err
+}
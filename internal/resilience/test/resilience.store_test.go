@@ -0,0 +1,204 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/isd-sgcu/rpkm67-store/internal/resilience"
+	mock_store "github.com/isd-sgcu/rpkm67-store/mocks/client/store"
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type StoreClientTest struct {
+	suite.Suite
+	controller  *gomock.Controller
+	conf        *config.Store
+	logger      *zap.Logger
+	unavailable error
+	notFound    error
+}
+
+func TestStoreClient(t *testing.T) {
+	suite.Run(t, new(StoreClientTest))
+}
+
+func (t *StoreClientTest) SetupTest() {
+	t.controller = gomock.NewController(t.T())
+	t.logger = zap.NewNop()
+	t.conf = &config.Store{
+		BucketName:      "mock-bucket",
+		RetryAttempts:   3,
+		RetryBaseDelay:  time.Millisecond,
+		RetryMaxDelay:   2 * time.Millisecond,
+		BreakerFailures: 3,
+		BreakerCooldown: 50 * time.Millisecond,
+	}
+	t.unavailable = minio.ErrorResponse{StatusCode: http.StatusServiceUnavailable, Code: "ServiceUnavailable"}
+	t.notFound = minio.ErrorResponse{StatusCode: http.StatusNotFound, Code: "NoSuchKey"}
+}
+
+func (t *StoreClientTest) TestRetriesTransientError() {
+	client := mock_store.NewMockClient(t.controller)
+	gomock.InOrder(
+		client.EXPECT().StatObject(gomock.Any(), t.conf.BucketName, "key", gomock.Any()).Return(minio.ObjectInfo{}, t.unavailable),
+		client.EXPECT().StatObject(gomock.Any(), t.conf.BucketName, "key", gomock.Any()).Return(minio.ObjectInfo{Key: "key"}, nil),
+	)
+
+	info, err := resilience.NewStoreClient(client, t.conf, t.logger).StatObject(context.Background(), t.conf.BucketName, "key", minio.StatObjectOptions{})
+
+	t.Nil(err)
+	t.Equal("key", info.Key)
+}
+
+func (t *StoreClientTest) TestDoesNotRetryPermanentError() {
+	client := mock_store.NewMockClient(t.controller)
+	client.EXPECT().StatObject(gomock.Any(), t.conf.BucketName, "key", gomock.Any()).Return(minio.ObjectInfo{}, t.notFound).Times(1)
+
+	_, err := resilience.NewStoreClient(client, t.conf, t.logger).StatObject(context.Background(), t.conf.BucketName, "key", minio.StatObjectOptions{})
+
+	t.Equal(t.notFound, err)
+}
+
+func (t *StoreClientTest) TestGivesUpAfterAttempts() {
+	client := mock_store.NewMockClient(t.controller)
+	client.EXPECT().RemoveObject(gomock.Any(), t.conf.BucketName, "key", minio.RemoveObjectOptions{VersionID: "v1"}).Return(t.unavailable).Times(t.conf.RetryAttempts)
+
+	err := resilience.NewStoreClient(client, t.conf, t.logger).RemoveObject(context.Background(), t.conf.BucketName, "key", minio.RemoveObjectOptions{VersionID: "v1"})
+
+	t.Equal(t.unavailable, err)
+}
+
+func (t *StoreClientTest) TestRemoveObjectWithoutVersionNotRetried() {
+	client := mock_store.NewMockClient(t.controller)
+	client.EXPECT().RemoveObject(gomock.Any(), t.conf.BucketName, "key", gomock.Any()).Return(t.unavailable).Times(1)
+
+	err := resilience.NewStoreClient(client, t.conf, t.logger).RemoveObject(context.Background(), t.conf.BucketName, "key", minio.RemoveObjectOptions{})
+
+	t.Equal(t.unavailable, err)
+}
+
+func (t *StoreClientTest) TestStopsRetryingAtDeadline() {
+	t.conf.RetryBaseDelay = time.Hour
+	t.conf.RetryMaxDelay = time.Hour
+
+	client := mock_store.NewMockClient(t.controller)
+	client.EXPECT().BucketExists(gomock.Any(), t.conf.BucketName).Return(false, t.unavailable).Times(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := resilience.NewStoreClient(client, t.conf, t.logger).BucketExists(ctx, t.conf.BucketName)
+
+	t.Equal(t.unavailable, err)
+}
+
+func (t *StoreClientTest) TestPutObjectRewindsReader() {
+	client := mock_store.NewMockClient(t.controller)
+	var sent [][]byte
+	put := func(ctx context.Context, bucketName string, objectName string, reader io.Reader, objectSize int64, opts minio.PutObjectOptions) (minio.UploadInfo, error) {
+		data, err := io.ReadAll(reader)
+		t.Require().Nil(err)
+		sent = append(sent, data)
+		if len(sent) == 1 {
+			return minio.UploadInfo{}, t.unavailable
+		}
+		return minio.UploadInfo{Key: objectName}, nil
+	}
+	client.EXPECT().PutObject(gomock.Any(), t.conf.BucketName, "key", gomock.Any(), int64(4), gomock.Any()).DoAndReturn(put).Times(2)
+
+	info, err := resilience.NewStoreClient(client, t.conf, t.logger).PutObject(context.Background(), t.conf.BucketName, "key", bytes.NewReader([]byte("data")), 4, minio.PutObjectOptions{})
+
+	t.Nil(err)
+	t.Equal("key", info.Key)
+	t.Equal([][]byte{[]byte("data"), []byte("data")}, sent)
+}
+
+func (t *StoreClientTest) TestPutObjectNotRetriedWithoutSeeker() {
+	client := mock_store.NewMockClient(t.controller)
+	client.EXPECT().PutObject(gomock.Any(), t.conf.BucketName, "key", gomock.Any(), int64(4), gomock.Any()).Return(minio.UploadInfo{}, t.unavailable).Times(1)
+
+	reader := io.MultiReader(bytes.NewReader([]byte("data")))
+	_, err := resilience.NewStoreClient(client, t.conf, t.logger).PutObject(context.Background(), t.conf.BucketName, "key", reader, 4, minio.PutObjectOptions{})
+
+	t.Equal(t.unavailable, err)
+}
+
+func (t *StoreClientTest) TestCopyObjectRetriesTransientError() {
+	client := mock_store.NewMockClient(t.controller)
+	gomock.InOrder(
+		client.EXPECT().CopyObject(gomock.Any(), gomock.Any(), gomock.Any()).Return(minio.UploadInfo{}, t.unavailable),
+		client.EXPECT().CopyObject(gomock.Any(), gomock.Any(), gomock.Any()).Return(minio.UploadInfo{Key: "copy"}, nil),
+	)
+
+	info, err := resilience.NewStoreClient(client, t.conf, t.logger).CopyObject(context.Background(), minio.CopyDestOptions{Bucket: t.conf.BucketName, Object: "copy"}, minio.CopySrcOptions{Bucket: t.conf.BucketName, Object: "key"})
+
+	t.Nil(err)
+	t.Equal("copy", info.Key)
+}
+
+func (t *StoreClientTest) TestBreakerOpensAndRecovers() {
+	t.conf.RetryAttempts = 1
+
+	client := mock_store.NewMockClient(t.controller)
+	storeClient := resilience.NewStoreClient(client, t.conf, t.logger)
+	client.EXPECT().StatObject(gomock.Any(), t.conf.BucketName, "key", gomock.Any()).Return(minio.ObjectInfo{}, t.unavailable).Times(t.conf.BreakerFailures)
+	for i := 0; i < t.conf.BreakerFailures; i++ {
+		_, err := storeClient.StatObject(context.Background(), t.conf.BucketName, "key", minio.StatObjectOptions{})
+		t.Equal(t.unavailable, err)
+	}
+
+	// open: calls fail without reaching the backend
+	_, err := storeClient.StatObject(context.Background(), t.conf.BucketName, "key", minio.StatObjectOptions{})
+	t.ErrorIs(err, resilience.ErrCircuitOpen)
+	for info := range storeClient.ListObjects(context.Background(), t.conf.BucketName, minio.ListObjectsOptions{}) {
+		t.ErrorIs(info.Err, resilience.ErrCircuitOpen)
+	}
+
+	// half-open after the cooldown: a successful trial closes the breaker
+	time.Sleep(t.conf.BreakerCooldown)
+	client.EXPECT().StatObject(gomock.Any(), t.conf.BucketName, "key", gomock.Any()).Return(minio.ObjectInfo{Key: "key"}, nil).Times(2)
+	_, err = storeClient.StatObject(context.Background(), t.conf.BucketName, "key", minio.StatObjectOptions{})
+	t.Nil(err)
+	_, err = storeClient.StatObject(context.Background(), t.conf.BucketName, "key", minio.StatObjectOptions{})
+	t.Nil(err)
+}
+
+func (t *StoreClientTest) TestBreakerReopensOnFailedTrial() {
+	t.conf.RetryAttempts = 1
+	t.conf.BreakerFailures = 1
+
+	client := mock_store.NewMockClient(t.controller)
+	storeClient := resilience.NewStoreClient(client, t.conf, t.logger)
+	client.EXPECT().BucketExists(gomock.Any(), t.conf.BucketName).Return(false, t.unavailable).Times(2)
+
+	_, err := storeClient.BucketExists(context.Background(), t.conf.BucketName)
+	t.Equal(t.unavailable, err)
+
+	time.Sleep(t.conf.BreakerCooldown)
+	_, err = storeClient.BucketExists(context.Background(), t.conf.BucketName)
+	t.Equal(t.unavailable, err)
+
+	_, err = storeClient.BucketExists(context.Background(), t.conf.BucketName)
+	t.ErrorIs(err, resilience.ErrCircuitOpen)
+}
+
+func (t *StoreClientTest) TestNotFoundDoesNotTripBreaker() {
+	t.conf.RetryAttempts = 1
+	t.conf.BreakerFailures = 1
+
+	client := mock_store.NewMockClient(t.controller)
+	storeClient := resilience.NewStoreClient(client, t.conf, t.logger)
+	client.EXPECT().StatObject(gomock.Any(), t.conf.BucketName, "key", gomock.Any()).Return(minio.ObjectInfo{}, t.notFound).Times(2)
+
+	for i := 0; i < 2; i++ {
+		_, err := storeClient.StatObject(context.Background(), t.conf.BucketName, "key", minio.StatObjectOptions{})
+		t.Equal(t.notFound, err)
+	}
+}
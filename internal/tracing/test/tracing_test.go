@@ -0,0 +1,112 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/isd-sgcu/rpkm67-store/internal/object"
+	"github.com/isd-sgcu/rpkm67-store/internal/tracing"
+	mock_store "github.com/isd-sgcu/rpkm67-store/mocks/client/store"
+	mock_object "github.com/isd-sgcu/rpkm67-store/mocks/object"
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type TracingTest struct {
+	suite.Suite
+	controller *gomock.Controller
+	recorder   *tracetest.SpanRecorder
+}
+
+func TestTracing(t *testing.T) {
+	suite.Run(t, new(TracingTest))
+}
+
+func (t *TracingTest) SetupTest() {
+	t.controller = gomock.NewController(t.T())
+	t.recorder = tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(t.recorder)))
+}
+
+func (t *TracingTest) TearDownTest() {
+	otel.SetTracerProvider(noop.NewTracerProvider())
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, attr := range span.Attributes() {
+		attrs[attr.Key] = attr.Value
+	}
+
+	return attrs
+}
+
+func (t *TracingTest) TestStoreClientSpan() {
+	client := mock_store.NewMockClient(t.controller)
+	client.EXPECT().PutObject(gomock.Any(), "bucket", "object", gomock.Any(), int64(4), gomock.Any()).Return(minio.UploadInfo{}, nil)
+
+	_, err := tracing.NewStoreClient(client).PutObject(context.Background(), "bucket", "object", bytes.NewReader([]byte("data")), 4, minio.PutObjectOptions{})
+
+	t.Nil(err)
+	spans := t.recorder.Ended()
+	t.Require().Len(spans, 1)
+	t.Equal("store.Client/PutObject", spans[0].Name())
+	t.Equal("bucket", attributes(spans[0])["store.bucket"].AsString())
+	t.Equal("object", attributes(spans[0])["store.key"].AsString())
+	t.Equal(int64(4), attributes(spans[0])["store.size"].AsInt64())
+}
+
+func (t *TracingTest) TestStoreClientSpanError() {
+	client := mock_store.NewMockClient(t.controller)
+	client.EXPECT().RemoveObject(gomock.Any(), "bucket", "object", gomock.Any()).Return(fmt.Errorf("error"))
+
+	err := tracing.NewStoreClient(client).RemoveObject(context.Background(), "bucket", "object", minio.RemoveObjectOptions{})
+
+	t.NotNil(err)
+	spans := t.recorder.Ended()
+	t.Require().Len(spans, 1)
+	t.Equal(codes.Error, spans[0].Status().Code)
+}
+
+func (t *TracingTest) TestObjectRepositorySpanParentsStoreCalls() {
+	repo := mock_object.NewMockRepository(t.controller)
+	client := mock_store.NewMockClient(t.controller)
+	client.EXPECT().RemoveObject(gomock.Any(), "bucket", "object", gomock.Any()).Return(nil)
+	repo.EXPECT().Delete(gomock.Any(), "bucket", "object").DoAndReturn(func(ctx context.Context, bucketName string, objectKey string) error {
+		return tracing.NewStoreClient(client).RemoveObject(ctx, bucketName, objectKey, minio.RemoveObjectOptions{})
+	})
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "rpc")
+	err := tracing.NewObjectRepository(repo).Delete(ctx, "bucket", "object")
+	parent.End()
+
+	t.Nil(err)
+	spans := t.recorder.Ended()
+	t.Require().Len(spans, 3)
+	storeSpan, repoSpan, rpcSpan := spans[0], spans[1], spans[2]
+	t.Equal("object.Repository/Delete", repoSpan.Name())
+	t.Equal(rpcSpan.SpanContext().SpanID(), repoSpan.Parent().SpanID())
+	t.Equal(repoSpan.SpanContext().SpanID(), storeSpan.Parent().SpanID())
+}
+
+func (t *TracingTest) TestObjectRepositoryUploadAttributes() {
+	repo := mock_object.NewMockRepository(t.controller)
+	repo.EXPECT().Upload(gomock.Any(), []byte("data"), "bucket", "object", gomock.Any()).Return("url", "object", "", nil)
+
+	_, _, _, err := tracing.NewObjectRepository(repo).Upload(context.Background(), []byte("data"), "bucket", "object", object.UploadOptions{ContentType: "text/plain"})
+
+	t.Nil(err)
+	spans := t.recorder.Ended()
+	t.Require().Len(spans, 1)
+	t.Equal(int64(4), attributes(spans[0])["store.size"].AsInt64())
+	t.Equal("text/plain", attributes(spans[0])["store.content_type"].AsString())
+}
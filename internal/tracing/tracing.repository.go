@@ -0,0 +1,125 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/isd-sgcu/rpkm67-store/internal/object"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type objectRepository struct {
+	repo object.Repository
+}
+
+// NewObjectRepository wraps repo to record a span around every call, parenting the
+// spans of the storage backend calls it makes.
+func NewObjectRepository(repo object.Repository) object.Repository {
+	return &objectRepository{
+		repo: repo,
+	}
+}
+
+func (r *objectRepository) Upload(ctx context.Context, file []byte, bucketName string, objectKey string, opts object.UploadOptions) (url string, key string, versionID string, err error) {
+	ctx, span := startSpan(ctx, "object.Repository/Upload", append(bucketKey(bucketName, objectKey),
+		attribute.Int64("store.size", int64(len(file))),
+		attribute.String("store.content_type", opts.ContentType))...)
+	defer func() { endSpan(span, err) }()
+
+	return r.repo.Upload(ctx, file, bucketName, objectKey, opts)
+}
+
+func (r *objectRepository) Delete(ctx context.Context, bucketName string, objectKey string) (err error) {
+	ctx, span := startSpan(ctx, "object.Repository/Delete", bucketKey(bucketName, objectKey)...)
+	defer func() { endSpan(span, err) }()
+
+	return r.repo.Delete(ctx, bucketName, objectKey)
+}
+
+func (r *objectRepository) Purge(ctx context.Context, bucketName string, objectKey string, bypassGovernance bool) (err error) {
+	ctx, span := startSpan(ctx, "object.Repository/Purge", append(bucketKey(bucketName, objectKey), attribute.Bool("store.governance_bypass", bypassGovernance))...)
+	defer func() { endSpan(span, err) }()
+
+	return r.repo.Purge(ctx, bucketName, objectKey, bypassGovernance)
+}
+
+func (r *objectRepository) GetRetention(ctx context.Context, bucketName string, objectKey string, versionID string) (retention *object.Retention, err error) {
+	ctx, span := startSpan(ctx, "object.Repository/GetRetention", append(bucketKey(bucketName, objectKey), attribute.String("store.version_id", versionID))...)
+	defer func() { endSpan(span, err) }()
+
+	return r.repo.GetRetention(ctx, bucketName, objectKey, versionID)
+}
+
+func (r *objectRepository) GetVersion(ctx context.Context, bucketName string, objectKey string, versionID string) (url string, err error) {
+	ctx, span := startSpan(ctx, "object.Repository/GetVersion", append(bucketKey(bucketName, objectKey), attribute.String("store.version_id", versionID))...)
+	defer func() { endSpan(span, err) }()
+
+	return r.repo.GetVersion(ctx, bucketName, objectKey, versionID)
+}
+
+func (r *objectRepository) DeleteVersion(ctx context.Context, bucketName string, objectKey string, versionID string) (err error) {
+	ctx, span := startSpan(ctx, "object.Repository/DeleteVersion", append(bucketKey(bucketName, objectKey), attribute.String("store.version_id", versionID))...)
+	defer func() { endSpan(span, err) }()
+
+	return r.repo.DeleteVersion(ctx, bucketName, objectKey, versionID)
+}
+
+func (r *objectRepository) ListVersions(ctx context.Context, bucketName string, objectKey string) (versions []object.ObjectVersion, err error) {
+	ctx, span := startSpan(ctx, "object.Repository/ListVersions", bucketKey(bucketName, objectKey)...)
+	defer func() { endSpan(span, err) }()
+
+	return r.repo.ListVersions(ctx, bucketName, objectKey)
+}
+
+func (r *objectRepository) RestoreVersion(ctx context.Context, bucketName string, objectKey string, versionID string) (url string, newVersionID string, err error) {
+	ctx, span := startSpan(ctx, "object.Repository/RestoreVersion", append(bucketKey(bucketName, objectKey), attribute.String("store.version_id", versionID))...)
+	defer func() { endSpan(span, err) }()
+
+	return r.repo.RestoreVersion(ctx, bucketName, objectKey, versionID)
+}
+
+func (r *objectRepository) Trash(ctx context.Context, bucketName string, objectKey string) (err error) {
+	ctx, span := startSpan(ctx, "object.Repository/Trash", bucketKey(bucketName, objectKey)...)
+	defer func() { endSpan(span, err) }()
+
+	return r.repo.Trash(ctx, bucketName, objectKey)
+}
+
+func (r *objectRepository) Restore(ctx context.Context, bucketName string, objectKey string) (url string, err error) {
+	ctx, span := startSpan(ctx, "object.Repository/Restore", bucketKey(bucketName, objectKey)...)
+	defer func() { endSpan(span, err) }()
+
+	return r.repo.Restore(ctx, bucketName, objectKey)
+}
+
+func (r *objectRepository) Get(ctx context.Context, bucketName string, objectKey string) (url string, err error) {
+	ctx, span := startSpan(ctx, "object.Repository/Get", bucketKey(bucketName, objectKey)...)
+	defer func() { endSpan(span, err) }()
+
+	return r.repo.Get(ctx, bucketName, objectKey)
+}
+
+// GetURL only formats a URL, so it isn't worth a span.
+func (r *objectRepository) GetURL(bucketName string, objectKey string) string {
+	return r.repo.GetURL(bucketName, objectKey)
+}
+
+func (r *objectRepository) SetAlias(ctx context.Context, bucketName string, alias string, objectKey string) (err error) {
+	ctx, span := startSpan(ctx, "object.Repository/SetAlias", append(bucketKey(bucketName, objectKey), attribute.String("store.alias", alias))...)
+	defer func() { endSpan(span, err) }()
+
+	return r.repo.SetAlias(ctx, bucketName, alias, objectKey)
+}
+
+func (r *objectRepository) ResolveAlias(ctx context.Context, bucketName string, alias string) (key string, err error) {
+	ctx, span := startSpan(ctx, "object.Repository/ResolveAlias", attribute.String("store.bucket", bucketName), attribute.String("store.alias", alias))
+	defer func() { endSpan(span, err) }()
+
+	return r.repo.ResolveAlias(ctx, bucketName, alias)
+}
+
+func (r *objectRepository) List(ctx context.Context, bucketName string, prefix string) (objects []object.StoredObject, err error) {
+	ctx, span := startSpan(ctx, "object.Repository/List", attribute.String("store.bucket", bucketName), attribute.String("store.prefix", prefix))
+	defer func() { endSpan(span, err) }()
+
+	return r.repo.List(ctx, bucketName, prefix)
+}
@@ -0,0 +1,64 @@
+package tracing
+
+import (
+	"context"
+	"io"
+
+	"github.com/isd-sgcu/rpkm67-store/internal/client/store"
+	"github.com/minio/minio-go/v7"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type storeClient struct {
+	client store.Client
+}
+
+// NewStoreClient wraps client to record a span around every storage backend call.
+func NewStoreClient(client store.Client) store.Client {
+	return &storeClient{
+		client: client,
+	}
+}
+
+func (c *storeClient) PutObject(ctx context.Context, bucketName string, objectName string, reader io.Reader, objectSize int64, opts minio.PutObjectOptions) (info minio.UploadInfo, err error) {
+	ctx, span := startSpan(ctx, "store.Client/PutObject", append(bucketKey(bucketName, objectName), attribute.Int64("store.size", objectSize))...)
+	defer func() { endSpan(span, err) }()
+
+	return c.client.PutObject(ctx, bucketName, objectName, reader, objectSize, opts)
+}
+
+func (c *storeClient) RemoveObject(ctx context.Context, bucketName string, objectName string, opts minio.RemoveObjectOptions) (err error) {
+	ctx, span := startSpan(ctx, "store.Client/RemoveObject", append(bucketKey(bucketName, objectName), attribute.String("store.version_id", opts.VersionID))...)
+	defer func() { endSpan(span, err) }()
+
+	return c.client.RemoveObject(ctx, bucketName, objectName, opts)
+}
+
+func (c *storeClient) StatObject(ctx context.Context, bucketName string, objectName string, opts minio.StatObjectOptions) (info minio.ObjectInfo, err error) {
+	ctx, span := startSpan(ctx, "store.Client/StatObject", append(bucketKey(bucketName, objectName), attribute.String("store.version_id", opts.VersionID))...)
+	defer func() { endSpan(span, err) }()
+
+	return c.client.StatObject(ctx, bucketName, objectName, opts)
+}
+
+// ListObjects only spans the call itself; the listing is streamed to the caller afterwards.
+func (c *storeClient) ListObjects(ctx context.Context, bucketName string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+	ctx, span := startSpan(ctx, "store.Client/ListObjects", attribute.String("store.bucket", bucketName), attribute.String("store.prefix", opts.Prefix))
+	defer span.End()
+
+	return c.client.ListObjects(ctx, bucketName, opts)
+}
+
+func (c *storeClient) CopyObject(ctx context.Context, dst minio.CopyDestOptions, src minio.CopySrcOptions) (info minio.UploadInfo, err error) {
+	ctx, span := startSpan(ctx, "store.Client/CopyObject", append(bucketKey(dst.Bucket, dst.Object), attribute.String("store.source_key", src.Object))...)
+	defer func() { endSpan(span, err) }()
+
+	return c.client.CopyObject(ctx, dst, src)
+}
+
+func (c *storeClient) BucketExists(ctx context.Context, bucketName string) (exists bool, err error) {
+	ctx, span := startSpan(ctx, "store.Client/BucketExists", attribute.String("store.bucket", bucketName))
+	defer func() { endSpan(span, err) }()
+
+	return c.client.BucketExists(ctx, bucketName)
+}
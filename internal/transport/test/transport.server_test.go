@@ -0,0 +1,131 @@
+package test
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"testing"
+
+	proto "github.com/isd-sgcu/rpkm67-go-proto/rpkm67/store/object/v1"
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/isd-sgcu/rpkm67-store/internal/transport"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// compressionRecorder records the compression of the response headers a client receives.
+type compressionRecorder struct {
+	compression string
+}
+
+func (r *compressionRecorder) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) HandleRPC(_ context.Context, s stats.RPCStats) {
+	if header, ok := s.(*stats.InHeader); ok && header.Client {
+		r.compression = header.Compression
+	}
+}
+
+func (r *compressionRecorder) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) HandleConn(context.Context, stats.ConnStats) {}
+
+// uploadServer answers every upload with the size of its data.
+type uploadServer struct {
+	proto.UnimplementedObjectServiceServer
+}
+
+func (s *uploadServer) Upload(ctx context.Context, req *proto.UploadObjectRequest) (*proto.UploadObjectResponse, error) {
+	return &proto.UploadObjectResponse{Object: &proto.Object{Key: strconv.Itoa(len(req.Data))}}, nil
+}
+
+type ServerOptionsTest struct {
+	suite.Suite
+	conf     *config.GRPC
+	recorder *compressionRecorder
+}
+
+func TestServerOptions(t *testing.T) {
+	suite.Run(t, new(ServerOptionsTest))
+}
+
+func (t *ServerOptionsTest) SetupTest() {
+	t.conf = &config.GRPC{
+		MaxConcurrentStreams: 10,
+		Compression:          "gzip",
+	}
+	t.recorder = &compressionRecorder{}
+}
+
+// dial serves the options for maxFileSize on an in-memory listener and returns a client for it.
+func (t *ServerOptionsTest) dial(maxFileSize int64) proto.ObjectServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(transport.NewServerOptions(t.conf, maxFileSize)...)
+	proto.RegisterObjectServiceServer(server, &uploadServer{})
+	go func() { _ = server.Serve(listener) }()
+	t.T().Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(math.MaxInt32)),
+		grpc.WithStatsHandler(t.recorder),
+	)
+	t.Require().Nil(err)
+	t.T().Cleanup(func() { conn.Close() })
+
+	return proto.NewObjectServiceClient(conn)
+}
+
+func (t *ServerOptionsTest) TestMaxMessageSize() {
+	t.Equal(10*1024*1024+1024*1024, transport.MaxMessageSize(10*1024*1024))
+	t.Equal(math.MaxInt32, transport.MaxMessageSize(0))
+	t.Equal(math.MaxInt32, transport.MaxMessageSize(math.MaxInt64))
+}
+
+func (t *ServerOptionsTest) TestReceivesUploadsAboveDefaultLimit() {
+	client := t.dial(8 * 1024 * 1024)
+
+	resp, err := client.Upload(context.Background(), &proto.UploadObjectRequest{Data: make([]byte, 6*1024*1024)})
+
+	t.Require().Nil(err)
+	t.Equal(strconv.Itoa(6*1024*1024), resp.Object.Key)
+}
+
+func (t *ServerOptionsTest) TestRejectsMessagesAboveUploadLimit() {
+	client := t.dial(1024 * 1024)
+
+	_, err := client.Upload(context.Background(), &proto.UploadObjectRequest{Data: make([]byte, 3*1024*1024)})
+
+	t.Equal(codes.ResourceExhausted, status.Code(err))
+}
+
+func (t *ServerOptionsTest) TestCompressesResponses() {
+	client := t.dial(1024 * 1024)
+
+	_, err := client.Upload(context.Background(), &proto.UploadObjectRequest{})
+
+	t.Require().Nil(err)
+	t.Equal(gzip.Name, t.recorder.compression)
+}
+
+func (t *ServerOptionsTest) TestCompressionDisabled() {
+	t.conf.Compression = ""
+	client := t.dial(1024 * 1024)
+
+	_, err := client.Upload(context.Background(), &proto.UploadObjectRequest{})
+
+	t.Require().Nil(err)
+	t.Empty(t.recorder.compression)
+}
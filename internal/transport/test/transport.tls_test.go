@@ -0,0 +1,134 @@
+package test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"github.com/isd-sgcu/rpkm67-store/internal/transport"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type TLSTest struct {
+	suite.Suite
+	dir    string
+	conf   *config.TLS
+	logger *zap.Logger
+}
+
+func TestTLS(t *testing.T) {
+	suite.Run(t, new(TLSTest))
+}
+
+func (t *TLSTest) SetupTest() {
+	t.logger = zap.NewNop()
+	t.dir = t.T().TempDir()
+	t.conf = &config.TLS{
+		CertFile: filepath.Join(t.dir, "server.crt"),
+		KeyFile:  filepath.Join(t.dir, "server.key"),
+	}
+}
+
+// writeCertificate writes a self-signed certificate for commonName to the configured paths.
+func (t *TLSTest) writeCertificate(commonName string, modTime time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	t.Require().Nil(err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	t.Require().Nil(err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	t.Require().Nil(err)
+
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	t.Require().Nil(os.WriteFile(t.conf.CertFile, certPem, 0o600))
+	t.Require().Nil(os.WriteFile(t.conf.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	t.Require().Nil(os.Chtimes(t.conf.CertFile, modTime, modTime))
+	t.Require().Nil(os.Chtimes(t.conf.KeyFile, modTime, modTime))
+}
+
+func (t *TLSTest) servedCommonName(tlsConfig *tls.Config) string {
+	conf, err := tlsConfig.GetConfigForClient(&tls.ClientHelloInfo{})
+	t.Require().Nil(err)
+
+	leaf, err := x509.ParseCertificate(conf.Certificates[0].Certificate[0])
+	t.Require().Nil(err)
+
+	return leaf.Subject.CommonName
+}
+
+func (t *TLSTest) TestNewTLSConfigMissingFiles() {
+	tlsConfig, err := transport.NewTLSConfig(t.conf, t.logger)
+
+	t.Nil(tlsConfig)
+	t.NotNil(err)
+}
+
+func (t *TLSTest) TestNewTLSConfigWithoutClientCA() {
+	t.writeCertificate("store", time.Now())
+
+	tlsConfig, err := transport.NewTLSConfig(t.conf, t.logger)
+	t.Require().Nil(err)
+
+	conf, err := tlsConfig.GetConfigForClient(&tls.ClientHelloInfo{})
+
+	t.Nil(err)
+	t.Equal(tls.NoClientCert, conf.ClientAuth)
+	t.Nil(conf.ClientCAs)
+}
+
+func (t *TLSTest) TestNewTLSConfigWithClientCA() {
+	t.writeCertificate("store", time.Now())
+	t.conf.ClientCAFile = t.conf.CertFile
+
+	tlsConfig, err := transport.NewTLSConfig(t.conf, t.logger)
+	t.Require().Nil(err)
+
+	conf, err := tlsConfig.GetConfigForClient(&tls.ClientHelloInfo{})
+
+	t.Nil(err)
+	t.Equal(tls.RequireAndVerifyClientCert, conf.ClientAuth)
+	t.NotNil(conf.ClientCAs)
+}
+
+func (t *TLSTest) TestReloadRotatedCertificate() {
+	t.writeCertificate("old", time.Now().Add(-time.Minute))
+
+	tlsConfig, err := transport.NewTLSConfig(t.conf, t.logger)
+	t.Require().Nil(err)
+	t.Equal("old", t.servedCommonName(tlsConfig))
+
+	t.writeCertificate("new", time.Now())
+
+	t.Equal("new", t.servedCommonName(tlsConfig))
+}
+
+func (t *TLSTest) TestReloadInvalidCertificateKeepsPrevious() {
+	t.writeCertificate("old", time.Now().Add(-time.Minute))
+
+	tlsConfig, err := transport.NewTLSConfig(t.conf, t.logger)
+	t.Require().Nil(err)
+
+	t.Require().Nil(os.WriteFile(t.conf.CertFile, []byte("garbage"), 0o600))
+
+	t.Equal("old", t.servedCommonName(tlsConfig))
+}
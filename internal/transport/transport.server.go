@@ -0,0 +1,66 @@
+package transport
+
+import (
+	"context"
+	"math"
+	"slices"
+
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"google.golang.org/grpc"
+	// registers the gzip compressor, the one GRPC_COMPRESSION allows
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
+)
+
+// messageOverhead is the room left in a message for the fields of an upload besides its data.
+const messageOverhead = 1024 * 1024
+
+// MaxMessageSize is the largest message the server sends or receives: an upload of
+// maxFileSize bytes plus its other fields. A maxFileSize of 0 means unlimited.
+func MaxMessageSize(maxFileSize int64) int {
+	if maxFileSize <= 0 || maxFileSize > math.MaxInt32-messageOverhead {
+		return math.MaxInt32
+	}
+
+	return int(maxFileSize) + messageOverhead
+}
+
+// NewServerOptions returns the gRPC server options for conf, sizing messages to fit uploads
+// of maxFileSize bytes so that gRPC's default 4 MB limit doesn't cap them first.
+func NewServerOptions(conf *config.GRPC, maxFileSize int64) []grpc.ServerOption {
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(MaxMessageSize(maxFileSize)),
+		grpc.MaxSendMsgSize(MaxMessageSize(maxFileSize)),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:                  conf.KeepaliveTime,
+			Timeout:               conf.KeepaliveTimeout,
+			MaxConnectionIdle:     conf.MaxConnectionIdle,
+			MaxConnectionAge:      conf.MaxConnectionAge,
+			MaxConnectionAgeGrace: conf.MaxConnectionAgeGrace,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             conf.KeepaliveMinTime,
+			PermitWithoutStream: conf.KeepalivePermitWithoutStream,
+		}),
+	}
+	if conf.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(uint32(conf.MaxConcurrentStreams)))
+	}
+	if conf.Compression != "" {
+		opts = append(opts, grpc.ChainUnaryInterceptor(UnaryCompressionInterceptor(conf.Compression)))
+	}
+
+	return opts
+}
+
+// UnaryCompressionInterceptor compresses responses with the named compressor when the client
+// accepts it. Requests are decompressed by whichever registered compressor they name.
+func UnaryCompressionInterceptor(name string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if accepted, err := grpc.ClientSupportedCompressors(ctx); err == nil && slices.Contains(accepted, name) {
+			_ = grpc.SetSendCompressor(ctx, name)
+		}
+
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,143 @@
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/isd-sgcu/rpkm67-store/config"
+	"go.uber.org/zap"
+)
+
+// NewTLSConfig returns the server TLS config for conf. Client certificates are required and
+// verified when a client CA bundle is set. The certificate, key and CA bundle are reloaded
+// when their files change, so rotated certificates are picked up without a restart.
+func NewTLSConfig(conf *config.TLS, log *zap.Logger) (*tls.Config, error) {
+	r := &reloader{
+		conf: conf,
+		log:  log,
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		GetConfigForClient: r.getConfigForClient,
+	}, nil
+}
+
+type reloader struct {
+	conf *config.TLS
+	log  *zap.Logger
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	modTimes []time.Time
+}
+
+func (r *reloader) files() []string {
+	files := []string{r.conf.CertFile, r.conf.KeyFile}
+	if r.conf.ClientCAFile != "" {
+		files = append(files, r.conf.ClientCAFile)
+	}
+
+	return files
+}
+
+func (r *reloader) statFiles() ([]time.Time, error) {
+	files := r.files()
+	modTimes := make([]time.Time, len(files))
+	for i, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		modTimes[i] = info.ModTime()
+	}
+
+	return modTimes, nil
+}
+
+func (r *reloader) load() error {
+	modTimes, err := r.statFiles()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.conf.CertFile, r.conf.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	var clientCA *x509.CertPool
+	if r.conf.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.conf.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA bundle: %w", err)
+		}
+		clientCA = x509.NewCertPool()
+		if !clientCA.AppendCertsFromPEM(pem) {
+			return errors.New("client CA bundle contains no certificates")
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCA = clientCA
+	r.modTimes = modTimes
+
+	return nil
+}
+
+func (r *reloader) changed() ([]time.Time, bool) {
+	modTimes, err := r.statFiles()
+	if err != nil {
+		// a rotation in progress may briefly remove the files; keep serving the old ones
+		return nil, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for i := range modTimes {
+		if !modTimes[i].Equal(r.modTimes[i]) {
+			return modTimes, true
+		}
+	}
+
+	return nil, false
+}
+
+func (r *reloader) getConfigForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	if modTimes, changed := r.changed(); changed {
+		if err := r.load(); err != nil {
+			r.log.Named("TLS").Error("Failed to reload certificates, keeping the previous ones", zap.Error(err))
+			// don't retry on every handshake until the files change again
+			r.mu.Lock()
+			r.modTimes = modTimes
+			r.mu.Unlock()
+		} else {
+			r.log.Named("TLS").Info("Reloaded certificates")
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	conf := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*r.cert},
+	}
+	if r.clientCA != nil {
+		conf.ClientCAs = r.clientCA
+		conf.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return conf, nil
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// GetMetadataValue returns the first value of key in the incoming gRPC metadata, or "" if absent.
+func GetMetadataValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// SetHeaderValue sends key as a response header of the current gRPC call. It is a no-op
+// outside of a gRPC call, e.g. when a handler is invoked directly from tests.
+func SetHeaderValue(ctx context.Context, key string, value string) {
+	if value == "" {
+		return
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(key, value))
+}
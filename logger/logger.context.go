@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// scope holds the fields describing the current request. It is shared by everything
+// handling the request, so fields added deep in the call chain show up in the access line.
+// Handlers may log from several goroutines, hence the mutex.
+type scope struct {
+	mu     sync.Mutex
+	fields []zap.Field
+}
+
+type scopeKey struct{}
+
+// NewContext returns a copy of ctx starting a request scope with fields.
+func NewContext(ctx context.Context, fields ...zap.Field) context.Context {
+	return context.WithValue(ctx, scopeKey{}, &scope{fields: fields})
+}
+
+// AddFields attaches fields to the request scope of ctx. It is a no-op outside of a request.
+func AddFields(ctx context.Context, fields ...zap.Field) {
+	if s, ok := ctx.Value(scopeKey{}).(*scope); ok {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.fields = append(s.fields, fields...)
+	}
+}
+
+// FromContext returns log annotated with the fields of the request scope of ctx, if any.
+func FromContext(ctx context.Context, log *zap.Logger) *zap.Logger {
+	if s, ok := ctx.Value(scopeKey{}).(*scope); ok {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return log.With(s.fields...)
+	}
+
+	return log
+}
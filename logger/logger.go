@@ -3,16 +3,36 @@ package logger
 import (
 	"github.com/isd-sgcu/rpkm67-store/config"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
+// level is shared by every logger from New, so SetLevel changes them all at runtime.
+var level = zap.NewAtomicLevel()
+
 func New(conf *config.Config) *zap.Logger {
-	var logger *zap.Logger
+	var zapConf zap.Config
 
 	if conf.App.IsDevelopment() {
-		logger = zap.Must(zap.NewDevelopment())
+		zapConf = zap.NewDevelopmentConfig()
 	} else {
-		logger = zap.Must(zap.NewProduction())
+		zapConf = zap.NewProductionConfig()
+	}
+	zapConf.Level = level
+	_ = SetLevel(&conf.App)
+
+	return zap.Must(zapConf.Build())
+}
+
+// SetLevel applies the log level of conf, falling back to debug in development and info otherwise.
+func SetLevel(conf *config.App) error {
+	if conf.LogLevel == "" {
+		if conf.IsDevelopment() {
+			level.SetLevel(zapcore.DebugLevel)
+		} else {
+			level.SetLevel(zapcore.InfoLevel)
+		}
+		return nil
 	}
 
-	return logger
+	return level.UnmarshalText([]byte(conf.LogLevel))
 }
@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isd-sgcu/rpkm67-store/constant"
+	"github.com/isd-sgcu/rpkm67-store/internal/utils"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// UnaryServerInterceptor starts a request scope carrying the request ID, taken from the
+// x-request-id metadata or generated, and writes one access line per call.
+func UnaryServerInterceptor(log *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = startRequest(ctx, info.FullMethod)
+		start := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		FromContext(ctx, log).Check(levelFor(status.Code(err)), "gRPC call").Write(
+			zap.String("code", status.Code(err).String()),
+			zap.Duration("duration", time.Since(start)),
+			zap.Int("request_size", messageSize(req)),
+			zap.Int("response_size", messageSize(resp)),
+			zap.Error(err),
+		)
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of UnaryServerInterceptor.
+func StreamServerInterceptor(log *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := startRequest(ss.Context(), info.FullMethod)
+		start := time.Now()
+
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+
+		FromContext(ctx, log).Check(levelFor(status.Code(err)), "gRPC stream").Write(
+			zap.String("code", status.Code(err).String()),
+			zap.Duration("duration", time.Since(start)),
+			zap.Error(err),
+		)
+
+		return err
+	}
+}
+
+func startRequest(ctx context.Context, fullMethod string) context.Context {
+	requestID := utils.GetMetadataValue(ctx, constant.RequestIdMetadataKey)
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+	utils.SetHeaderValue(ctx, constant.RequestIdMetadataKey, requestID)
+
+	return NewContext(ctx, zap.String("request_id", requestID), zap.String("method", fullMethod))
+}
+
+// levelFor logs outcomes caused by the caller, such as a missing object, below Error
+// so that Error stays reserved for failures of the service itself.
+func levelFor(code codes.Code) zapcore.Level {
+	switch code {
+	case codes.OK, codes.NotFound, codes.Canceled, codes.AlreadyExists:
+		return zapcore.InfoLevel
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange, codes.PermissionDenied,
+		codes.Unauthenticated, codes.ResourceExhausted, codes.Aborted:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+func messageSize(msg interface{}) int {
+	if m, ok := msg.(proto.Message); ok {
+		return proto.Size(m)
+	}
+
+	return 0
+}
+
+// serverStream overrides the context of a grpc.ServerStream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,122 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	proto "github.com/isd-sgcu/rpkm67-go-proto/rpkm67/store/object/v1"
+	"github.com/isd-sgcu/rpkm67-store/logger"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type LoggerInterceptorTest struct {
+	suite.Suite
+	log  *zap.Logger
+	logs *observer.ObservedLogs
+	info *grpc.UnaryServerInfo
+}
+
+func TestLoggerInterceptor(t *testing.T) {
+	suite.Run(t, new(LoggerInterceptorTest))
+}
+
+func (t *LoggerInterceptorTest) SetupTest() {
+	core, logs := observer.New(zapcore.DebugLevel)
+	t.log = zap.New(core)
+	t.logs = logs
+	t.info = &grpc.UnaryServerInfo{FullMethod: "/rpkm67.file.image.v1.ObjectService/FindByKey"}
+}
+
+func (t *LoggerInterceptorTest) TestPropagatesRequestId() {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "request-1"))
+	handlerLog := zap.New(t.log.Core())
+
+	_, err := logger.UnaryServerInterceptor(t.log)(ctx, &proto.FindByKeyObjectRequest{Key: "key"}, t.info, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		logger.FromContext(ctx, handlerLog).Info("handling")
+		return &proto.FindByKeyObjectResponse{}, nil
+	})
+
+	t.Nil(err)
+	entries := t.logs.All()
+	t.Require().Len(entries, 2)
+	for _, entry := range entries {
+		t.Equal("request-1", entry.ContextMap()["request_id"])
+		t.Equal(t.info.FullMethod, entry.ContextMap()["method"])
+	}
+	t.Equal(zapcore.InfoLevel, entries[1].Level)
+	t.Equal("OK", entries[1].ContextMap()["code"])
+	t.Equal(int64(len("key")+2), entries[1].ContextMap()["request_size"])
+}
+
+func (t *LoggerInterceptorTest) TestGeneratesRequestId() {
+	_, err := logger.UnaryServerInterceptor(t.log)(context.Background(), nil, t.info, func(context.Context, interface{}) (interface{}, error) {
+		return nil, nil
+	})
+
+	t.Nil(err)
+	t.Require().Len(t.logs.All(), 1)
+	t.NotEmpty(t.logs.All()[0].ContextMap()["request_id"])
+}
+
+func (t *LoggerInterceptorTest) TestAddFieldsReachesAccessLine() {
+	_, _ = logger.UnaryServerInterceptor(t.log)(context.Background(), nil, t.info, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		logger.AddFields(ctx, zap.String("caller", "service:gateway"))
+		return nil, nil
+	})
+
+	t.Require().Len(t.logs.All(), 1)
+	t.Equal("service:gateway", t.logs.All()[0].ContextMap()["caller"])
+}
+
+func (t *LoggerInterceptorTest) TestAddFieldsConcurrently() {
+	_, _ = logger.UnaryServerInterceptor(t.log)(context.Background(), nil, t.info, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				logger.AddFields(ctx, zap.Int(fmt.Sprintf("field_%d", i), i))
+				logger.FromContext(ctx, t.log).Debug("working")
+			}(i)
+		}
+		wg.Wait()
+		return nil, nil
+	})
+
+	access := t.logs.All()[len(t.logs.All())-1].ContextMap()
+	for i := 0; i < 8; i++ {
+		t.Contains(access, fmt.Sprintf("field_%d", i))
+	}
+}
+
+func (t *LoggerInterceptorTest) TestLevels() {
+	levels := map[codes.Code]zapcore.Level{
+		codes.NotFound:          zapcore.InfoLevel,
+		codes.InvalidArgument:   zapcore.WarnLevel,
+		codes.PermissionDenied:  zapcore.WarnLevel,
+		codes.ResourceExhausted: zapcore.WarnLevel,
+		codes.Internal:          zapcore.ErrorLevel,
+		codes.Unavailable:       zapcore.ErrorLevel,
+	}
+
+	for code, level := range levels {
+		t.logs.TakeAll()
+
+		_, err := logger.UnaryServerInterceptor(t.log)(context.Background(), nil, t.info, func(context.Context, interface{}) (interface{}, error) {
+			return nil, status.Error(code, "error")
+		})
+
+		t.Equal(code, status.Code(err))
+		t.Require().Len(t.logs.All(), 1)
+		t.Equal(level, t.logs.All()[0].Level, code.String())
+	}
+}
@@ -0,0 +1,93 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/auth/auth.authenticator.go
+
+// Package mock_auth is a generated GoMock package.
+package mock_auth
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	config "github.com/isd-sgcu/rpkm67-store/config"
+	auth "github.com/isd-sgcu/rpkm67-store/internal/auth"
+	grpc "google.golang.org/grpc"
+)
+
+// MockAuthenticator is a mock of Authenticator interface.
+type MockAuthenticator struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthenticatorMockRecorder
+}
+
+// MockAuthenticatorMockRecorder is the mock recorder for MockAuthenticator.
+type MockAuthenticatorMockRecorder struct {
+	mock *MockAuthenticator
+}
+
+// NewMockAuthenticator creates a new mock instance.
+func NewMockAuthenticator(ctrl *gomock.Controller) *MockAuthenticator {
+	mock := &MockAuthenticator{ctrl: ctrl}
+	mock.recorder = &MockAuthenticatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthenticator) EXPECT() *MockAuthenticatorMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockAuthenticator) Authenticate(ctx context.Context) (*auth.Identity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx)
+	ret0, _ := ret[0].(*auth.Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockAuthenticatorMockRecorder) Authenticate(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAuthenticator)(nil).Authenticate), ctx)
+}
+
+// Reload mocks base method.
+func (m *MockAuthenticator) Reload(conf *config.Auth) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Reload", conf)
+}
+
+// Reload indicates an expected call of Reload.
+func (mr *MockAuthenticatorMockRecorder) Reload(conf interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reload", reflect.TypeOf((*MockAuthenticator)(nil).Reload), conf)
+}
+
+// StreamServerInterceptor mocks base method.
+func (m *MockAuthenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamServerInterceptor")
+	ret0, _ := ret[0].(grpc.StreamServerInterceptor)
+	return ret0
+}
+
+// StreamServerInterceptor indicates an expected call of StreamServerInterceptor.
+func (mr *MockAuthenticatorMockRecorder) StreamServerInterceptor() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamServerInterceptor", reflect.TypeOf((*MockAuthenticator)(nil).StreamServerInterceptor))
+}
+
+// UnaryServerInterceptor mocks base method.
+func (m *MockAuthenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnaryServerInterceptor")
+	ret0, _ := ret[0].(grpc.UnaryServerInterceptor)
+	return ret0
+}
+
+// UnaryServerInterceptor indicates an expected call of UnaryServerInterceptor.
+func (mr *MockAuthenticatorMockRecorder) UnaryServerInterceptor() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnaryServerInterceptor", reflect.TypeOf((*MockAuthenticator)(nil).UnaryServerInterceptor))
+}
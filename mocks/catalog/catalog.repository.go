@@ -0,0 +1,321 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/catalog/catalog.repository.go
+
+// Package mock_catalog is a generated GoMock package.
+package mock_catalog
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	catalog "github.com/isd-sgcu/rpkm67-store/internal/catalog"
+	model "github.com/isd-sgcu/rpkm67-store/internal/model"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Backfill mocks base method.
+func (m *MockRepository) Backfill(object *model.Object) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Backfill", object)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Backfill indicates an expected call of Backfill.
+func (mr *MockRepositoryMockRecorder) Backfill(object interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Backfill", reflect.TypeOf((*MockRepository)(nil).Backfill), object)
+}
+
+// Commit mocks base method.
+func (m *MockRepository) Commit(bucketName, objectKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Commit", bucketName, objectKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Commit indicates an expected call of Commit.
+func (mr *MockRepositoryMockRecorder) Commit(bucketName, objectKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockRepository)(nil).Commit), bucketName, objectKey)
+}
+
+// CompleteBackfill mocks base method.
+func (m *MockRepository) CompleteBackfill(bucketName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteBackfill", bucketName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteBackfill indicates an expected call of CompleteBackfill.
+func (mr *MockRepositoryMockRecorder) CompleteBackfill(bucketName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteBackfill", reflect.TypeOf((*MockRepository)(nil).CompleteBackfill), bucketName)
+}
+
+// CompleteIdempotencyKey mocks base method.
+func (m *MockRepository) CompleteIdempotencyKey(key *model.IdempotencyKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteIdempotencyKey", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteIdempotencyKey indicates an expected call of CompleteIdempotencyKey.
+func (mr *MockRepositoryMockRecorder) CompleteIdempotencyKey(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotencyKey", reflect.TypeOf((*MockRepository)(nil).CompleteIdempotencyKey), key)
+}
+
+// Create mocks base method.
+func (m *MockRepository) Create(object *model.Object) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", object)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRepositoryMockRecorder) Create(object interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), object)
+}
+
+// Delete mocks base method.
+func (m *MockRepository) Delete(bucketName, objectKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", bucketName, objectKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockRepositoryMockRecorder) Delete(bucketName, objectKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), bucketName, objectKey)
+}
+
+// FindByKey mocks base method.
+func (m *MockRepository) FindByKey(bucketName, objectKey string, object *model.Object) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByKey", bucketName, objectKey, object)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FindByKey indicates an expected call of FindByKey.
+func (mr *MockRepositoryMockRecorder) FindByKey(bucketName, objectKey, object interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByKey", reflect.TypeOf((*MockRepository)(nil).FindByKey), bucketName, objectKey, object)
+}
+
+// FindIdempotencyKey mocks base method.
+func (m *MockRepository) FindIdempotencyKey(owner, key string, record *model.IdempotencyKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindIdempotencyKey", owner, key, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FindIdempotencyKey indicates an expected call of FindIdempotencyKey.
+func (mr *MockRepositoryMockRecorder) FindIdempotencyKey(owner, key, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindIdempotencyKey", reflect.TypeOf((*MockRepository)(nil).FindIdempotencyKey), owner, key, record)
+}
+
+// FindUsage mocks base method.
+func (m *MockRepository) FindUsage(owner string, usages *[]*model.Usage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUsage", owner, usages)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FindUsage indicates an expected call of FindUsage.
+func (mr *MockRepositoryMockRecorder) FindUsage(owner, usages interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUsage", reflect.TypeOf((*MockRepository)(nil).FindUsage), owner, usages)
+}
+
+// IncrementUsage mocks base method.
+func (m *MockRepository) IncrementUsage(owner, category string, bytes, objects, maxBytes, maxObjects int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementUsage", owner, category, bytes, objects, maxBytes, maxObjects)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementUsage indicates an expected call of IncrementUsage.
+func (mr *MockRepositoryMockRecorder) IncrementUsage(owner, category, bytes, objects, maxBytes, maxObjects interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementUsage", reflect.TypeOf((*MockRepository)(nil).IncrementUsage), owner, category, bytes, objects, maxBytes, maxObjects)
+}
+
+// IsBackfilled mocks base method.
+func (m *MockRepository) IsBackfilled(bucketName string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBackfilled", bucketName)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsBackfilled indicates an expected call of IsBackfilled.
+func (mr *MockRepositoryMockRecorder) IsBackfilled(bucketName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBackfilled", reflect.TypeOf((*MockRepository)(nil).IsBackfilled), bucketName)
+}
+
+// List mocks base method.
+func (m *MockRepository) List(bucketName, prefix string, limit int, objects *[]*model.Object) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", bucketName, prefix, limit, objects)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// List indicates an expected call of List.
+func (mr *MockRepositoryMockRecorder) List(bucketName, prefix, limit, objects interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), bucketName, prefix, limit, objects)
+}
+
+// Purge mocks base method.
+func (m *MockRepository) Purge(bucketName, objectKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", bucketName, objectKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockRepositoryMockRecorder) Purge(bucketName, objectKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockRepository)(nil).Purge), bucketName, objectKey)
+}
+
+// ReleaseIdempotencyKey mocks base method.
+func (m *MockRepository) ReleaseIdempotencyKey(owner, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseIdempotencyKey", owner, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseIdempotencyKey indicates an expected call of ReleaseIdempotencyKey.
+func (mr *MockRepositoryMockRecorder) ReleaseIdempotencyKey(owner, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseIdempotencyKey", reflect.TypeOf((*MockRepository)(nil).ReleaseIdempotencyKey), owner, key)
+}
+
+// ReserveIdempotencyKey mocks base method.
+func (m *MockRepository) ReserveIdempotencyKey(key *model.IdempotencyKey) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveIdempotencyKey", key)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveIdempotencyKey indicates an expected call of ReserveIdempotencyKey.
+func (mr *MockRepositoryMockRecorder) ReserveIdempotencyKey(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveIdempotencyKey", reflect.TypeOf((*MockRepository)(nil).ReserveIdempotencyKey), key)
+}
+
+// Resize mocks base method.
+func (m *MockRepository) Resize(bucketName, objectKey string, size int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resize", bucketName, objectKey, size)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Resize indicates an expected call of Resize.
+func (mr *MockRepositoryMockRecorder) Resize(bucketName, objectKey, size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resize", reflect.TypeOf((*MockRepository)(nil).Resize), bucketName, objectKey, size)
+}
+
+// Restore mocks base method.
+func (m *MockRepository) Restore(bucketName, objectKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", bucketName, objectKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockRepositoryMockRecorder) Restore(bucketName, objectKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockRepository)(nil).Restore), bucketName, objectKey)
+}
+
+// SumByCategory mocks base method.
+func (m *MockRepository) SumByCategory(bucketName string, usages *[]*model.Usage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumByCategory", bucketName, usages)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SumByCategory indicates an expected call of SumByCategory.
+func (mr *MockRepositoryMockRecorder) SumByCategory(bucketName, usages interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumByCategory", reflect.TypeOf((*MockRepository)(nil).SumByCategory), bucketName, usages)
+}
+
+// TryLock mocks base method.
+func (m *MockRepository) TryLock(name string, fn func() error) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TryLock", name, fn)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TryLock indicates an expected call of TryLock.
+func (mr *MockRepositoryMockRecorder) TryLock(name, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TryLock", reflect.TypeOf((*MockRepository)(nil).TryLock), name, fn)
+}
+
+// WithTransaction mocks base method.
+func (m *MockRepository) WithTransaction(txFunc func(catalog.Repository) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTransaction", txFunc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithTransaction indicates an expected call of WithTransaction.
+func (mr *MockRepositoryMockRecorder) WithTransaction(txFunc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTransaction", reflect.TypeOf((*MockRepository)(nil).WithTransaction), txFunc)
+}
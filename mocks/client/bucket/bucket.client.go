@@ -0,0 +1,171 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/client/bucket/bucket.client.go
+
+// Package mock_bucket is a generated GoMock package.
+package mock_bucket
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	minio "github.com/minio/minio-go/v7"
+	lifecycle "github.com/minio/minio-go/v7/pkg/lifecycle"
+)
+
+// MockClient is a mock of Client interface.
+type MockClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientMockRecorder
+}
+
+// MockClientMockRecorder is the mock recorder for MockClient.
+type MockClientMockRecorder struct {
+	mock *MockClient
+}
+
+// NewMockClient creates a new mock instance.
+func NewMockClient(ctrl *gomock.Controller) *MockClient {
+	mock := &MockClient{ctrl: ctrl}
+	mock.recorder = &MockClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClient) EXPECT() *MockClientMockRecorder {
+	return m.recorder
+}
+
+// BucketExists mocks base method.
+func (m *MockClient) BucketExists(ctx context.Context, bucketName string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BucketExists", ctx, bucketName)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BucketExists indicates an expected call of BucketExists.
+func (mr *MockClientMockRecorder) BucketExists(ctx, bucketName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BucketExists", reflect.TypeOf((*MockClient)(nil).BucketExists), ctx, bucketName)
+}
+
+// GetBucketLifecycle mocks base method.
+func (m *MockClient) GetBucketLifecycle(ctx context.Context, bucketName string) (*lifecycle.Configuration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBucketLifecycle", ctx, bucketName)
+	ret0, _ := ret[0].(*lifecycle.Configuration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBucketLifecycle indicates an expected call of GetBucketLifecycle.
+func (mr *MockClientMockRecorder) GetBucketLifecycle(ctx, bucketName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBucketLifecycle", reflect.TypeOf((*MockClient)(nil).GetBucketLifecycle), ctx, bucketName)
+}
+
+// GetBucketPolicy mocks base method.
+func (m *MockClient) GetBucketPolicy(ctx context.Context, bucketName string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBucketPolicy", ctx, bucketName)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBucketPolicy indicates an expected call of GetBucketPolicy.
+func (mr *MockClientMockRecorder) GetBucketPolicy(ctx, bucketName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBucketPolicy", reflect.TypeOf((*MockClient)(nil).GetBucketPolicy), ctx, bucketName)
+}
+
+// GetBucketVersioning mocks base method.
+func (m *MockClient) GetBucketVersioning(ctx context.Context, bucketName string) (minio.BucketVersioningConfiguration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBucketVersioning", ctx, bucketName)
+	ret0, _ := ret[0].(minio.BucketVersioningConfiguration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBucketVersioning indicates an expected call of GetBucketVersioning.
+func (mr *MockClientMockRecorder) GetBucketVersioning(ctx, bucketName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBucketVersioning", reflect.TypeOf((*MockClient)(nil).GetBucketVersioning), ctx, bucketName)
+}
+
+// GetObjectLockConfig mocks base method.
+func (m *MockClient) GetObjectLockConfig(ctx context.Context, bucketName string) (string, *minio.RetentionMode, *uint, *minio.ValidityUnit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetObjectLockConfig", ctx, bucketName)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*minio.RetentionMode)
+	ret2, _ := ret[2].(*uint)
+	ret3, _ := ret[3].(*minio.ValidityUnit)
+	ret4, _ := ret[4].(error)
+	return ret0, ret1, ret2, ret3, ret4
+}
+
+// GetObjectLockConfig indicates an expected call of GetObjectLockConfig.
+func (mr *MockClientMockRecorder) GetObjectLockConfig(ctx, bucketName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetObjectLockConfig", reflect.TypeOf((*MockClient)(nil).GetObjectLockConfig), ctx, bucketName)
+}
+
+// MakeBucket mocks base method.
+func (m *MockClient) MakeBucket(ctx context.Context, bucketName string, opts minio.MakeBucketOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MakeBucket", ctx, bucketName, opts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MakeBucket indicates an expected call of MakeBucket.
+func (mr *MockClientMockRecorder) MakeBucket(ctx, bucketName, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MakeBucket", reflect.TypeOf((*MockClient)(nil).MakeBucket), ctx, bucketName, opts)
+}
+
+// SetBucketLifecycle mocks base method.
+func (m *MockClient) SetBucketLifecycle(ctx context.Context, bucketName string, config *lifecycle.Configuration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBucketLifecycle", ctx, bucketName, config)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBucketLifecycle indicates an expected call of SetBucketLifecycle.
+func (mr *MockClientMockRecorder) SetBucketLifecycle(ctx, bucketName, config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBucketLifecycle", reflect.TypeOf((*MockClient)(nil).SetBucketLifecycle), ctx, bucketName, config)
+}
+
+// SetBucketPolicy mocks base method.
+func (m *MockClient) SetBucketPolicy(ctx context.Context, bucketName, policy string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBucketPolicy", ctx, bucketName, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBucketPolicy indicates an expected call of SetBucketPolicy.
+func (mr *MockClientMockRecorder) SetBucketPolicy(ctx, bucketName, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBucketPolicy", reflect.TypeOf((*MockClient)(nil).SetBucketPolicy), ctx, bucketName, policy)
+}
+
+// SetBucketVersioning mocks base method.
+func (m *MockClient) SetBucketVersioning(ctx context.Context, bucketName string, config minio.BucketVersioningConfiguration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBucketVersioning", ctx, bucketName, config)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBucketVersioning indicates an expected call of SetBucketVersioning.
+func (mr *MockClientMockRecorder) SetBucketVersioning(ctx, bucketName, config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBucketVersioning", reflect.TypeOf((*MockClient)(nil).SetBucketVersioning), ctx, bucketName, config)
+}
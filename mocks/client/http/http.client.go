@@ -5,6 +5,7 @@
 package mock_http
 
 import (
+	context "context"
 	http "net/http"
 	reflect "reflect"
 
@@ -34,17 +35,17 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
-// Get mocks base method.
-func (m *MockClient) Get(url string) (*http.Response, error) {
+// Head mocks base method.
+func (m *MockClient) Head(ctx context.Context, url string) (*http.Response, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", url)
+	ret := m.ctrl.Call(m, "Head", ctx, url)
 	ret0, _ := ret[0].(*http.Response)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Get indicates an expected call of Get.
-func (mr *MockClientMockRecorder) Get(url interface{}) *gomock.Call {
+// Head indicates an expected call of Head.
+func (mr *MockClientMockRecorder) Head(ctx, url interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockClient)(nil).Get), url)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Head", reflect.TypeOf((*MockClient)(nil).Head), ctx, url)
 }
@@ -5,9 +5,11 @@
 package mock_object
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	object "github.com/isd-sgcu/rpkm67-store/internal/object"
 )
 
 // MockRepository is a mock of Repository interface.
@@ -34,32 +36,61 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 }
 
 // Delete mocks base method.
-func (m *MockRepository) Delete(bucketName, objectKey string) error {
+func (m *MockRepository) Delete(ctx context.Context, bucketName, objectKey string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", bucketName, objectKey)
+	ret := m.ctrl.Call(m, "Delete", ctx, bucketName, objectKey)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockRepositoryMockRecorder) Delete(bucketName, objectKey interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Delete(ctx, bucketName, objectKey interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), bucketName, objectKey)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), ctx, bucketName, objectKey)
+}
+
+// DeleteVersion mocks base method.
+func (m *MockRepository) DeleteVersion(ctx context.Context, bucketName, objectKey, versionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVersion", ctx, bucketName, objectKey, versionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVersion indicates an expected call of DeleteVersion.
+func (mr *MockRepositoryMockRecorder) DeleteVersion(ctx, bucketName, objectKey, versionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVersion", reflect.TypeOf((*MockRepository)(nil).DeleteVersion), ctx, bucketName, objectKey, versionID)
 }
 
 // Get mocks base method.
-func (m *MockRepository) Get(bucketName, objectKey string) (string, error) {
+func (m *MockRepository) Get(ctx context.Context, bucketName, objectKey string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", bucketName, objectKey)
+	ret := m.ctrl.Call(m, "Get", ctx, bucketName, objectKey)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockRepositoryMockRecorder) Get(bucketName, objectKey interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Get(ctx, bucketName, objectKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRepository)(nil).Get), ctx, bucketName, objectKey)
+}
+
+// GetRetention mocks base method.
+func (m *MockRepository) GetRetention(ctx context.Context, bucketName, objectKey, versionID string) (*object.Retention, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRetention", ctx, bucketName, objectKey, versionID)
+	ret0, _ := ret[0].(*object.Retention)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRetention indicates an expected call of GetRetention.
+func (mr *MockRepositoryMockRecorder) GetRetention(ctx, bucketName, objectKey, versionID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRepository)(nil).Get), bucketName, objectKey)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRetention", reflect.TypeOf((*MockRepository)(nil).GetRetention), ctx, bucketName, objectKey, versionID)
 }
 
 // GetURL mocks base method.
@@ -76,18 +107,152 @@ func (mr *MockRepositoryMockRecorder) GetURL(bucketName, objectKey interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURL", reflect.TypeOf((*MockRepository)(nil).GetURL), bucketName, objectKey)
 }
 
-// Upload mocks base method.
-func (m *MockRepository) Upload(file []byte, bucketName, objectKey string) (string, string, error) {
+// GetVersion mocks base method.
+func (m *MockRepository) GetVersion(ctx context.Context, bucketName, objectKey, versionID string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Upload", file, bucketName, objectKey)
+	ret := m.ctrl.Call(m, "GetVersion", ctx, bucketName, objectKey, versionID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVersion indicates an expected call of GetVersion.
+func (mr *MockRepositoryMockRecorder) GetVersion(ctx, bucketName, objectKey, versionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersion", reflect.TypeOf((*MockRepository)(nil).GetVersion), ctx, bucketName, objectKey, versionID)
+}
+
+// List mocks base method.
+func (m *MockRepository) List(ctx context.Context, bucketName, prefix string) ([]object.StoredObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, bucketName, prefix)
+	ret0, _ := ret[0].([]object.StoredObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockRepositoryMockRecorder) List(ctx, bucketName, prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), ctx, bucketName, prefix)
+}
+
+// ListVersions mocks base method.
+func (m *MockRepository) ListVersions(ctx context.Context, bucketName, objectKey string) ([]object.ObjectVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVersions", ctx, bucketName, objectKey)
+	ret0, _ := ret[0].([]object.ObjectVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVersions indicates an expected call of ListVersions.
+func (mr *MockRepositoryMockRecorder) ListVersions(ctx, bucketName, objectKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVersions", reflect.TypeOf((*MockRepository)(nil).ListVersions), ctx, bucketName, objectKey)
+}
+
+// Purge mocks base method.
+func (m *MockRepository) Purge(ctx context.Context, bucketName, objectKey string, bypassGovernance bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, bucketName, objectKey, bypassGovernance)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockRepositoryMockRecorder) Purge(ctx, bucketName, objectKey, bypassGovernance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockRepository)(nil).Purge), ctx, bucketName, objectKey, bypassGovernance)
+}
+
+// ResolveAlias mocks base method.
+func (m *MockRepository) ResolveAlias(ctx context.Context, bucketName, alias string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveAlias", ctx, bucketName, alias)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveAlias indicates an expected call of ResolveAlias.
+func (mr *MockRepositoryMockRecorder) ResolveAlias(ctx, bucketName, alias interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveAlias", reflect.TypeOf((*MockRepository)(nil).ResolveAlias), ctx, bucketName, alias)
+}
+
+// Restore mocks base method.
+func (m *MockRepository) Restore(ctx context.Context, bucketName, objectKey string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, bucketName, objectKey)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockRepositoryMockRecorder) Restore(ctx, bucketName, objectKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockRepository)(nil).Restore), ctx, bucketName, objectKey)
+}
+
+// RestoreVersion mocks base method.
+func (m *MockRepository) RestoreVersion(ctx context.Context, bucketName, objectKey, versionID string) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreVersion", ctx, bucketName, objectKey, versionID)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(string)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
 
+// RestoreVersion indicates an expected call of RestoreVersion.
+func (mr *MockRepositoryMockRecorder) RestoreVersion(ctx, bucketName, objectKey, versionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreVersion", reflect.TypeOf((*MockRepository)(nil).RestoreVersion), ctx, bucketName, objectKey, versionID)
+}
+
+// SetAlias mocks base method.
+func (m *MockRepository) SetAlias(ctx context.Context, bucketName, alias, objectKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAlias", ctx, bucketName, alias, objectKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAlias indicates an expected call of SetAlias.
+func (mr *MockRepositoryMockRecorder) SetAlias(ctx, bucketName, alias, objectKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAlias", reflect.TypeOf((*MockRepository)(nil).SetAlias), ctx, bucketName, alias, objectKey)
+}
+
+// Trash mocks base method.
+func (m *MockRepository) Trash(ctx context.Context, bucketName, objectKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trash", ctx, bucketName, objectKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Trash indicates an expected call of Trash.
+func (mr *MockRepositoryMockRecorder) Trash(ctx, bucketName, objectKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trash", reflect.TypeOf((*MockRepository)(nil).Trash), ctx, bucketName, objectKey)
+}
+
+// Upload mocks base method.
+func (m *MockRepository) Upload(ctx context.Context, file []byte, bucketName, objectKey string, opts object.UploadOptions) (string, string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upload", ctx, file, bucketName, objectKey, opts)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(string)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
 // Upload indicates an expected call of Upload.
-func (mr *MockRepositoryMockRecorder) Upload(file, bucketName, objectKey interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Upload(ctx, file, bucketName, objectKey, opts interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upload", reflect.TypeOf((*MockRepository)(nil).Upload), file, bucketName, objectKey)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upload", reflect.TypeOf((*MockRepository)(nil).Upload), ctx, file, bucketName, objectKey, opts)
 }
@@ -10,6 +10,8 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	v1 "github.com/isd-sgcu/rpkm67-go-proto/rpkm67/store/object/v1"
+	config "github.com/isd-sgcu/rpkm67-store/config"
+	v10 "github.com/isd-sgcu/rpkm67-store/proto/rpkm67/store/admin/v1"
 )
 
 // MockService is a mock of Service interface.
@@ -65,6 +67,123 @@ func (mr *MockServiceMockRecorder) FindByKey(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByKey", reflect.TypeOf((*MockService)(nil).FindByKey), arg0, arg1)
 }
 
+// ForceDelete mocks base method.
+func (m *MockService) ForceDelete(arg0 context.Context, arg1 *v10.ForceDeleteObjectRequest) (*v10.ForceDeleteObjectResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForceDelete", arg0, arg1)
+	ret0, _ := ret[0].(*v10.ForceDeleteObjectResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ForceDelete indicates an expected call of ForceDelete.
+func (mr *MockServiceMockRecorder) ForceDelete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForceDelete", reflect.TypeOf((*MockService)(nil).ForceDelete), arg0, arg1)
+}
+
+// GetUsage mocks base method.
+func (m *MockService) GetUsage(arg0 context.Context, arg1 *v10.GetUsageRequest) (*v10.GetUsageResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsage", arg0, arg1)
+	ret0, _ := ret[0].(*v10.GetUsageResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsage indicates an expected call of GetUsage.
+func (mr *MockServiceMockRecorder) GetUsage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsage", reflect.TypeOf((*MockService)(nil).GetUsage), arg0, arg1)
+}
+
+// ListVersions mocks base method.
+func (m *MockService) ListVersions(arg0 context.Context, arg1 *v10.ListVersionsRequest) (*v10.ListVersionsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVersions", arg0, arg1)
+	ret0, _ := ret[0].(*v10.ListVersionsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVersions indicates an expected call of ListVersions.
+func (mr *MockServiceMockRecorder) ListVersions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVersions", reflect.TypeOf((*MockService)(nil).ListVersions), arg0, arg1)
+}
+
+// PurgeTrash mocks base method.
+func (m *MockService) PurgeTrash(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTrash", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeTrash indicates an expected call of PurgeTrash.
+func (mr *MockServiceMockRecorder) PurgeTrash(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTrash", reflect.TypeOf((*MockService)(nil).PurgeTrash), ctx)
+}
+
+// Reload mocks base method.
+func (m *MockService) Reload(conf *config.Store) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Reload", conf)
+}
+
+// Reload indicates an expected call of Reload.
+func (mr *MockServiceMockRecorder) Reload(conf interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reload", reflect.TypeOf((*MockService)(nil).Reload), conf)
+}
+
+// Restore mocks base method.
+func (m *MockService) Restore(arg0 context.Context, arg1 *v10.RestoreObjectRequest) (*v10.RestoreObjectResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0, arg1)
+	ret0, _ := ret[0].(*v10.RestoreObjectResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockServiceMockRecorder) Restore(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockService)(nil).Restore), arg0, arg1)
+}
+
+// RestoreVersion mocks base method.
+func (m *MockService) RestoreVersion(arg0 context.Context, arg1 *v10.RestoreVersionRequest) (*v10.RestoreVersionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreVersion", arg0, arg1)
+	ret0, _ := ret[0].(*v10.RestoreVersionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreVersion indicates an expected call of RestoreVersion.
+func (mr *MockServiceMockRecorder) RestoreVersion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreVersion", reflect.TypeOf((*MockService)(nil).RestoreVersion), arg0, arg1)
+}
+
+// SetAlias mocks base method.
+func (m *MockService) SetAlias(arg0 context.Context, arg1 *v10.SetAliasRequest) (*v10.SetAliasResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAlias", arg0, arg1)
+	ret0, _ := ret[0].(*v10.SetAliasResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetAlias indicates an expected call of SetAlias.
+func (mr *MockServiceMockRecorder) SetAlias(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAlias", reflect.TypeOf((*MockService)(nil).SetAlias), arg0, arg1)
+}
+
 // Upload mocks base method.
 func (m *MockService) Upload(arg0 context.Context, arg1 *v1.UploadObjectRequest) (*v1.UploadObjectResponse, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +199,18 @@ func (mr *MockServiceMockRecorder) Upload(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upload", reflect.TypeOf((*MockService)(nil).Upload), arg0, arg1)
 }
 
+// mustEmbedUnimplementedAdminServiceServer mocks base method.
+func (m *MockService) mustEmbedUnimplementedAdminServiceServer() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "mustEmbedUnimplementedAdminServiceServer")
+}
+
+// mustEmbedUnimplementedAdminServiceServer indicates an expected call of mustEmbedUnimplementedAdminServiceServer.
+func (mr *MockServiceMockRecorder) mustEmbedUnimplementedAdminServiceServer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "mustEmbedUnimplementedAdminServiceServer", reflect.TypeOf((*MockService)(nil).mustEmbedUnimplementedAdminServiceServer))
+}
+
 // mustEmbedUnimplementedObjectServiceServer mocks base method.
 func (m *MockService) mustEmbedUnimplementedObjectServiceServer() {
 	m.ctrl.T.Helper()